@@ -2,4 +2,4 @@ module github.com/c8ab/provenskills
 
 go 1.25.7
 
-require gopkg.in/yaml.v3 v3.0.1 // indirect
+require gopkg.in/yaml.v3 v3.0.1
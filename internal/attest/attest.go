@@ -0,0 +1,226 @@
+// Package attest creates, stores and verifies in-toto attestations about
+// skill artifacts. Each attestation is an in-toto statement signed in a
+// DSSE envelope; an artifact may carry any number of them, with arbitrary
+// predicate types.
+package attest
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/dsse"
+	"github.com/c8ab/provenskills/internal/intoto"
+	"github.com/c8ab/provenskills/internal/trust"
+)
+
+// Dir is the directory inside a stored artifact that holds its
+// attestations, one DSSE envelope per file.
+const Dir = "attestations"
+
+// Attestation is a stored attestation and the statement it carries.
+type Attestation struct {
+	Envelope  dsse.Envelope
+	Statement intoto.Statement
+	// Path is the file the attestation was loaded from.
+	Path string
+}
+
+// ValidatePredicateType checks that t is an absolute URI, as in-toto
+// requires of predicate types.
+func ValidatePredicateType(t string) error {
+	u, err := url.Parse(t)
+	if err != nil || u.Scheme == "" || (u.Host == "" && u.Opaque == "") {
+		return fmt.Errorf("predicate type %q must be an absolute URI", t)
+	}
+	return nil
+}
+
+// Sign wraps an in-toto statement in a DSSE envelope signed by key.
+func Sign(key crypto.Signer, st intoto.Statement) (dsse.Envelope, error) {
+	if err := ValidatePredicateType(st.PredicateType); err != nil {
+		return dsse.Envelope{}, err
+	}
+	payload, err := json.Marshal(st)
+	if err != nil {
+		return dsse.Envelope{}, fmt.Errorf("failed to marshal statement: %w", err)
+	}
+	return dsse.Sign(key, intoto.PayloadType, payload)
+}
+
+// Create signs a statement about subject carrying predicate, a JSON
+// object, of type predicateType.
+func Create(key crypto.Signer, subject intoto.ResourceDescriptor, predicateType string, predicate []byte) (dsse.Envelope, error) {
+	var obj map[string]interface{}
+	if err := json.Unmarshal(predicate, &obj); err != nil {
+		return dsse.Envelope{}, fmt.Errorf("predicate must be a JSON object: %w", err)
+	}
+	st := intoto.Statement{
+		Type:          intoto.StatementType,
+		Subject:       []intoto.ResourceDescriptor{subject},
+		PredicateType: predicateType,
+		Predicate:     json.RawMessage(predicate),
+	}
+	return Sign(key, st)
+}
+
+// Open decodes the in-toto statement inside an envelope.
+func Open(env dsse.Envelope) (intoto.Statement, error) {
+	if env.PayloadType != intoto.PayloadType {
+		return intoto.Statement{}, fmt.Errorf("unexpected payload type %q", env.PayloadType)
+	}
+	payload, err := env.DecodePayload()
+	if err != nil {
+		return intoto.Statement{}, err
+	}
+	return intoto.ParseStatement(payload)
+}
+
+// Parse decodes a serialized envelope and the statement inside it.
+func Parse(data []byte) (Attestation, error) {
+	env, err := dsse.Parse(data)
+	if err != nil {
+		return Attestation{}, err
+	}
+	st, err := Open(env)
+	if err != nil {
+		return Attestation{}, err
+	}
+	return Attestation{Envelope: env, Statement: st}, nil
+}
+
+// fileName returns the file name of an attestation. It is derived from the
+// predicate type and signing keys, so attesting the same predicate type
+// with the same keys again replaces the earlier attestation.
+func fileName(a Attestation) string {
+	ids := a.Envelope.KeyIDs()
+	sort.Strings(ids)
+	sum := sha256.Sum256([]byte(a.Statement.PredicateType + "\n" + strings.Join(ids, "\n")))
+	return hex.EncodeToString(sum[:8]) + ".json"
+}
+
+// Save writes an attestation envelope into the attestations directory of
+// a stored artifact and returns the file path.
+func Save(artifactDir string, env dsse.Envelope) (string, error) {
+	st, err := Open(env)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(artifactDir, Dir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create attestations directory: %w", err)
+	}
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal attestation: %w", err)
+	}
+	data = append(data, '\n')
+
+	path := filepath.Join(dir, fileName(Attestation{Envelope: env, Statement: st}))
+	tmp := fmt.Sprintf("%s.tmp.%d", path, os.Getpid())
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write attestation: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to write attestation: %w", err)
+	}
+	return path, nil
+}
+
+// Load returns every attestation stored with an artifact, ordered by
+// predicate type. An artifact without an attestations directory has none.
+func Load(artifactDir string) ([]Attestation, error) {
+	dir := filepath.Join(artifactDir, Dir)
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attestations: %w", err)
+	}
+
+	var atts []Attestation
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read attestation: %w", err)
+		}
+		a, err := Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		a.Path = path
+		atts = append(atts, a)
+	}
+	sort.SliceStable(atts, func(i, j int) bool {
+		return atts[i].Statement.PredicateType < atts[j].Statement.PredicateType
+	})
+	return atts, nil
+}
+
+// Filter returns the attestations of the given predicate type that are
+// about the artifact with digest.
+func Filter(atts []Attestation, predicateType, digest string) []Attestation {
+	var out []Attestation
+	for _, a := range atts {
+		if a.Statement.PredicateType == predicateType && a.Statement.HasSubject(digest) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// Signer is the verification outcome of one signature on an attestation.
+// Status is one of the trust.Status* values.
+type Signer struct {
+	KeyID  string
+	Status string
+	Key    *trust.Key
+	Err    error
+}
+
+// Verify checks each signature of an attestation against the trust store.
+// DSSE signatures name their key only by id, so a signature by a key that
+// is not trusted cannot be checked and is reported as untrusted.
+func Verify(env dsse.Envelope, ts *trust.Store) ([]Signer, error) {
+	signers := make([]Signer, 0, len(env.Signatures))
+	seen := map[string]bool{}
+	for _, keyID := range env.KeyIDs() {
+		if seen[keyID] {
+			continue
+		}
+		seen[keyID] = true
+
+		s := Signer{KeyID: keyID, Status: trust.StatusUntrusted}
+		k, ok, err := ts.Lookup(keyID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			pub, err := k.Public()
+			if err != nil {
+				return nil, err
+			}
+			if err := env.Verify(pub); err != nil {
+				s.Status, s.Err = trust.StatusInvalid, err
+			} else {
+				s.Status, s.Key = trust.StatusTrusted, &k
+			}
+		}
+		signers = append(signers, s)
+	}
+	return signers, nil
+}
@@ -0,0 +1,300 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/attest"
+	"github.com/c8ab/provenskills/internal/exitcode"
+	"github.com/c8ab/provenskills/internal/intoto"
+	"github.com/c8ab/provenskills/internal/signing"
+	"github.com/c8ab/provenskills/internal/trust"
+)
+
+const attestUsage = "Usage: psk attest <name>[@<version>] --predicate <file.json> --type <uri> --key <file>"
+
+const attestationsUsage = "Usage: psk attestations <name>[@<version>] [--type <uri>] [--json]"
+
+// RunAttest executes the "psk attest" command.
+func RunAttest(args []string) int {
+	var refArg, predicatePath, predicateType, keyPath string
+	var jsonOutput bool
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--predicate":
+			if i+1 < len(args) {
+				i++
+				predicatePath = args[i]
+			}
+		case "--type":
+			if i+1 < len(args) {
+				i++
+				predicateType = args[i]
+			}
+		case "--key":
+			if i+1 < len(args) {
+				i++
+				keyPath = args[i]
+			}
+		case "--json":
+			jsonOutput = true
+		default:
+			if refArg != "" || strings.HasPrefix(args[i], "-") {
+				fmt.Fprintf(os.Stderr, "error: unexpected argument %s\n\n%s\n", args[i], attestUsage)
+				return exitcode.ErrValidation
+			}
+			refArg = args[i]
+		}
+	}
+
+	switch {
+	case refArg == "":
+		fmt.Fprintf(os.Stderr, "error: skill argument is required\n\n%s\n", attestUsage)
+		return exitcode.ErrValidation
+	case predicatePath == "":
+		fmt.Fprintf(os.Stderr, "error: --predicate flag is required\n\n%s\n", attestUsage)
+		return exitcode.ErrValidation
+	case predicateType == "":
+		fmt.Fprintf(os.Stderr, "error: --type flag is required\n\n%s\n", attestUsage)
+		return exitcode.ErrValidation
+	case keyPath == "":
+		fmt.Fprintf(os.Stderr, "error: --key flag is required\n\n%s\n", attestUsage)
+		return exitcode.ErrValidation
+	}
+
+	name, version, err := parseSkillQuery(refArg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrValidation
+	}
+	if err := attest.ValidatePredicateType(predicateType); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrValidation
+	}
+
+	predicate, err := os.ReadFile(predicatePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: failed to read predicate: %v\n", err)
+		return exitcode.ErrIO
+	}
+
+	key, err := signing.LoadPrivateKey(keyPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: failed to load key %s: %v\n", keyPath, err)
+		if os.IsNotExist(err) {
+			return exitcode.ErrIO
+		}
+		return exitcode.ErrValidation
+	}
+
+	s, code := openStore()
+	if s == nil {
+		return code
+	}
+	if version, code = resolveSkillVersion(s, name, version); version == "" {
+		return code
+	}
+	unlock, code := lockArtifact(s, name, version)
+	if unlock == nil {
+		return code
+	}
+	defer unlock()
+	if !s.Exists(name, version) {
+		fmt.Fprintf(os.Stderr, "error: skill %s@%s not found in store\n", name, version)
+		return exitcode.ErrIO
+	}
+	dir := s.ArtifactPath(name, version)
+
+	digest, code := storedDigest(dir)
+	if digest == "" {
+		return code
+	}
+
+	env, err := attest.Create(key, intoto.DigestSubject(name+"@"+version, digest), predicateType, predicate)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrValidation
+	}
+	path, err := attest.Save(dir, env)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrIO
+	}
+	keyID := env.KeyIDs()[0]
+
+	if jsonOutput {
+		result := map[string]string{
+			"name":          name,
+			"version":       version,
+			"digest":        digest,
+			"predicateType": predicateType,
+			"keyId":         keyID,
+			"attestation":   path,
+		}
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Attested skill: %s@%s\n", name, version)
+		fmt.Printf("  type:        %s\n", predicateType)
+		fmt.Printf("  digest:      %s\n", digest)
+		fmt.Printf("  key id:      %s\n", keyID)
+		fmt.Printf("  attestation: %s\n", path)
+	}
+	return exitcode.Success
+}
+
+// RunAttestations executes the "psk attestations" command, which lists the
+// attestations stored with a skill and checks their signers against the
+// trust store.
+func RunAttestations(args []string) int {
+	var refArg, predicateType string
+	var jsonOutput bool
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--type":
+			if i+1 < len(args) {
+				i++
+				predicateType = args[i]
+			}
+		case "--json":
+			jsonOutput = true
+		default:
+			if refArg != "" || strings.HasPrefix(args[i], "-") {
+				fmt.Fprintf(os.Stderr, "error: unexpected argument %s\n\n%s\n", args[i], attestationsUsage)
+				return exitcode.ErrValidation
+			}
+			refArg = args[i]
+		}
+	}
+
+	if refArg == "" {
+		fmt.Fprintf(os.Stderr, "error: skill argument is required\n\n%s\n", attestationsUsage)
+		return exitcode.ErrValidation
+	}
+
+	name, version, err := parseSkillQuery(refArg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrValidation
+	}
+
+	s, code := openStore()
+	if s == nil {
+		return code
+	}
+	if version, code = resolveSkillVersion(s, name, version); version == "" {
+		return code
+	}
+	if !s.Exists(name, version) {
+		fmt.Fprintf(os.Stderr, "error: skill %s@%s not found in store\n", name, version)
+		return exitcode.ErrIO
+	}
+	dir := s.ArtifactPath(name, version)
+
+	digest, code := storedDigest(dir)
+	if digest == "" {
+		return code
+	}
+
+	entries, err := describeAttestations(dir, digest, predicateType)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrIO
+	}
+
+	if jsonOutput {
+		data, _ := json.MarshalIndent(entries, "", "  ")
+		fmt.Println(string(data))
+		return exitcode.Success
+	}
+
+	if len(entries) == 0 {
+		fmt.Printf("No attestations for %s@%s.\n", name, version)
+		return exitcode.Success
+	}
+	fmt.Printf("Attestations for %s@%s (%s)\n", name, version, digest)
+	printAttestations(entries)
+	return exitcode.Success
+}
+
+// signerEntry is a signature of an attestation as listed by
+// "psk attestations" and "psk inspect".
+type signerEntry struct {
+	KeyID    string `json:"keyId"`
+	Status   string `json:"status"`
+	Identity string `json:"identity,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// attestationEntry is an attestation as listed by "psk attestations" and
+// "psk inspect".
+type attestationEntry struct {
+	PredicateType string        `json:"predicateType"`
+	Current       bool          `json:"current"`
+	Path          string        `json:"path"`
+	Signers       []signerEntry `json:"signers"`
+}
+
+// describeAttestations loads the attestations of the stored artifact in
+// dir, whose manifest has the given digest, and checks their signers
+// against the trust store. If predicateType is not empty, other
+// attestations are left out.
+func describeAttestations(dir, digest, predicateType string) ([]attestationEntry, error) {
+	atts, err := attest.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ts := trust.New("")
+	entries := []attestationEntry{}
+	for _, a := range atts {
+		if predicateType != "" && a.Statement.PredicateType != predicateType {
+			continue
+		}
+		signers, err := attest.Verify(a.Envelope, ts)
+		if err != nil {
+			return nil, err
+		}
+		e := attestationEntry{
+			PredicateType: a.Statement.PredicateType,
+			Current:       a.Statement.HasSubject(digest),
+			Path:          a.Path,
+			Signers:       []signerEntry{},
+		}
+		for _, sg := range signers {
+			se := signerEntry{KeyID: sg.KeyID, Status: sg.Status}
+			if sg.Key != nil {
+				se.Identity = sg.Key.Identity
+			}
+			if sg.Err != nil {
+				se.Error = sg.Err.Error()
+			}
+			e.Signers = append(e.Signers, se)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// printAttestations writes one line per attestation, followed by one line
+// per signer.
+func printAttestations(entries []attestationEntry) {
+	for _, e := range entries {
+		line := "  " + e.PredicateType
+		if !e.Current {
+			line += " (stale: about a different digest)"
+		}
+		fmt.Println(line)
+		for _, sg := range e.Signers {
+			line := fmt.Sprintf("    %-10s %s", sg.Status, sg.KeyID)
+			if sg.Identity != "" {
+				line += "  " + sg.Identity
+			}
+			fmt.Println(line)
+		}
+	}
+}
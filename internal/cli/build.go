@@ -1,14 +1,24 @@
 package cli
 
 import (
+	"context"
+	"crypto"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/c8ab/provenskills/internal/attest"
+	"github.com/c8ab/provenskills/internal/diff"
 	"github.com/c8ab/provenskills/internal/exitcode"
+	"github.com/c8ab/provenskills/internal/intoto"
+	"github.com/c8ab/provenskills/internal/provenance"
+	"github.com/c8ab/provenskills/internal/sbom"
+	"github.com/c8ab/provenskills/internal/signing"
 	"github.com/c8ab/provenskills/internal/skill"
 	"github.com/c8ab/provenskills/internal/store"
 )
@@ -17,8 +27,9 @@ import (
 func RunBuild(args []string) int {
 	// Manual arg parsing to support intermixed flags and positional args.
 	// Go's flag package stops at the first non-flag argument.
-	var maintainer, path string
-	var force, jsonOutput bool
+	var maintainer, path, keyPath, builderID string
+	var force, jsonOutput, allowOther, withProvenance, withSBOM, checkVersionBump bool
+	startedOn := time.Now()
 
 	for i := 0; i < len(args); i++ {
 		switch args[i] {
@@ -31,6 +42,24 @@ func RunBuild(args []string) int {
 			force = true
 		case "--json":
 			jsonOutput = true
+		case "--allow-other-files":
+			allowOther = true
+		case "--provenance":
+			withProvenance = true
+		case "--sbom":
+			withSBOM = true
+		case "--check-bump":
+			checkVersionBump = true
+		case "--key":
+			if i+1 < len(args) {
+				i++
+				keyPath = args[i]
+			}
+		case "--builder-id":
+			if i+1 < len(args) {
+				i++
+				builderID = args[i]
+			}
 		default:
 			if path == "" && !strings.HasPrefix(args[i], "-") {
 				path = args[i]
@@ -48,6 +77,18 @@ func RunBuild(args []string) int {
 		return exitcode.ErrValidation
 	}
 
+	if withProvenance && keyPath == "" {
+		fmt.Fprintln(os.Stderr, "error: --provenance requires --key to sign the attestation")
+		return exitcode.ErrValidation
+	}
+	if withSBOM && keyPath == "" {
+		fmt.Fprintln(os.Stderr, "error: --sbom requires --key to sign the attestation")
+		return exitcode.ErrValidation
+	}
+	if builderID == "" {
+		builderID = defaultBuilderID
+	}
+
 	// Reject path traversal
 	if containsPathTraversal(path) {
 		fmt.Fprintln(os.Stderr, "error: path contains '..' segments (path traversal not allowed)")
@@ -92,11 +133,33 @@ func RunBuild(args []string) int {
 	// Normalize version
 	version := skill.NormalizeVersion(fm.Metadata.Version)
 
+	buildTime, err := buildTimestamp()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrValidation
+	}
+
+	// Load the signing key before touching the store, so a bad key does not
+	// leave an artifact without the requested attestations.
+	var key crypto.Signer
+	if withProvenance || withSBOM {
+		key, err = signing.LoadPrivateKey(keyPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: failed to load key %s: %v\n", keyPath, err)
+			if os.IsNotExist(err) {
+				return exitcode.ErrIO
+			}
+			return exitcode.ErrValidation
+		}
+	}
+
 	// Initialize store
-	s := store.New("")
+	s, code := openStore()
+	if s == nil {
+		return code
+	}
 	if err := s.Init(); err != nil {
-		fmt.Fprintf(os.Stderr, "error: failed to initialize store: %v\n", err)
-		return exitcode.ErrIO
+		return printStoreError(fmt.Errorf("failed to initialize store: %w", err))
 	}
 
 	// Check for conflict (unless --force)
@@ -105,6 +168,28 @@ func RunBuild(args []string) int {
 		return exitcode.ErrConflict
 	}
 
+	// Record every file, rejecting anything outside the skill layout
+	contents, err := store.ScanContents(path, allowOther)
+	if err != nil {
+		var lerr *store.LayoutError
+		if errors.As(err, &lerr) {
+			fmt.Fprintf(os.Stderr, "error: validation failed for %s\n\n", path)
+			for _, p := range lerr.Paths {
+				fmt.Fprintf(os.Stderr, "  - %s: outside the skill layout (SKILL.md, scripts/, references/, assets/)\n", p)
+			}
+			fmt.Fprintln(os.Stderr, "\nUse --allow-other-files to include them.")
+			return exitcode.ErrValidation
+		}
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrIO
+	}
+
+	sourceHash, err := store.HashTree(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrIO
+	}
+
 	// Build manifest
 	manifest := store.Manifest{
 		ManifestVersion: 1,
@@ -113,27 +198,99 @@ func RunBuild(args []string) int {
 		Description:     fm.Description,
 		Author:          fm.Metadata.Author,
 		Maintainer:      maintainer,
-		BuildTimestamp:  time.Now().UTC().Format(time.RFC3339),
-		Contents: store.Contents{
-			SkillFile: "SKILL.md",
-		},
+		BuildTimestamp:  buildTime.Format(time.RFC3339),
+		Contents:        contents,
+		SourceHash:      sourceHash,
+	}
+
+	// Refuse versions bumped too little for their changes since the
+	// previous stored version
+	var bump *bumpCheck
+	if checkVersionBump {
+		c, code := checkBump(s, diff.Side{Label: filepath.Clean(path), Manifest: manifest, Skill: fm, Body: skill.Body(data), Source: true})
+		if code != exitcode.Success {
+			return code
+		}
+		if !c.OK {
+			printBumpError(c)
+			return exitcode.ErrValidation
+		}
+		bump = &c
 	}
 
-	// Add to store
-	destPath, err := s.Add(fm.Name, version, path, manifest, force)
+	// Add to store, recording the digest the artifact packs to. It is
+	// reported so that independent rebuilds can be compared against
+	// published artifacts.
+	destPath, err := s.AddFunc(fm.Name, version, manifest, force, recordPacked(manifest, func(dir string) error {
+		return store.CopySkill(path, dir)
+	}))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		return exitcode.ErrIO
+		return printStoreError(err)
+	}
+	digest, code := storedDigest(destPath)
+	if digest == "" {
+		return code
+	}
+
+	// Provenance and SBOMs are written into the stored artifact like
+	// signatures, under its lock
+	if withProvenance || withSBOM {
+		unlock, code := lockArtifact(s, fm.Name, version)
+		if unlock == nil {
+			return code
+		}
+		defer unlock()
+	}
+
+	var provenancePath string
+	if withProvenance {
+		provenancePath, err = writeProvenance(key, destPath, provenance.Build{
+			Name:            fm.Name,
+			Version:         version,
+			Digest:          digest,
+			SourceDir:       path,
+			SourceHash:      sourceHash,
+			Maintainer:      maintainer,
+			AllowOtherFiles: allowOther,
+			SourceDateEpoch: os.Getenv("SOURCE_DATE_EPOCH"),
+			BuilderID:       builderID,
+			BuilderVersion:  pskVersion,
+			StartedOn:       startedOn,
+			FinishedOn:      time.Now(),
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: skill stored but provenance failed: %v\n", err)
+			return exitcode.ErrIO
+		}
+	}
+
+	var sbomPaths []string
+	if withSBOM {
+		sbomPaths, err = writeSBOMs(key, destPath, manifest, digest)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: skill stored but SBOM failed: %v\n", err)
+			return exitcode.ErrIO
+		}
 	}
 
 	// Output
 	if jsonOutput {
-		result := map[string]string{
+		result := map[string]interface{}{
 			"name":       fm.Name,
 			"version":    version,
 			"author":     fm.Metadata.Author,
 			"maintainer": maintainer,
 			"path":       destPath + "/",
+			"digest":     digest,
+		}
+		if provenancePath != "" {
+			result["provenance"] = provenancePath
+		}
+		if len(sbomPaths) > 0 {
+			result["sbom"] = sbomPaths
+		}
+		if bump != nil {
+			result["bump"] = bump
 		}
 		data, _ := json.MarshalIndent(result, "", "  ")
 		fmt.Println(string(data))
@@ -141,8 +298,82 @@ func RunBuild(args []string) int {
 		fmt.Printf("Built skill: %s@%s\n", fm.Name, version)
 		fmt.Printf("  author:     %s\n", fm.Metadata.Author)
 		fmt.Printf("  maintainer: %s\n", maintainer)
+		fmt.Printf("  contents:   %d script(s), %d reference(s), %d asset(s)", len(contents.Scripts), len(contents.References), len(contents.Assets))
+		if len(contents.Other) > 0 {
+			fmt.Printf(", %d other file(s)", len(contents.Other))
+		}
+		fmt.Println()
 		fmt.Printf("  stored:     %s/\n", destPath)
+		fmt.Printf("  digest:     %s\n", digest)
+		if provenancePath != "" {
+			fmt.Printf("  provenance: %s\n", provenancePath)
+		}
+		for _, p := range sbomPaths {
+			fmt.Printf("  sbom:       %s\n", p)
+		}
+		if bump != nil && bump.Previous != "" {
+			fmt.Printf("  bump:       %s since %s (%s required)\n", bump.Actual, bump.Previous, bump.Required)
+		}
 	}
 
 	return exitcode.Success
 }
+
+// writeProvenance signs SLSA provenance for a build with key and stores it
+// with the artifact in dir. It returns the path of the stored envelope.
+func writeProvenance(key crypto.Signer, dir string, b provenance.Build) (string, error) {
+	st, err := provenance.Generate(context.Background(), b)
+	if err != nil {
+		return "", err
+	}
+	env, err := attest.Sign(key, st)
+	if err != nil {
+		return "", err
+	}
+	return attest.Save(dir, env)
+}
+
+// writeSBOMs signs an SBOM attestation in every supported format for the
+// stored skill in dir and returns the paths of the stored envelopes.
+func writeSBOMs(key crypto.Signer, dir string, m store.Manifest, digest string) ([]string, error) {
+	inv, err := sbom.Scan(dir, m, digest)
+	if err != nil {
+		return nil, err
+	}
+	inv.ToolVersion = pskVersion
+
+	subject := intoto.DigestSubject(m.Name+"@"+m.Version, digest)
+	var paths []string
+	for _, format := range sbom.Formats {
+		doc, err := sbom.Generate(inv, format)
+		if err != nil {
+			return nil, err
+		}
+		predicateType, _ := sbom.PredicateType(format)
+		env, err := attest.Create(key, subject, predicateType, doc)
+		if err != nil {
+			return nil, err
+		}
+		path, err := attest.Save(dir, env)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// buildTimestamp returns the time to record as the build timestamp: the
+// SOURCE_DATE_EPOCH environment variable if set, otherwise the current
+// time, in UTC.
+func buildTimestamp() (time.Time, error) {
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		return time.Now().UTC().Truncate(time.Second), nil
+	}
+	secs, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil || secs < 0 {
+		return time.Time{}, fmt.Errorf("SOURCE_DATE_EPOCH must be a non-negative integer, got %q", epoch)
+	}
+	return time.Unix(secs, 0).UTC(), nil
+}
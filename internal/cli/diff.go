@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/diff"
+	"github.com/c8ab/provenskills/internal/exitcode"
+	"github.com/c8ab/provenskills/internal/skill"
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+const diffUsage = "Usage: psk diff <name>[@<version>]|<dir> <name>[@<version>]|<dir> [--json]"
+
+// RunDiff executes the "psk diff" command.
+func RunDiff(args []string) int {
+	var positional []string
+	var jsonOutput bool
+
+	for _, arg := range args {
+		switch {
+		case arg == "--json":
+			jsonOutput = true
+		case !strings.HasPrefix(arg, "-"):
+			positional = append(positional, arg)
+		default:
+			fmt.Fprintf(os.Stderr, "error: unexpected argument %s\n\n%s\n", arg, diffUsage)
+			return exitcode.ErrValidation
+		}
+	}
+
+	if len(positional) != 2 {
+		fmt.Fprintf(os.Stderr, "error: two skills to compare are required\n\n%s\n", diffUsage)
+		return exitcode.ErrValidation
+	}
+
+	var s *store.Store
+	var code int
+	sides := make([]diff.Side, 2)
+	for i, arg := range positional {
+		if isSourceDir(arg) {
+			if sides[i], code = loadSourceSide(arg); code != exitcode.Success {
+				return code
+			}
+			continue
+		}
+
+		name, version, err := parseSkillQuery(arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return exitcode.ErrValidation
+		}
+		if s == nil {
+			if s, code = openStore(); s == nil {
+				return code
+			}
+		}
+		if version, code = resolveSkillVersion(s, name, version); version == "" {
+			return code
+		}
+		if !s.Exists(name, version) {
+			fmt.Fprintf(os.Stderr, "error: skill %s@%s not found in store\n", name, version)
+			return exitcode.ErrIO
+		}
+		if sides[i], code = loadStoredSide(s.ArtifactPath(name, version), name+"@"+version); code != exitcode.Success {
+			return code
+		}
+	}
+
+	result := diff.Compare(sides[0], sides[1])
+
+	if jsonOutput {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+		return exitcode.Success
+	}
+
+	if result.Identical() {
+		fmt.Printf("No differences between %s and %s.\n", result.Old, result.New)
+		return exitcode.Success
+	}
+	fmt.Printf("--- %s\n+++ %s\n", result.Old, result.New)
+	printFieldChanges("Manifest", result.Manifest)
+	printFieldChanges("Frontmatter", result.Frontmatter)
+	if len(result.Files) > 0 {
+		fmt.Println("\nFiles:")
+		for _, f := range result.Files {
+			fmt.Printf("  %s\n", describeFileChange(f))
+		}
+	}
+	if result.Body != "" {
+		fmt.Println("\nSKILL.md:")
+		fmt.Print(result.Body)
+	}
+	return exitcode.Success
+}
+
+// isSourceDir reports whether a diff argument names a source directory
+// rather than a stored skill. A reference with a version never does; a
+// bare name does if a directory of that name exists.
+func isSourceDir(arg string) bool {
+	if strings.Contains(arg, "@") {
+		return false
+	}
+	info, err := os.Stat(arg)
+	return err == nil && info.IsDir()
+}
+
+// loadStoredSide loads the stored artifact in dir for comparison. Its
+// files and source hash are taken from the files in dir rather than from
+// manifest.json, which is not verified.
+func loadStoredSide(dir, label string) (diff.Side, int) {
+	manifest, err := store.ReadManifest(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return diff.Side{}, exitcode.ErrIO
+	}
+	side, code := readSkillMD(dir, label)
+	if code != exitcode.Success {
+		return side, code
+	}
+	if manifest.Contents, manifest.SourceHash, code = scanSkillFiles(dir); code != exitcode.Success {
+		return diff.Side{}, code
+	}
+	side.Manifest = manifest
+	return side, exitcode.Success
+}
+
+// loadSourceSide loads the skill source directory dir for comparison,
+// computing its manifest as psk build would.
+func loadSourceSide(dir string) (diff.Side, int) {
+	side, code := readSkillMD(dir, filepath.Clean(dir))
+	if code != exitcode.Success {
+		return side, code
+	}
+	contents, sourceHash, code := scanSkillFiles(dir)
+	if code != exitcode.Success {
+		return diff.Side{}, code
+	}
+	side.Source = true
+	side.Manifest = store.Manifest{
+		ManifestVersion: 1,
+		Name:            side.Skill.Name,
+		Version:         skill.NormalizeVersion(side.Skill.Metadata.Version),
+		Description:     side.Skill.Description,
+		Author:          side.Skill.Metadata.Author,
+		Contents:        contents,
+		SourceHash:      sourceHash,
+	}
+	return side, exitcode.Success
+}
+
+// scanSkillFiles records the skill files in dir and computes their source
+// hash.
+func scanSkillFiles(dir string) (store.Contents, string, int) {
+	contents, err := store.ScanContents(dir, true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return store.Contents{}, "", exitcode.ErrIO
+	}
+	sourceHash, err := store.HashTree(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return store.Contents{}, "", exitcode.ErrIO
+	}
+	return contents, sourceHash, exitcode.Success
+}
+
+// readSkillMD reads the frontmatter and body of the SKILL.md in dir.
+func readSkillMD(dir, label string) (diff.Side, int) {
+	data, err := os.ReadFile(filepath.Join(dir, "SKILL.md"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: failed to read SKILL.md of %s: %v\n", label, err)
+		return diff.Side{}, exitcode.ErrIO
+	}
+	fm, err := skill.ParseFrontmatter(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: SKILL.md of %s: %v\n", label, err)
+		return diff.Side{}, exitcode.ErrValidation
+	}
+	return diff.Side{Label: label, Skill: fm, Body: skill.Body(data)}, exitcode.Success
+}
+
+// printFieldChanges writes a section of changed fields, if any.
+func printFieldChanges(title string, changes []diff.FieldChange) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Printf("\n%s:\n", title)
+	fieldW := 0
+	for _, c := range changes {
+		if len(c.Field) > fieldW {
+			fieldW = len(c.Field)
+		}
+	}
+	for _, c := range changes {
+		fmt.Printf("  %-*s  %s -> %s\n", fieldW+1, c.Field+":", orNone(c.Old), orNone(c.New))
+	}
+}
+
+// orNone quotes s, or returns "(none)" if it is empty.
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return fmt.Sprintf("%q", s)
+}
+
+// describeFileChange formats a file change as a status letter, the path
+// and what changed.
+func describeFileChange(f diff.FileChange) string {
+	switch f.Status {
+	case diff.StatusAdded:
+		return fmt.Sprintf("A %s  %s", f.Path, f.New.Digest)
+	case diff.StatusRemoved:
+		return fmt.Sprintf("D %s  %s", f.Path, f.Old.Digest)
+	}
+	line := "M " + f.Path
+	if f.Old.Digest != f.New.Digest {
+		line += fmt.Sprintf("  %s -> %s", f.Old.Digest, f.New.Digest)
+	}
+	switch {
+	case f.New.Executable && !f.Old.Executable:
+		line += "  (now executable)"
+	case f.Old.Executable && !f.New.Executable:
+		line += "  (no longer executable)"
+	}
+	return line
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/c8ab/provenskills/internal/exitcode"
+	"github.com/c8ab/provenskills/internal/oci"
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+// recordPacked returns a store.AddFunc populate function that runs
+// populate and then records the OCI manifest the artifact packs to with
+// manifest as its config. Signatures and attestations cover that digest
+// from then on.
+func recordPacked(manifest store.Manifest, populate func(dir string) error) func(dir string) error {
+	return func(dir string) error {
+		if err := populate(dir); err != nil {
+			return err
+		}
+		a, err := oci.PackManifest(dir, manifest)
+		if err != nil {
+			return err
+		}
+		return oci.RecordManifest(dir, a)
+	}
+}
+
+// storedDigest returns the digest of the stored artifact in dir. On
+// failure it reports the error and returns "" and the exit code.
+func storedDigest(dir string) (string, int) {
+	digest, err := oci.StoredDigest(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return "", exitcode.ErrIO
+	}
+	return digest, exitcode.Success
+}
+
+// packStored packs the stored artifact in dir for publishing. It fails
+// with ErrIntegrity if the artifact no longer packs to its stored digest,
+// since its signatures and attestations would not apply to what is
+// published.
+func packStored(dir, label string) (*oci.Artifact, int) {
+	artifact, err := oci.Pack(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return nil, exitcode.ErrIO
+	}
+	digest, code := storedDigest(dir)
+	if digest == "" {
+		return nil, code
+	}
+	if artifact.Digest() != digest {
+		fmt.Fprintf(os.Stderr, "error: %s now packs to %s, but it was stored as %s, which its signatures and attestations cover\n", label, artifact.Digest(), digest)
+		return nil, exitcode.ErrIntegrity
+	}
+	return artifact, exitcode.Success
+}
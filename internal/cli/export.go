@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/exitcode"
+	"github.com/c8ab/provenskills/internal/oci"
+)
+
+const exportUsage = "Usage: psk export <name>[@<version>] [--format oci-layout] <dir|file.tar>"
+
+// RunExport executes the "psk export" command.
+func RunExport(args []string) int {
+	var positional []string
+	format := "oci-layout"
+	var jsonOutput bool
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--format":
+			if i+1 < len(args) {
+				i++
+				format = args[i]
+			}
+		case "--json":
+			jsonOutput = true
+		default:
+			if strings.HasPrefix(args[i], "-") {
+				fmt.Fprintf(os.Stderr, "error: unknown flag %s\n\n%s\n", args[i], exportUsage)
+				return exitcode.ErrValidation
+			}
+			positional = append(positional, args[i])
+		}
+	}
+
+	if len(positional) != 2 {
+		fmt.Fprintf(os.Stderr, "error: skill and destination arguments are required\n\n%s\n", exportUsage)
+		return exitcode.ErrValidation
+	}
+	if format != "oci-layout" {
+		fmt.Fprintf(os.Stderr, "error: unsupported format %q (supported: oci-layout)\n", format)
+		return exitcode.ErrValidation
+	}
+
+	name, version, err := parseSkillQuery(positional[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrValidation
+	}
+	dest := positional[1]
+
+	s, code := openStore()
+	if s == nil {
+		return code
+	}
+	if version, code = resolveSkillVersion(s, name, version); version == "" {
+		return code
+	}
+	if !s.Exists(name, version) {
+		fmt.Fprintf(os.Stderr, "error: skill %s@%s not found in store\n", name, version)
+		return exitcode.ErrIO
+	}
+
+	dir := s.ArtifactPath(name, version)
+	artifact, code := packStored(dir, name+"@"+version)
+	if artifact == nil {
+		return code
+	}
+	referrers, err := storedReferrers(dir, artifact)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrIO
+	}
+	var counts referrerCounts
+	for _, r := range referrers {
+		counts.add(r.Manifest.ArtifactType)
+	}
+
+	if strings.HasSuffix(dest, ".tar") {
+		err = exportTar(dest, artifact, version, referrers)
+	} else {
+		err = oci.WriteLayout(dest, artifact, version, referrers)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: export failed: %v\n", err)
+		return exitcode.ErrIO
+	}
+
+	digest := artifact.Digest()
+	if jsonOutput {
+		result := map[string]interface{}{
+			"name":    name,
+			"version": version,
+			"format":  format,
+			"digest":  digest,
+			"path":    dest,
+		}
+		counts.addJSON(result)
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Exported skill: %s@%s\n", name, version)
+		fmt.Printf("  format:       %s\n", format)
+		fmt.Printf("  digest:       %s\n", digest)
+		fmt.Printf("  path:         %s\n", dest)
+		counts.print()
+	}
+
+	return exitcode.Success
+}
+
+// exportTar writes an image layout tarball to path atomically.
+func exportTar(path string, artifact *oci.Artifact, refName string, referrers []*oci.Artifact) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp.*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := oci.WriteLayoutTar(tmp, artifact, refName, referrers); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/exitcode"
+	"github.com/c8ab/provenskills/internal/oci"
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+const importUsage = "Usage: psk import <oci-layout-dir|file.tar> [--force]"
+
+// RunImport executes the "psk import" command.
+func RunImport(args []string) int {
+	var path string
+	var force, jsonOutput bool
+
+	for _, arg := range args {
+		switch {
+		case arg == "--force":
+			force = true
+		case arg == "--json":
+			jsonOutput = true
+		case !strings.HasPrefix(arg, "-") && path == "":
+			path = arg
+		default:
+			fmt.Fprintf(os.Stderr, "error: unexpected argument %s\n\n%s\n", arg, importUsage)
+			return exitcode.ErrValidation
+		}
+	}
+
+	if path == "" {
+		fmt.Fprintf(os.Stderr, "error: path argument is required\n\n%s\n", importUsage)
+		return exitcode.ErrValidation
+	}
+
+	layout, err := oci.OpenLayout(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "error: path not found: %s\n", path)
+			return exitcode.ErrIO
+		}
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		if errors.Is(err, oci.ErrTooLarge) {
+			return exitcode.ErrValidation
+		}
+		return exitcode.ErrIO
+	}
+
+	artifacts, err := layout.Skills()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrValidation
+	}
+
+	pol, err := loadPolicy("")
+	if err != nil {
+		return printPolicyLoadError(err)
+	}
+
+	s, code := openStore()
+	if s == nil {
+		return code
+	}
+	if err := s.Init(); err != nil {
+		return printStoreError(fmt.Errorf("failed to initialize store: %w", err))
+	}
+
+	type importEntry struct {
+		Name         string `json:"name"`
+		Version      string `json:"version"`
+		Digest       string `json:"digest"`
+		Path         string `json:"path"`
+		Signatures   int    `json:"signatures,omitempty"`
+		Attestations int    `json:"attestations,omitempty"`
+	}
+	var imported []importEntry
+
+	// Check every config and conflict before writing anything, so that a
+	// bad or existing skill does not leave the layout half imported
+	manifests := make([]store.Manifest, len(artifacts))
+	for i, artifact := range artifacts {
+		manifest, err := skillConfig(artifact)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return exitcode.ErrValidation
+		}
+		if !force && s.Exists(manifest.Name, manifest.Version) {
+			fmt.Fprintf(os.Stderr, "error: skill %s@%s already exists in store\n\nUse --force to overwrite.\n", manifest.Name, manifest.Version)
+			return exitcode.ErrConflict
+		}
+		manifests[i] = manifest
+	}
+
+	// Skills imported before a later one fails stay in the store
+	fail := func(code int) int {
+		if len(imported) > 0 {
+			fmt.Fprintf(os.Stderr, "\nImported before the failure:\n")
+			for _, e := range imported {
+				fmt.Fprintf(os.Stderr, "  %s@%s\n", e.Name, e.Version)
+			}
+		}
+		return code
+	}
+
+	for i, artifact := range artifacts {
+		manifest := manifests[i]
+		name, version := manifest.Name, manifest.Version
+
+		// Signatures and attestations are optional, so failures are
+		// reported as warnings. They are stored before the policy is
+		// evaluated so that it can take them into account.
+		var counts referrerCounts
+		destPath, err := s.AddFunc(name, version, manifest, force, func(dir string) error {
+			if err := unpackSkill(artifact, manifest)(dir); err != nil {
+				return err
+			}
+			if err := verifyUnpacked(artifact, manifest, dir); err != nil {
+				return err
+			}
+			if err := oci.RecordManifest(dir, artifact); err != nil {
+				return err
+			}
+			referrers, err := layout.Referrers(artifact.Digest())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: failed to read referrers of %s@%s: %v\n", name, version, err)
+			}
+			for _, r := range referrers {
+				if err := saveReferrer(dir, artifact, r); err != nil {
+					fmt.Fprintf(os.Stderr, "warning: skipping referrer %s: %v\n", r.Digest(), err)
+					continue
+				}
+				counts.add(r.Manifest.ArtifactType)
+			}
+			return admitSkill(pol, dir, manifest, artifact.Digest())
+		})
+		if err != nil {
+			var verr *validationError
+			if errors.As(err, &verr) {
+				printValidationError(name+"@"+version, verr)
+				return fail(exitcode.ErrValidation)
+			}
+			var perr *policyError
+			if errors.As(err, &perr) {
+				printPolicyError(name+"@"+version, perr)
+				return fail(exitcode.ErrPolicyDenied)
+			}
+			return fail(printStoreError(err))
+		}
+
+		imported = append(imported, importEntry{
+			Name:         name,
+			Version:      version,
+			Digest:       artifact.Digest(),
+			Path:         destPath + "/",
+			Signatures:   counts.signatures,
+			Attestations: counts.attestations,
+		})
+	}
+
+	if jsonOutput {
+		data, _ := json.MarshalIndent(imported, "", "  ")
+		fmt.Println(string(data))
+		return exitcode.Success
+	}
+
+	for _, e := range imported {
+		fmt.Printf("Imported skill: %s@%s\n", e.Name, e.Version)
+		fmt.Printf("  digest:       %s\n", e.Digest)
+		fmt.Printf("  stored:       %s\n", e.Path)
+		referrerCounts{e.Signatures, e.Attestations}.print()
+	}
+	return exitcode.Success
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/oci"
+	"github.com/c8ab/provenskills/internal/skill"
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+// validationError reports SKILL.md problems found in an ingested artifact.
+type validationError struct {
+	errs []string
+}
+
+func (e *validationError) Error() string {
+	return "validation failed: " + strings.Join(e.errs, "; ")
+}
+
+// skillConfig validates an artifact and its config blob, and checks that
+// the skill name and version are safe to use as store paths.
+func skillConfig(a *oci.Artifact) (store.Manifest, error) {
+	m, err := oci.ValidateArtifact(a)
+	if err != nil {
+		return store.Manifest{}, err
+	}
+	_, version, err := parseSkillRef(m.Name + "@" + m.Version)
+	if err != nil || version != m.Version {
+		return store.Manifest{}, fmt.Errorf("skill config has invalid name or version %q@%q", m.Name, m.Version)
+	}
+	return m, nil
+}
+
+// unpackSkill returns a store.AddFunc populate function that extracts the
+// artifact's layer and re-validates the unpacked SKILL.md against m.
+func unpackSkill(a *oci.Artifact, m store.Manifest) func(dir string) error {
+	return func(dir string) error {
+		if err := oci.UnpackLayer(a.Layer, dir); err != nil {
+			if errors.Is(err, oci.ErrTooLarge) {
+				return &validationError{[]string{err.Error()}}
+			}
+			return err
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, "SKILL.md"))
+		if err != nil {
+			return &validationError{[]string{"SKILL.md: missing from artifact"}}
+		}
+		fm, err := skill.ParseFrontmatter(data)
+		if err != nil {
+			return &validationError{[]string{err.Error()}}
+		}
+
+		errs := skill.Validate(fm, m.Name)
+		if len(errs) == 0 && skill.NormalizeVersion(fm.Metadata.Version) != m.Version {
+			errs = append(errs, fmt.Sprintf("metadata.version: %q does not match artifact version %q", fm.Metadata.Version, m.Version))
+		}
+		if len(errs) > 0 {
+			return &validationError{errs}
+		}
+		return nil
+	}
+}
+
+// verifyUnpacked checks the files of artifact a, unpacked into dir,
+// against its config m: they must be the files and source hash that m
+// records, and they must pack back to a's digest, so that signatures,
+// attestations and policy decisions made for that digest hold for the
+// stored skill.
+func verifyUnpacked(a *oci.Artifact, m store.Manifest, dir string) error {
+	problems, err := store.CheckContents(dir, m.Contents)
+	if err != nil {
+		return err
+	}
+	var errs []string
+	for _, p := range problems {
+		errs = append(errs, "contents: "+p)
+	}
+	sourceHash, err := store.HashTree(dir)
+	if err != nil {
+		return err
+	}
+	if sourceHash != m.SourceHash {
+		errs = append(errs, fmt.Sprintf("sourceHash: skill config records %q, but the layer hashes to %q", m.SourceHash, sourceHash))
+	}
+	if len(errs) > 0 {
+		return &validationError{errs}
+	}
+
+	repacked, err := oci.PackManifest(dir, m)
+	if err != nil {
+		return err
+	}
+	if repacked.Digest() != a.Digest() {
+		return &validationError{[]string{fmt.Sprintf("digest: artifact %s would be stored as %s, so its signatures and attestations would not apply", a.Digest(), repacked.Digest())}}
+	}
+	return nil
+}
+
+// printValidationError writes a validation failure for source in the same
+// format as "psk validate".
+func printValidationError(source string, verr *validationError) {
+	fmt.Fprintf(os.Stderr, "error: validation failed for %s\n\n", source)
+	for _, e := range verr.errs {
+		fmt.Fprintf(os.Stderr, "  - %s\n", e)
+	}
+}
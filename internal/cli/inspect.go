@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/exitcode"
+	"github.com/c8ab/provenskills/internal/signing"
+	"github.com/c8ab/provenskills/internal/skill"
+	"github.com/c8ab/provenskills/internal/store"
+	"github.com/c8ab/provenskills/internal/trust"
+)
+
+const inspectUsage = "Usage: psk inspect <name>[@<version>] [--json]"
+
+// RunInspect executes the "psk inspect" command.
+func RunInspect(args []string) int {
+	var refArg string
+	var jsonOutput bool
+
+	for _, arg := range args {
+		switch {
+		case arg == "--json":
+			jsonOutput = true
+		case !strings.HasPrefix(arg, "-") && refArg == "":
+			refArg = arg
+		default:
+			fmt.Fprintf(os.Stderr, "error: unexpected argument %s\n\n%s\n", arg, inspectUsage)
+			return exitcode.ErrValidation
+		}
+	}
+
+	if refArg == "" {
+		fmt.Fprintf(os.Stderr, "error: skill argument is required\n\n%s\n", inspectUsage)
+		return exitcode.ErrValidation
+	}
+
+	name, version, err := parseSkillQuery(refArg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrValidation
+	}
+
+	s, code := openStore()
+	if s == nil {
+		return code
+	}
+	if version, code = resolveSkillVersion(s, name, version); version == "" {
+		return code
+	}
+	if !s.Exists(name, version) {
+		fmt.Fprintf(os.Stderr, "error: skill %s@%s not found in store\n", name, version)
+		return exitcode.ErrIO
+	}
+	dir := s.ArtifactPath(name, version)
+
+	manifest, err := store.ReadManifest(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrIO
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "SKILL.md"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: failed to read SKILL.md: %v\n", err)
+		return exitcode.ErrIO
+	}
+	fm, err := skill.ParseFrontmatter(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: SKILL.md: %v\n", err)
+		return exitcode.ErrValidation
+	}
+
+	digest, code := storedDigest(dir)
+	if digest == "" {
+		return code
+	}
+
+	sigs, err := signing.Load(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrBadSignature
+	}
+	results, err := trust.New("").Verify(digest, sigs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrIO
+	}
+	attestations, err := describeAttestations(dir, digest, "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrIO
+	}
+
+	if jsonOutput {
+		type signatureEntry struct {
+			KeyID     string `json:"keyId"`
+			Algorithm string `json:"algorithm"`
+			Status    string `json:"status"`
+			Identity  string `json:"identity,omitempty"`
+			Error     string `json:"error,omitempty"`
+		}
+		signatures := []signatureEntry{}
+		for _, r := range results {
+			e := signatureEntry{KeyID: r.Signature.KeyID, Algorithm: r.Signature.Algorithm, Status: r.Status}
+			if r.Key != nil {
+				e.Identity = r.Key.Identity
+			}
+			if r.Err != nil {
+				e.Error = r.Err.Error()
+			}
+			signatures = append(signatures, e)
+		}
+		result := map[string]interface{}{
+			"name":         name,
+			"version":      version,
+			"path":         dir + "/",
+			"digest":       digest,
+			"manifest":     manifest,
+			"frontmatter":  fm,
+			"signatures":   signatures,
+			"attestations": attestations,
+		}
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+		return exitcode.Success
+	}
+
+	fmt.Printf("Skill: %s@%s\n", name, version)
+	printFields([][2]string{
+		{"path", dir + "/"},
+		{"digest", digest},
+		{"description", manifest.Description},
+		{"author", manifest.Author},
+		{"maintainer", manifest.Maintainer},
+		{"built", manifest.BuildTimestamp},
+		{"sourceHash", manifest.SourceHash},
+		{"license", fm.License},
+		{"compatibility", fm.Compatibility},
+		{"allowed-tools", fm.AllowedTools},
+	})
+
+	fmt.Println("\nContents:")
+	if manifest.Contents.SkillFile != "" {
+		fmt.Printf("  %s\n", manifest.Contents.SkillFile)
+	}
+	files := manifest.Contents.Files()
+	pathW := 0
+	for _, f := range files {
+		if len(f.Path) > pathW {
+			pathW = len(f.Path)
+		}
+	}
+	for _, f := range files {
+		line := fmt.Sprintf("  %-*s  %10s  %s", pathW, f.Path, formatBytes(f.Size), f.Digest)
+		if f.Executable {
+			line += "  (executable)"
+		}
+		fmt.Println(line)
+	}
+
+	fmt.Println("\nSignatures:")
+	if len(results) == 0 {
+		fmt.Println("  none")
+	}
+	for _, r := range results {
+		line := fmt.Sprintf("  %-10s %s", r.Status, r.Signature.KeyID)
+		switch {
+		case r.Key != nil:
+			line += "  " + r.Key.Identity
+		case r.Err != nil:
+			line += "  " + r.Err.Error()
+		}
+		fmt.Println(line)
+	}
+
+	fmt.Println("\nAttestations:")
+	if len(attestations) == 0 {
+		fmt.Println("  none")
+	}
+	printAttestations(attestations)
+	return exitcode.Success
+}
+
+// printFields writes label/value pairs with aligned values, leaving out
+// empty values.
+func printFields(fields [][2]string) {
+	labelW := 0
+	for _, f := range fields {
+		if f[1] != "" && len(f[0]) > labelW {
+			labelW = len(f[0])
+		}
+	}
+	for _, f := range fields {
+		if f[1] != "" {
+			fmt.Printf("  %-*s  %s\n", labelW+1, f[0]+":", f[1])
+		}
+	}
+}
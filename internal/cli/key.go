@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/c8ab/provenskills/internal/exitcode"
+	"github.com/c8ab/provenskills/internal/signing"
+)
+
+const keyUsage = "Usage: psk key generate [--algorithm ecdsa-p256|ed25519] [--output <prefix>] [--force]"
+
+// RunKey executes the "psk key" command group.
+func RunKey(args []string) int {
+	if len(args) < 1 {
+		fmt.Fprintf(os.Stderr, "error: subcommand is required\n\n%s\n", keyUsage)
+		return exitcode.ErrValidation
+	}
+	switch args[0] {
+	case "generate":
+		return runKeyGenerate(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "error: unknown key subcommand %q\n\n%s\n", args[0], keyUsage)
+		return exitcode.ErrValidation
+	}
+}
+
+// runKeyGenerate executes "psk key generate".
+func runKeyGenerate(args []string) int {
+	algorithm := signing.AlgorithmECDSAP256
+	output := "psk"
+	var force, jsonOutput bool
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--algorithm", "--output":
+			if i+1 >= len(args) {
+				fmt.Fprintf(os.Stderr, "error: %s requires a value\n\n%s\n", args[i], keyUsage)
+				return exitcode.ErrValidation
+			}
+			if args[i] == "--algorithm" {
+				algorithm = args[i+1]
+			} else {
+				output = args[i+1]
+			}
+			i++
+		case "--force":
+			force = true
+		case "--json":
+			jsonOutput = true
+		default:
+			fmt.Fprintf(os.Stderr, "error: unexpected argument %s\n\n%s\n", args[i], keyUsage)
+			return exitcode.ErrValidation
+		}
+	}
+
+	privPath, pubPath := output+".key", output+".pub"
+	if !force {
+		for _, p := range []string{privPath, pubPath} {
+			if _, err := os.Stat(p); err == nil {
+				fmt.Fprintf(os.Stderr, "error: %s already exists\n\nUse --force to overwrite.\n", p)
+				return exitcode.ErrConflict
+			}
+		}
+	}
+
+	key, err := signing.GenerateKey(algorithm)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrValidation
+	}
+	privPEM, err := signing.MarshalPrivateKey(key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrGeneral
+	}
+	pubPEM, err := signing.MarshalPublicKey(key.Public())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrGeneral
+	}
+	keyID, err := signing.KeyID(key.Public())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrGeneral
+	}
+
+	if err := writeKeyFile(privPath, privPEM, 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "error: failed to write private key: %v\n", err)
+		return exitcode.ErrIO
+	}
+	if err := writeKeyFile(pubPath, pubPEM, 0o644); err != nil {
+		// Do not leave a private key without its public half
+		os.Remove(privPath)
+		fmt.Fprintf(os.Stderr, "error: failed to write public key: %v\n", err)
+		return exitcode.ErrIO
+	}
+
+	if jsonOutput {
+		result := map[string]string{
+			"algorithm":  algorithm,
+			"privateKey": privPath,
+			"publicKey":  pubPath,
+			"keyId":      keyID,
+		}
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Generated %s key pair\n", algorithm)
+		fmt.Printf("  private key: %s\n", privPath)
+		fmt.Printf("  public key:  %s\n", pubPath)
+		fmt.Printf("  key id:      %s\n", keyID)
+	}
+	return exitcode.Success
+}
+
+// writeKeyFile writes data to path with exactly the given permissions.
+func writeKeyFile(path string, data []byte, perm os.FileMode) error {
+	os.Remove(path)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		return err
+	}
+	return f.Close()
+}
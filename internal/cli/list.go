@@ -7,7 +7,6 @@ import (
 	"os"
 
 	"github.com/c8ab/provenskills/internal/exitcode"
-	"github.com/c8ab/provenskills/internal/store"
 )
 
 // RunList executes the "psk list" command.
@@ -20,7 +19,10 @@ func RunList(args []string) int {
 		return exitcode.ErrValidation
 	}
 
-	s := store.New("")
+	s, code := openStore()
+	if s == nil {
+		return code
+	}
 	manifests, err := s.List()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/exitcode"
+	"github.com/c8ab/provenskills/internal/policy"
+	"github.com/c8ab/provenskills/internal/store"
+	"github.com/c8ab/provenskills/internal/trust"
+)
+
+const policyUsage = `Usage:
+  psk policy check <name>[@<version>] [--policy <file>] [--json]`
+
+// policyError reports that a skill was denied by the admission policy.
+type policyError struct {
+	path       string
+	violations []policy.Violation
+}
+
+func (e *policyError) Error() string {
+	return fmt.Sprintf("denied by policy %s: %d violation(s)", e.path, len(e.violations))
+}
+
+// loadPolicy loads the policy at path, or the policy that applies in the
+// current directory if path is empty. It returns nil when no policy
+// applies.
+func loadPolicy(path string) (*policy.Policy, error) {
+	if path == "" {
+		path = policy.Find(".")
+	}
+	if path == "" {
+		return nil, nil
+	}
+	p, err := policy.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// printPolicyLoadError reports a policy that could not be loaded and
+// returns the matching exit code.
+func printPolicyLoadError(err error) int {
+	var perr *os.PathError
+	if errors.As(err, &perr) && errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "error: policy file not found: %s\n", perr.Path)
+		return exitcode.ErrIO
+	}
+	fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	return exitcode.ErrValidation
+}
+
+// admitSkill evaluates p against the skill in dir described by m, whose
+// artifact has the given digest. It returns a *policyError listing the
+// violations if the skill is denied. A nil policy admits everything.
+func admitSkill(p *policy.Policy, dir string, m store.Manifest, digest string) error {
+	if p == nil {
+		return nil
+	}
+	in, err := policy.Gather(dir, m, digest, trust.New(""))
+	if err != nil {
+		return err
+	}
+	if vs := policy.Evaluate(*p, in); len(vs) > 0 {
+		return &policyError{path: p.Path, violations: vs}
+	}
+	return nil
+}
+
+// printPolicyError writes a policy denial for source, one violated rule
+// per line.
+func printPolicyError(source string, perr *policyError) {
+	fmt.Fprintf(os.Stderr, "error: policy denied %s (%s)\n\n", source, perr.path)
+	for _, v := range perr.violations {
+		fmt.Fprintf(os.Stderr, "  - %s\n", v)
+	}
+}
+
+// RunPolicy executes the "psk policy" command group.
+func RunPolicy(args []string) int {
+	if len(args) < 1 {
+		fmt.Fprintf(os.Stderr, "error: subcommand is required\n\n%s\n", policyUsage)
+		return exitcode.ErrValidation
+	}
+	switch args[0] {
+	case "check":
+		return runPolicyCheck(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "error: unknown policy subcommand %q\n\n%s\n", args[0], policyUsage)
+		return exitcode.ErrValidation
+	}
+}
+
+// runPolicyCheck executes "psk policy check".
+func runPolicyCheck(args []string) int {
+	var refArg, policyPath string
+	var jsonOutput bool
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--policy":
+			if i+1 < len(args) {
+				i++
+				policyPath = args[i]
+			}
+		case "--json":
+			jsonOutput = true
+		default:
+			if refArg != "" || strings.HasPrefix(args[i], "-") {
+				fmt.Fprintf(os.Stderr, "error: unexpected argument %s\n\n%s\n", args[i], policyUsage)
+				return exitcode.ErrValidation
+			}
+			refArg = args[i]
+		}
+	}
+
+	if refArg == "" {
+		fmt.Fprintf(os.Stderr, "error: skill argument is required\n\n%s\n", policyUsage)
+		return exitcode.ErrValidation
+	}
+	name, version, err := parseSkillQuery(refArg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrValidation
+	}
+
+	p, err := loadPolicy(policyPath)
+	if err != nil {
+		return printPolicyLoadError(err)
+	}
+	if p == nil {
+		fmt.Fprintf(os.Stderr, "error: no policy found\n\nCreate %s or ~/%s, set PSK_POLICY, or use --policy <file>.\n", policy.ProjectFile, policy.ProjectFile)
+		return exitcode.ErrIO
+	}
+
+	s, code := openStore()
+	if s == nil {
+		return code
+	}
+	if version, code = resolveSkillVersion(s, name, version); version == "" {
+		return code
+	}
+	if !s.Exists(name, version) {
+		fmt.Fprintf(os.Stderr, "error: skill %s@%s not found in store\n", name, version)
+		return exitcode.ErrIO
+	}
+	dir := s.ArtifactPath(name, version)
+
+	manifest, err := store.ReadManifest(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrIO
+	}
+	digest, code := storedDigest(dir)
+	if digest == "" {
+		return code
+	}
+
+	violations := []policy.Violation{}
+	err = admitSkill(p, dir, manifest, digest)
+	var perr *policyError
+	switch {
+	case errors.As(err, &perr):
+		violations = perr.violations
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrIO
+	}
+
+	code = exitcode.Success
+	if len(violations) > 0 {
+		code = exitcode.ErrPolicyDenied
+	}
+
+	if jsonOutput {
+		result := map[string]interface{}{
+			"name":       name,
+			"version":    version,
+			"digest":     digest,
+			"policy":     p.Path,
+			"allowed":    code == exitcode.Success,
+			"violations": violations,
+		}
+		data, _ := json.MarshalIndent(result, "", "  ")
+		if code == exitcode.Success {
+			fmt.Println(string(data))
+		} else {
+			fmt.Fprintln(os.Stderr, string(data))
+		}
+		return code
+	}
+
+	if perr != nil {
+		printPolicyError(name+"@"+version, perr)
+		return code
+	}
+	fmt.Printf("Policy allows skill: %s@%s\n", name, version)
+	fmt.Printf("  policy: %s\n", p.Path)
+	fmt.Printf("  digest: %s\n", digest)
+	return code
+}
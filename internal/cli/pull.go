@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/exitcode"
+	"github.com/c8ab/provenskills/internal/oci"
+)
+
+const pullUsage = "Usage: psk pull <registry>/<repository>[:<tag>|@<digest>] [--force]"
+
+// RunPull executes the "psk pull" command.
+func RunPull(args []string) int {
+	var refArg string
+	var force, jsonOutput bool
+
+	for _, arg := range args {
+		switch {
+		case arg == "--force":
+			force = true
+		case arg == "--json":
+			jsonOutput = true
+		case !strings.HasPrefix(arg, "-") && refArg == "":
+			refArg = arg
+		default:
+			fmt.Fprintf(os.Stderr, "error: unexpected argument %s\n\n%s\n", arg, pullUsage)
+			return exitcode.ErrValidation
+		}
+	}
+
+	if refArg == "" {
+		fmt.Fprintf(os.Stderr, "error: registry reference argument is required\n\n%s\n", pullUsage)
+		return exitcode.ErrValidation
+	}
+
+	ref, err := oci.ParseReference(refArg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrValidation
+	}
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+
+	pol, err := loadPolicy("")
+	if err != nil {
+		return printPolicyLoadError(err)
+	}
+
+	ctx := context.Background()
+	client := oci.NewClient()
+	artifact, err := client.Pull(ctx, ref)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: pull failed: %v\n", err)
+		if errors.Is(err, oci.ErrNotFound) {
+			return exitcode.ErrNotFound
+		}
+		if errors.Is(err, oci.ErrTooLarge) {
+			return exitcode.ErrValidation
+		}
+		return exitcode.ErrIO
+	}
+
+	manifest, err := skillConfig(artifact)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrValidation
+	}
+	name, version := manifest.Name, manifest.Version
+
+	s, code := openStore()
+	if s == nil {
+		return code
+	}
+	if err := s.Init(); err != nil {
+		return printStoreError(fmt.Errorf("failed to initialize store: %w", err))
+	}
+
+	if !force && s.Exists(name, version) {
+		fmt.Fprintf(os.Stderr, "error: skill %s@%s already exists in store\n\nUse --force to overwrite.\n", name, version)
+		return exitcode.ErrConflict
+	}
+
+	// Referrers are fetched before the skill lands in the store so that
+	// the policy can take its signatures and provenance into account.
+	var referrers referrerCounts
+	destPath, err := s.AddFunc(name, version, manifest, force, func(dir string) error {
+		if err := unpackSkill(artifact, manifest)(dir); err != nil {
+			return err
+		}
+		if err := verifyUnpacked(artifact, manifest, dir); err != nil {
+			return err
+		}
+		if err := oci.RecordManifest(dir, artifact); err != nil {
+			return err
+		}
+		referrers = pullReferrers(ctx, client, ref, artifact, dir)
+		return admitSkill(pol, dir, manifest, artifact.Digest())
+	})
+	if err != nil {
+		var verr *validationError
+		if errors.As(err, &verr) {
+			printValidationError(ref.String(), verr)
+			return exitcode.ErrValidation
+		}
+		var perr *policyError
+		if errors.As(err, &perr) {
+			printPolicyError(ref.String(), perr)
+			return exitcode.ErrPolicyDenied
+		}
+		return printStoreError(err)
+	}
+
+	digest := artifact.Digest()
+	if jsonOutput {
+		result := map[string]interface{}{
+			"name":      name,
+			"version":   version,
+			"reference": ref.String(),
+			"digest":    digest,
+			"path":      destPath + "/",
+		}
+		referrers.addJSON(result)
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Pulled skill: %s@%s\n", name, version)
+		fmt.Printf("  reference:    %s\n", ref)
+		fmt.Printf("  digest:       %s\n", digest)
+		fmt.Printf("  stored:       %s/\n", destPath)
+		referrers.print()
+	}
+
+	return exitcode.Success
+}
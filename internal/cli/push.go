@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/cosign"
+	"github.com/c8ab/provenskills/internal/exitcode"
+	"github.com/c8ab/provenskills/internal/oci"
+	"github.com/c8ab/provenskills/internal/signing"
+)
+
+const pushUsage = "Usage: psk push <name>[@<version>] <registry>/<repository>[:<tag>] [--cosign-key <file>]"
+
+// RunPush executes the "psk push" command.
+func RunPush(args []string) int {
+	var positional []string
+	var cosignKeyPath string
+	var jsonOutput bool
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--json":
+			jsonOutput = true
+		case arg == "--cosign-key":
+			if i+1 < len(args) {
+				i++
+				cosignKeyPath = args[i]
+			}
+		case !strings.HasPrefix(arg, "-"):
+			positional = append(positional, arg)
+		default:
+			fmt.Fprintf(os.Stderr, "error: unknown flag %s\n\n%s\n", arg, pushUsage)
+			return exitcode.ErrValidation
+		}
+	}
+
+	if len(positional) != 2 {
+		fmt.Fprintf(os.Stderr, "error: skill and registry reference arguments are required\n\n%s\n", pushUsage)
+		return exitcode.ErrValidation
+	}
+
+	name, version, err := parseSkillQuery(positional[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrValidation
+	}
+
+	ref, err := oci.ParseReference(positional[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrValidation
+	}
+	if ref.Digest != "" {
+		fmt.Fprintln(os.Stderr, "error: cannot push to a digest reference; use a tag")
+		return exitcode.ErrValidation
+	}
+
+	var cosignKey crypto.Signer
+	if cosignKeyPath != "" {
+		cosignKey, err = signing.LoadPrivateKey(cosignKeyPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: failed to load key %s: %v\n", cosignKeyPath, err)
+			if os.IsNotExist(err) {
+				return exitcode.ErrIO
+			}
+			return exitcode.ErrValidation
+		}
+	}
+
+	s, code := openStore()
+	if s == nil {
+		return code
+	}
+	if version, code = resolveSkillVersion(s, name, version); version == "" {
+		return code
+	}
+	if ref.Tag == "" {
+		// Tags cannot contain "+", so build metadata is joined with "_"
+		ref.Tag = strings.ReplaceAll(version, "+", "_")
+	}
+	if !s.Exists(name, version) {
+		fmt.Fprintf(os.Stderr, "error: skill %s@%s not found in store\n", name, version)
+		return exitcode.ErrIO
+	}
+
+	artifact, code := packStored(s.ArtifactPath(name, version), name+"@"+version)
+	if artifact == nil {
+		return code
+	}
+
+	ctx := context.Background()
+	client := oci.NewClient()
+	if err := client.Push(ctx, ref, artifact); err != nil {
+		fmt.Fprintf(os.Stderr, "error: push failed: %v\n", err)
+		return exitcode.ErrIO
+	}
+
+	referrers, err := pushReferrers(ctx, client, ref, artifact, s.ArtifactPath(name, version))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: failed to push signatures and attestations: %v\n", err)
+		return exitcode.ErrIO
+	}
+
+	digest := artifact.Digest()
+	if cosignKey != nil {
+		sig, err := cosign.Sign(cosignKey, ref, digest)
+		if err == nil {
+			err = cosign.Push(ctx, client, ref, digest, sig)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: failed to push cosign signature: %v\n", err)
+			return exitcode.ErrIO
+		}
+	}
+
+	if jsonOutput {
+		result := map[string]interface{}{
+			"name":      name,
+			"version":   version,
+			"reference": ref.String(),
+			"digest":    digest,
+		}
+		referrers.addJSON(result)
+		if cosignKey != nil {
+			result["cosignSignature"] = cosign.SignatureTag(digest)
+		}
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Pushed skill: %s@%s\n", name, version)
+		fmt.Printf("  reference:    %s\n", ref)
+		fmt.Printf("  digest:       %s\n", digest)
+		referrers.print()
+		if cosignKey != nil {
+			fmt.Printf("  cosign:       %s\n", cosign.SignatureTag(digest))
+		}
+	}
+
+	return exitcode.Success
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/exitcode"
+	"github.com/c8ab/provenskills/internal/skill"
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+// parseSkillRef splits a "<name>@<version>" argument. The version is
+// normalized so "1.0" and "1.0.0" address the same artifact.
+func parseSkillRef(arg string) (name, version string, err error) {
+	name, version, ok := strings.Cut(arg, "@")
+	if !ok || name == "" || version == "" {
+		return "", "", fmt.Errorf("invalid skill reference %q: expected <name>@<version>", arg)
+	}
+	if containsPathTraversal(name) || containsPathTraversal(version) ||
+		strings.ContainsAny(name+version, `/\`) {
+		return "", "", fmt.Errorf("invalid skill reference %q", arg)
+	}
+	return name, skill.NormalizeVersion(version), nil
+}
+
+// parseSkillQuery splits a "<name>@<version>", "<name>@<range>" or bare
+// "<name>" argument. An exact version is normalized like parseSkillRef; a
+// range is returned as written and a bare name with an empty query, for
+// resolveSkillVersion to pick a stored version.
+func parseSkillQuery(arg string) (name, query string, err error) {
+	name, query, ok := strings.Cut(arg, "@")
+	if name == "" || (ok && strings.TrimSpace(query) == "") {
+		return "", "", fmt.Errorf("invalid skill reference %q: expected <name>[@<version or range>]", arg)
+	}
+	if containsPathTraversal(name) || strings.ContainsAny(name, `/\`) {
+		return "", "", fmt.Errorf("invalid skill reference %q", arg)
+	}
+	if !ok {
+		return name, "", nil
+	}
+	if version := skill.NormalizeVersion(query); isExactVersion(version) {
+		return name, version, nil
+	}
+	if _, err := skill.ParseConstraint(query); err != nil {
+		return "", "", err
+	}
+	return name, query, nil
+}
+
+// isExactVersion reports whether a query names a single version.
+func isExactVersion(query string) bool {
+	_, err := skill.ParseVersion(query)
+	return err == nil
+}
+
+// resolveSkillVersion returns the version a query of parseSkillQuery
+// addresses: an exact version as is, otherwise the highest stored version
+// of name that satisfies the range, or the highest release for an empty
+// query. Prereleases are only used when named, so a name with nothing but
+// prereleases in the store fails with a hint to specify one. On failure it
+// reports the error and returns "" and the exit code.
+func resolveSkillVersion(s *store.Store, name, query string) (string, int) {
+	if isExactVersion(query) {
+		return query, exitcode.Success
+	}
+	rng := query
+	if rng == "" {
+		rng = "*"
+	}
+	c, err := skill.ParseConstraint(rng)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return "", exitcode.ErrValidation
+	}
+	version, err := s.Resolve(name, c)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return "", exitcode.ErrIO
+	}
+	if version == "" {
+		if query == "" {
+			versions, err := s.Versions(name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				return "", exitcode.ErrIO
+			}
+			if len(versions) > 0 {
+				fmt.Fprintf(os.Stderr, "error: only prerelease versions of %s are in store; specify one, such as %s@%s\n", name, name, versions[len(versions)-1])
+			} else {
+				fmt.Fprintf(os.Stderr, "error: skill %s not found in store\n", name)
+			}
+		} else {
+			fmt.Fprintf(os.Stderr, "error: no version of %s in store matches %q\n", name, query)
+		}
+		return "", exitcode.ErrIO
+	}
+	return version, exitcode.Success
+}
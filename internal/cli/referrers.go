@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/c8ab/provenskills/internal/attest"
+	"github.com/c8ab/provenskills/internal/oci"
+	"github.com/c8ab/provenskills/internal/signing"
+)
+
+// referrerCounts tallies the signatures and attestations transferred with
+// a skill.
+type referrerCounts struct {
+	signatures   int
+	attestations int
+}
+
+// add counts a referrer of the given artifact type.
+func (c *referrerCounts) add(artifactType string) {
+	switch artifactType {
+	case oci.ArtifactTypeSignature:
+		c.signatures++
+	case oci.ArtifactTypeAttestation:
+		c.attestations++
+	}
+}
+
+// addJSON sets the non-zero counts on a JSON result.
+func (c referrerCounts) addJSON(result map[string]interface{}) {
+	if c.signatures > 0 {
+		result["signatures"] = c.signatures
+	}
+	if c.attestations > 0 {
+		result["attestations"] = c.attestations
+	}
+}
+
+// print writes the non-zero counts as indented human output lines.
+func (c referrerCounts) print() {
+	if c.signatures > 0 {
+		fmt.Printf("  signatures:   %d\n", c.signatures)
+	}
+	if c.attestations > 0 {
+		fmt.Printf("  attestations: %d\n", c.attestations)
+	}
+}
+
+// storedReferrers returns the referrer artifacts that accompany the stored
+// skill in dir: one per signature and one per attestation. Signatures and
+// attestations that are not about artifact are skipped with a warning.
+func storedReferrers(dir string, artifact *oci.Artifact) ([]*oci.Artifact, error) {
+	digest := artifact.Digest()
+	var referrers []*oci.Artifact
+
+	sigs, err := signing.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, sig := range sigs {
+		if err := sig.Verify(digest); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: skipping signature by %s: %v\n", sig.KeyID, err)
+			continue
+		}
+		data, err := json.Marshal(sig)
+		if err != nil {
+			return nil, err
+		}
+		r, err := oci.NewReferrer(artifact.Descriptor(), oci.ArtifactTypeSignature, oci.MediaTypeSignature, data, nil)
+		if err != nil {
+			return nil, err
+		}
+		referrers = append(referrers, r)
+	}
+
+	atts, err := attest.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range atts {
+		if !a.Statement.HasSubject(digest) {
+			fmt.Fprintf(os.Stderr, "warning: skipping %s attestation: not about artifact %s\n", a.Statement.PredicateType, digest)
+			continue
+		}
+		data, err := json.Marshal(a.Envelope)
+		if err != nil {
+			return nil, err
+		}
+		annotations := map[string]string{oci.AnnotationPredicateType: a.Statement.PredicateType}
+		r, err := oci.NewReferrer(artifact.Descriptor(), oci.ArtifactTypeAttestation, oci.MediaTypeDSSEEnvelope, data, annotations)
+		if err != nil {
+			return nil, err
+		}
+		referrers = append(referrers, r)
+	}
+	return referrers, nil
+}
+
+// saveReferrer stores a signature or attestation referrer of artifact in
+// the artifact directory dir after checking that it is about artifact.
+func saveReferrer(dir string, artifact, referrer *oci.Artifact) error {
+	digest := artifact.Digest()
+	switch referrer.Manifest.ArtifactType {
+	case oci.ArtifactTypeSignature:
+		sig, err := signing.Parse(referrer.Layer)
+		if err != nil {
+			return err
+		}
+		if err := sig.Verify(digest); err != nil {
+			return err
+		}
+		_, err = signing.Save(dir, sig)
+		return err
+	case oci.ArtifactTypeAttestation:
+		a, err := attest.Parse(referrer.Layer)
+		if err != nil {
+			return err
+		}
+		if !a.Statement.HasSubject(digest) {
+			return fmt.Errorf("attestation is not about artifact %s", digest)
+		}
+		_, err = attest.Save(dir, a.Envelope)
+		return err
+	default:
+		return fmt.Errorf("unsupported artifact type %q", referrer.Manifest.ArtifactType)
+	}
+}
+
+// pushReferrers attaches the stored signatures and attestations of the
+// skill in dir to its pushed manifest.
+func pushReferrers(ctx context.Context, client *oci.Client, ref oci.Reference, artifact *oci.Artifact, dir string) (referrerCounts, error) {
+	var counts referrerCounts
+	referrers, err := storedReferrers(dir, artifact)
+	if err != nil {
+		return counts, err
+	}
+	for _, r := range referrers {
+		if err := client.PushReferrer(ctx, ref, r); err != nil {
+			return counts, err
+		}
+		counts.add(r.Manifest.ArtifactType)
+	}
+	return counts, nil
+}
+
+// pullReferrers fetches the signature and attestation referrers of a
+// pulled artifact and stores those that are about it. They are optional,
+// so failures are reported as warnings.
+func pullReferrers(ctx context.Context, client *oci.Client, ref oci.Reference, artifact *oci.Artifact, dir string) referrerCounts {
+	var counts referrerCounts
+	descs, err := client.Referrers(ctx, ref, artifact.Digest(), "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to list referrers: %v\n", err)
+		return counts
+	}
+
+	for _, desc := range descs {
+		if desc.ArtifactType != oci.ArtifactTypeSignature && desc.ArtifactType != oci.ArtifactTypeAttestation {
+			continue
+		}
+		referrer, err := client.PullReferrer(ctx, ref, desc)
+		if err == nil {
+			err = saveReferrer(dir, artifact, referrer)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: skipping referrer %s: %v\n", desc.Digest, err)
+			continue
+		}
+		counts.add(referrer.Manifest.ArtifactType)
+	}
+	return counts
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/exitcode"
+)
+
+const rmUsage = `Usage:
+  psk rm <name>@<version> [--json]
+  psk rm <name> --all-versions [--json]`
+
+// RunRm executes the "psk rm" command.
+func RunRm(args []string) int {
+	var refArg string
+	var allVersions, jsonOutput bool
+
+	for _, arg := range args {
+		switch {
+		case arg == "--all-versions":
+			allVersions = true
+		case arg == "--json":
+			jsonOutput = true
+		case !strings.HasPrefix(arg, "-") && refArg == "":
+			refArg = arg
+		default:
+			fmt.Fprintf(os.Stderr, "error: unexpected argument %s\n\n%s\n", arg, rmUsage)
+			return exitcode.ErrValidation
+		}
+	}
+
+	if refArg == "" {
+		fmt.Fprintf(os.Stderr, "error: skill argument is required\n\n%s\n", rmUsage)
+		return exitcode.ErrValidation
+	}
+
+	s, code := openStore()
+	if s == nil {
+		return code
+	}
+	var name string
+	var versions []string
+	if allVersions {
+		name = refArg
+		if strings.Contains(name, "@") {
+			fmt.Fprintf(os.Stderr, "error: --all-versions takes a skill name without a version\n\n%s\n", rmUsage)
+			return exitcode.ErrValidation
+		}
+		if containsPathTraversal(name) || strings.ContainsAny(name, `/\`) {
+			fmt.Fprintf(os.Stderr, "error: invalid skill name %q\n", name)
+			return exitcode.ErrValidation
+		}
+		var err error
+		if versions, err = s.Versions(name); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return exitcode.ErrIO
+		}
+		if len(versions) == 0 {
+			fmt.Fprintf(os.Stderr, "error: skill %s not found in store\n", name)
+			return exitcode.ErrIO
+		}
+	} else {
+		if !strings.Contains(refArg, "@") {
+			fmt.Fprintf(os.Stderr, "error: version is required\n\nUse %s@<version>, or --all-versions to remove every version.\n", refArg)
+			return exitcode.ErrValidation
+		}
+		n, version, err := parseSkillRef(refArg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return exitcode.ErrValidation
+		}
+		name, versions = n, []string{version}
+	}
+
+	type rmEntry struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Path    string `json:"path"`
+	}
+	removed := []rmEntry{}
+	for _, version := range versions {
+		path := s.ArtifactPath(name, version)
+		if err := s.Remove(name, version); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				fmt.Fprintf(os.Stderr, "error: skill %s@%s not found in store\n", name, version)
+				return exitcode.ErrIO
+			}
+			return printStoreError(err)
+		}
+		removed = append(removed, rmEntry{Name: name, Version: version, Path: path + "/"})
+	}
+
+	if jsonOutput {
+		data, _ := json.MarshalIndent(removed, "", "  ")
+		fmt.Println(string(data))
+		return exitcode.Success
+	}
+	for _, e := range removed {
+		fmt.Printf("Removed skill: %s@%s\n", e.Name, e.Version)
+	}
+	return exitcode.Success
+}
@@ -7,7 +7,11 @@ import (
 	"github.com/c8ab/provenskills/internal/exitcode"
 )
 
-const version = "0.1.0"
+const pskVersion = "0.1.0"
+
+// defaultBuilderID identifies psk itself as the builder in provenance when
+// no --builder-id is given.
+const defaultBuilderID = "https://github.com/c8ab/provenskills/cmd/psk@v" + pskVersion
 
 const helpText = `psk - Package and manage Proven Skill Artifacts
 
@@ -15,16 +19,41 @@ Usage:
   psk <command> [flags]
 
 Commands:
-  build     Package a skill directory into an artifact
-  list      List all skills in the local store
-  validate  Validate a skill directory
+  attest            Attach a signed in-toto attestation to a stored skill
+  attestations      List a stored skill's attestations and their signers
+  build             Package a skill directory into an artifact
+  diff              Compare two versions of a skill, or one and a source directory
+  export            Export a stored skill as an OCI image layout
+  import            Import skills from an OCI image layout or tarball
+  inspect           Show the manifest, frontmatter and signatures of a stored skill
+  key               Generate signing keys
+  list              List all skills in the local store
+  policy            Check stored skills against the admission policy
+  pull              Pull a skill from an OCI registry into the local store
+  push              Push a stored skill to an OCI registry
+  rm                Remove skill versions from the local store
+  sbom              Print the SBOM of a stored skill (SPDX or CycloneDX)
+  semver-check      Check that a skill's version bump matches its changes
+  sign              Sign a stored skill with a local key
+  store             Check, clean up and migrate the local store
+  trust             Manage trusted signing keys
+  validate          Validate a skill directory
+  verify            Check a stored skill against its recorded source hash
+  verify-cosign     Check cosign signatures of a skill in an OCI registry
+  verify-signature  Check a stored skill's signatures against trusted keys
 
 Flags:
   --help      Show this help message
   --version   Show psk version
 
 Environment:
-  PSK_STORE   Override default store location (~/.psk/store/)`
+  PSK_STORE              Override default store location (~/.psk/store/)
+  PSK_TRUST              Override default trust store location (~/.psk/trust/)
+  PSK_POLICY             Admission policy file (default: nearest .psk/policy.yaml)
+  PSK_LOCK_TIMEOUT       How long to wait for a store lock (default: 30s)
+  PSK_REGISTRY_USERNAME  Registry username
+  PSK_REGISTRY_PASSWORD  Registry password or token
+  SOURCE_DATE_EPOCH      Build timestamp for reproducible builds (Unix seconds)`
 
 // Run is the main entry point for the CLI. It parses the subcommand
 // from args and dispatches to the appropriate handler.
@@ -38,17 +67,55 @@ func Run(args []string) int {
 	subcmd := args[1]
 
 	switch subcmd {
+	case "attest":
+		return RunAttest(args[2:])
+	case "attestations":
+		return RunAttestations(args[2:])
 	case "build":
 		return RunBuild(args[2:])
+	case "diff":
+		return RunDiff(args[2:])
+	case "export":
+		return RunExport(args[2:])
+	case "import":
+		return RunImport(args[2:])
+	case "inspect":
+		return RunInspect(args[2:])
+	case "key":
+		return RunKey(args[2:])
 	case "list":
 		return RunList(args[2:])
+	case "policy":
+		return RunPolicy(args[2:])
+	case "pull":
+		return RunPull(args[2:])
+	case "push":
+		return RunPush(args[2:])
+	case "rm":
+		return RunRm(args[2:])
+	case "sbom":
+		return RunSBOM(args[2:])
+	case "semver-check":
+		return RunSemverCheck(args[2:])
+	case "sign":
+		return RunSign(args[2:])
+	case "store":
+		return RunStore(args[2:])
+	case "trust":
+		return RunTrust(args[2:])
 	case "validate":
 		return RunValidate(args[2:])
+	case "verify":
+		return RunVerify(args[2:])
+	case "verify-cosign":
+		return RunVerifyCosign(args[2:])
+	case "verify-signature":
+		return RunVerifySignature(args[2:])
 	case "--help", "-h", "help":
 		fmt.Println(helpText)
 		return exitcode.Success
 	case "--version", "-v":
-		fmt.Printf("psk version %s\n", version)
+		fmt.Printf("psk version %s\n", pskVersion)
 		return exitcode.Success
 	default:
 		fmt.Fprintf(os.Stderr, "error: unknown command %q\n\n%s\n", subcmd, helpText)
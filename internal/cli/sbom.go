@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/attest"
+	"github.com/c8ab/provenskills/internal/exitcode"
+	"github.com/c8ab/provenskills/internal/sbom"
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+const sbomUsage = "Usage: psk sbom <name>[@<version>] [--format spdx|cyclonedx]"
+
+// RunSBOM executes the "psk sbom" command. It prints the SBOM attested for
+// a stored skill, or generates one from the stored files if the skill was
+// built without --sbom.
+func RunSBOM(args []string) int {
+	var refArg string
+	format := sbom.FormatSPDX
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--format":
+			if i+1 < len(args) {
+				i++
+				format = args[i]
+			}
+		default:
+			if refArg != "" || strings.HasPrefix(args[i], "-") {
+				fmt.Fprintf(os.Stderr, "error: unexpected argument %s\n\n%s\n", args[i], sbomUsage)
+				return exitcode.ErrValidation
+			}
+			refArg = args[i]
+		}
+	}
+
+	if refArg == "" {
+		fmt.Fprintf(os.Stderr, "error: skill argument is required\n\n%s\n", sbomUsage)
+		return exitcode.ErrValidation
+	}
+	predicateType, err := sbom.PredicateType(format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrValidation
+	}
+
+	name, version, err := parseSkillQuery(refArg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrValidation
+	}
+
+	s, code := openStore()
+	if s == nil {
+		return code
+	}
+	if version, code = resolveSkillVersion(s, name, version); version == "" {
+		return code
+	}
+	if !s.Exists(name, version) {
+		fmt.Fprintf(os.Stderr, "error: skill %s@%s not found in store\n", name, version)
+		return exitcode.ErrIO
+	}
+	dir := s.ArtifactPath(name, version)
+
+	digest, code := storedDigest(dir)
+	if digest == "" {
+		return code
+	}
+
+	atts, err := attest.Load(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrIO
+	}
+	if found := attest.Filter(atts, predicateType, digest); len(found) > 0 {
+		var out bytes.Buffer
+		if err := json.Indent(&out, found[0].Statement.Predicate, "", "  "); err != nil {
+			fmt.Fprintf(os.Stderr, "error: invalid SBOM attestation: %v\n", err)
+			return exitcode.ErrIntegrity
+		}
+		fmt.Println(out.String())
+		return exitcode.Success
+	}
+
+	manifest, err := store.ReadManifest(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrIO
+	}
+	inv, err := sbom.Scan(dir, manifest, digest)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrIO
+	}
+	inv.ToolVersion = pskVersion
+	doc, err := sbom.Generate(inv, format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrGeneral
+	}
+	fmt.Fprintf(os.Stderr, "warning: %s@%s has no %s SBOM attestation; generated from the stored files\n", name, version, format)
+	fmt.Println(string(doc))
+	return exitcode.Success
+}
@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/diff"
+	"github.com/c8ab/provenskills/internal/exitcode"
+	"github.com/c8ab/provenskills/internal/skill"
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+const semverCheckUsage = "Usage: psk semver-check <name>[@<version>]|<dir> [--json]"
+
+// RunSemverCheck executes the "psk semver-check" command.
+func RunSemverCheck(args []string) int {
+	var refArg string
+	var jsonOutput bool
+
+	for _, arg := range args {
+		switch {
+		case arg == "--json":
+			jsonOutput = true
+		case !strings.HasPrefix(arg, "-") && refArg == "":
+			refArg = arg
+		default:
+			fmt.Fprintf(os.Stderr, "error: unexpected argument %s\n\n%s\n", arg, semverCheckUsage)
+			return exitcode.ErrValidation
+		}
+	}
+
+	if refArg == "" {
+		fmt.Fprintf(os.Stderr, "error: skill argument is required\n\n%s\n", semverCheckUsage)
+		return exitcode.ErrValidation
+	}
+
+	var side diff.Side
+	var s *store.Store
+	var code int
+	if isSourceDir(refArg) {
+		if side, code = loadSourceSide(refArg); code != exitcode.Success {
+			return code
+		}
+		if s, code = openStore(); s == nil {
+			return code
+		}
+	} else {
+		name, version, err := parseSkillQuery(refArg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return exitcode.ErrValidation
+		}
+		if s, code = openStore(); s == nil {
+			return code
+		}
+		if version, code = resolveSkillVersion(s, name, version); version == "" {
+			return code
+		}
+		if !s.Exists(name, version) {
+			fmt.Fprintf(os.Stderr, "error: skill %s@%s not found in store\n", name, version)
+			return exitcode.ErrIO
+		}
+		if side, code = loadStoredSide(s.ArtifactPath(name, version), name+"@"+version); code != exitcode.Success {
+			return code
+		}
+	}
+
+	c, code := checkBump(s, side)
+	if code != exitcode.Success {
+		return code
+	}
+
+	if jsonOutput {
+		data, _ := json.MarshalIndent(c, "", "  ")
+		if c.OK {
+			fmt.Println(string(data))
+		} else {
+			fmt.Fprintln(os.Stderr, string(data))
+		}
+	} else if c.OK {
+		if c.Previous == "" {
+			fmt.Printf("No earlier version of %s in store: nothing to check.\n", c.Name)
+			return exitcode.Success
+		}
+		fmt.Printf("Version bump OK: %s %s -> %s\n", c.Name, c.Previous, c.Version)
+		fmt.Printf("  required: %s\n", c.Required)
+		fmt.Printf("  actual:   %s\n", c.Actual)
+		if len(c.Reasons) > 0 {
+			fmt.Println()
+			printBumpReasons(os.Stdout, c.Reasons)
+		}
+	} else {
+		printBumpError(c)
+	}
+
+	if !c.OK {
+		return exitcode.ErrValidation
+	}
+	return exitcode.Success
+}
+
+// bumpCheck is the result of checking the version bump of a skill against
+// the previous version of it in the store.
+type bumpCheck struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Previous is the version compared against, or "" if there is none.
+	Previous string        `json:"previous"`
+	Required diff.Bump     `json:"required"`
+	Actual   diff.Bump     `json:"actual"`
+	OK       bool          `json:"ok"`
+	Reasons  []diff.Reason `json:"reasons"`
+}
+
+// checkBump checks that the version of side is bumped enough for its
+// changes since the previous version of the skill in s, as found in the
+// stored files of that version rather than its manifest. A skill with no
+// previous version always passes.
+func checkBump(s *store.Store, side diff.Side) (bumpCheck, int) {
+	name, version := side.Manifest.Name, side.Manifest.Version
+	c := bumpCheck{Name: name, Version: version, OK: true, Reasons: []diff.Reason{}}
+
+	v, err := skill.ParseVersion(version)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: cannot check the version bump of %s: %v\n", name, err)
+		return c, exitcode.ErrValidation
+	}
+	manifests, err := s.List()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return c, exitcode.ErrIO
+	}
+	c.Previous = previousVersion(manifests, name, v)
+	if c.Previous == "" {
+		return c, exitcode.Success
+	}
+
+	prev, code := loadStoredSide(s.ArtifactPath(name, c.Previous), name+"@"+c.Previous)
+	if code != exitcode.Success {
+		return c, code
+	}
+	pv, _ := skill.ParseVersion(c.Previous)
+	c.Required, c.Reasons = diff.RequiredBump(diff.Compare(prev, side))
+	c.Actual = diff.VersionBump(pv, v)
+	c.OK = c.Actual >= c.Required
+	return c, exitcode.Success
+}
+
+// previousVersion returns the highest version of name in manifests below
+// v, or "" if there is none. A release is only compared with releases, so
+// that changes made over its prereleases still count.
+func previousVersion(manifests []store.Manifest, name string, v skill.Version) string {
+	var prev string
+	var pv skill.Version
+	for _, m := range manifests {
+		if m.Name != name {
+			continue
+		}
+		mv, err := skill.ParseVersion(m.Version)
+		if err != nil || mv.Compare(v) >= 0 || (len(mv.Prerelease) > 0 && len(v.Prerelease) == 0) {
+			continue
+		}
+		if prev == "" || mv.Compare(pv) > 0 {
+			prev, pv = m.Version, mv
+		}
+	}
+	return prev
+}
+
+// printBumpError reports an under-bumped version and the changes that
+// require a larger bump.
+func printBumpError(c bumpCheck) {
+	fmt.Fprintf(os.Stderr, "error: %s %s -> %s is a %s bump, but the changes require a %s bump\n",
+		c.Name, c.Previous, c.Version, c.Actual, c.Required)
+	if len(c.Reasons) > 0 {
+		fmt.Fprintln(os.Stderr)
+		printBumpReasons(os.Stderr, c.Reasons)
+	}
+}
+
+// printBumpReasons lists changes with the bump each requires.
+func printBumpReasons(w io.Writer, reasons []diff.Reason) {
+	for _, r := range reasons {
+		fmt.Fprintf(w, "  - %-5s  %s\n", r.Bump, r.Change)
+	}
+}
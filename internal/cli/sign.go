@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/exitcode"
+	"github.com/c8ab/provenskills/internal/signing"
+)
+
+const signUsage = "Usage: psk sign <name>[@<version>] --key <file>"
+
+// RunSign executes the "psk sign" command.
+func RunSign(args []string) int {
+	var refArg, keyPath string
+	var jsonOutput bool
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--key":
+			if i+1 < len(args) {
+				i++
+				keyPath = args[i]
+			}
+		case "--json":
+			jsonOutput = true
+		default:
+			if refArg != "" || strings.HasPrefix(args[i], "-") {
+				fmt.Fprintf(os.Stderr, "error: unexpected argument %s\n\n%s\n", args[i], signUsage)
+				return exitcode.ErrValidation
+			}
+			refArg = args[i]
+		}
+	}
+
+	if refArg == "" {
+		fmt.Fprintf(os.Stderr, "error: skill argument is required\n\n%s\n", signUsage)
+		return exitcode.ErrValidation
+	}
+	if keyPath == "" {
+		fmt.Fprintf(os.Stderr, "error: --key flag is required\n\n%s\n", signUsage)
+		return exitcode.ErrValidation
+	}
+
+	name, version, err := parseSkillQuery(refArg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrValidation
+	}
+
+	key, err := signing.LoadPrivateKey(keyPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: failed to load key %s: %v\n", keyPath, err)
+		if os.IsNotExist(err) {
+			return exitcode.ErrIO
+		}
+		return exitcode.ErrValidation
+	}
+
+	s, code := openStore()
+	if s == nil {
+		return code
+	}
+	if version, code = resolveSkillVersion(s, name, version); version == "" {
+		return code
+	}
+	unlock, code := lockArtifact(s, name, version)
+	if unlock == nil {
+		return code
+	}
+	defer unlock()
+	if !s.Exists(name, version) {
+		fmt.Fprintf(os.Stderr, "error: skill %s@%s not found in store\n", name, version)
+		return exitcode.ErrIO
+	}
+	dir := s.ArtifactPath(name, version)
+
+	digest, code := storedDigest(dir)
+	if digest == "" {
+		return code
+	}
+
+	sig, err := signing.Sign(key, digest)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrGeneral
+	}
+	sigPath, err := signing.Save(dir, sig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrIO
+	}
+
+	if jsonOutput {
+		result := map[string]string{
+			"name":      name,
+			"version":   version,
+			"digest":    digest,
+			"keyId":     sig.KeyID,
+			"algorithm": sig.Algorithm,
+			"signature": sigPath,
+		}
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Signed skill: %s@%s\n", name, version)
+		fmt.Printf("  digest:    %s\n", digest)
+		fmt.Printf("  key id:    %s\n", sig.KeyID)
+		fmt.Printf("  signature: %s\n", sigPath)
+	}
+	return exitcode.Success
+}
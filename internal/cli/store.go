@@ -0,0 +1,321 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/c8ab/provenskills/internal/exitcode"
+	"github.com/c8ab/provenskills/internal/fsck"
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+const storeUsage = `Usage:
+  psk store gc [--dry-run] [--json]
+  psk store fsck [--json]
+  psk store migrate [--dry-run] [--json]`
+
+// openStore opens the local store, migrating it to the current layout if
+// needed. On failure it reports the error and returns a nil store and the
+// exit code.
+func openStore() (*store.Store, int) {
+	s := store.New("")
+	backup, err := s.Open()
+	if err != nil {
+		if errors.Is(err, store.ErrNewerVersion) {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return nil, exitcode.ErrValidation
+		}
+		return nil, printStoreError(err)
+	}
+	if backup != "" {
+		fmt.Fprintf(os.Stderr, "Migrated store to version %d (backup of the previous store: %s)\n", store.Version, backup)
+	}
+	return s, exitcode.Success
+}
+
+// printStoreError reports a failed store operation and returns the
+// matching exit code: ErrLocked if another process held the store lock for
+// too long, ErrIO otherwise.
+func printStoreError(err error) int {
+	fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	var lerr *store.LockedError
+	if errors.As(err, &lerr) {
+		fmt.Fprintf(os.Stderr, "\nRetry once it has finished, or set PSK_LOCK_TIMEOUT to wait longer (default %s).\n", store.DefaultLockTimeout)
+		return exitcode.ErrLocked
+	}
+	return exitcode.ErrIO
+}
+
+// lockArtifact takes the lock of a stored artifact, as store writes do, for
+// adding signatures or attestations to it. On failure it reports the error
+// and returns nil and the exit code.
+func lockArtifact(s *store.Store, name, version string) (func(), int) {
+	unlock, err := s.LockArtifact(name, version)
+	if err != nil {
+		return nil, printStoreError(err)
+	}
+	return unlock, exitcode.Success
+}
+
+// RunStore executes the "psk store" command group.
+func RunStore(args []string) int {
+	if len(args) < 1 {
+		fmt.Fprintf(os.Stderr, "error: subcommand is required\n\n%s\n", storeUsage)
+		return exitcode.ErrValidation
+	}
+	switch args[0] {
+	case "fsck":
+		return runStoreFsck(args[1:])
+	case "gc":
+		return runStoreGC(args[1:])
+	case "migrate":
+		return runStoreMigrate(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "error: unknown store subcommand %q\n\n%s\n", args[0], storeUsage)
+		return exitcode.ErrValidation
+	}
+}
+
+// runStoreGC executes "psk store gc".
+func runStoreGC(args []string) int {
+	var dryRun, jsonOutput bool
+
+	for _, arg := range args {
+		switch arg {
+		case "--dry-run":
+			dryRun = true
+		case "--json":
+			jsonOutput = true
+		default:
+			fmt.Fprintf(os.Stderr, "error: unexpected argument %s\n\n%s\n", arg, storeUsage)
+			return exitcode.ErrValidation
+		}
+	}
+
+	s, code := openStore()
+	if s == nil {
+		return code
+	}
+	if !dryRun {
+		// Hold off writers between finding garbage and deleting it
+		unlock, err := s.Lock()
+		if err != nil {
+			return printStoreError(err)
+		}
+		defer unlock()
+	}
+	garbage, err := s.FindGarbage()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrIO
+	}
+	if !dryRun {
+		if err := s.GC(garbage); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return exitcode.ErrIO
+		}
+	}
+
+	var reclaimed int64
+	for _, g := range garbage {
+		reclaimed += g.Size
+	}
+
+	if jsonOutput {
+		if garbage == nil {
+			garbage = []store.Garbage{}
+		}
+		result := map[string]interface{}{
+			"dryRun":    dryRun,
+			"removed":   garbage,
+			"reclaimed": reclaimed,
+		}
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+		return exitcode.Success
+	}
+
+	if len(garbage) == 0 {
+		fmt.Println("Nothing to collect.")
+		return exitcode.Success
+	}
+	verb := "Removed"
+	if dryRun {
+		verb = "Would remove"
+	}
+	fmt.Printf("%s %d item(s), reclaiming %s:\n", verb, len(garbage), formatBytes(reclaimed))
+	for _, g := range garbage {
+		fmt.Printf("  %s\n    %s\n", g.Path, g.Reason)
+	}
+	return exitcode.Success
+}
+
+// runStoreFsck executes "psk store fsck".
+func runStoreFsck(args []string) int {
+	var jsonOutput bool
+
+	for _, arg := range args {
+		switch arg {
+		case "--json":
+			jsonOutput = true
+		default:
+			fmt.Fprintf(os.Stderr, "error: unexpected argument %s\n\n%s\n", arg, storeUsage)
+			return exitcode.ErrValidation
+		}
+	}
+
+	s, code := openStore()
+	if s == nil {
+		return code
+	}
+	entries, err := s.Entries()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrIO
+	}
+
+	results := []fsck.Result{}
+	failed := 0
+	for _, e := range entries {
+		r := fsck.Check(s, e)
+		if !r.OK() {
+			failed++
+		}
+		results = append(results, r)
+	}
+	code = exitcode.Success
+	if failed > 0 {
+		code = exitcode.ErrIntegrity
+	}
+
+	if jsonOutput {
+		data, _ := json.MarshalIndent(results, "", "  ")
+		if code == exitcode.Success {
+			fmt.Println(string(data))
+		} else {
+			fmt.Fprintln(os.Stderr, string(data))
+		}
+		return code
+	}
+
+	if len(results) == 0 {
+		fmt.Println("No skills found in store.")
+		return code
+	}
+
+	nameW, versionW := 4, 7 // header lengths
+	for _, r := range results {
+		if len(r.Name) > nameW {
+			nameW = len(r.Name)
+		}
+		if len(r.Version) > versionW {
+			versionW = len(r.Version)
+		}
+	}
+	fmtStr := fmt.Sprintf("%%-%ds  %%-%ds  %%s\n", nameW, versionW)
+	fmt.Printf(fmtStr, "NAME", "VERSION", "STATUS")
+	for _, r := range results {
+		status := "ok"
+		if !r.OK() {
+			status = fmt.Sprintf("%d problem(s)", len(r.Problems))
+		}
+		fmt.Printf(fmtStr, r.Name, r.Version, status)
+		for _, p := range r.Problems {
+			fmt.Printf("  - %s\n", p)
+		}
+	}
+	fmt.Printf("\nChecked %d artifact(s), %d with problems.\n", len(results), failed)
+	return code
+}
+
+// runStoreMigrate executes "psk store migrate".
+func runStoreMigrate(args []string) int {
+	var dryRun, jsonOutput bool
+
+	for _, arg := range args {
+		switch arg {
+		case "--dry-run":
+			dryRun = true
+		case "--json":
+			jsonOutput = true
+		default:
+			fmt.Fprintf(os.Stderr, "error: unexpected argument %s\n\n%s\n", arg, storeUsage)
+			return exitcode.ErrValidation
+		}
+	}
+
+	s := store.New("")
+	pending, version, err := s.Pending()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		if errors.Is(err, store.ErrNewerVersion) {
+			return exitcode.ErrValidation
+		}
+		return exitcode.ErrIO
+	}
+
+	var backup string
+	if len(pending) > 0 {
+		backup = s.BackupPath(version)
+		if !dryRun {
+			if _, err := s.Open(); err != nil {
+				return printStoreError(err)
+			}
+		}
+	}
+
+	if jsonOutput {
+		type migrationEntry struct {
+			From        int    `json:"from"`
+			To          int    `json:"to"`
+			Description string `json:"description"`
+		}
+		entries := []migrationEntry{}
+		for _, m := range pending {
+			entries = append(entries, migrationEntry{From: m.From, To: m.From + 1, Description: m.Description})
+		}
+		result := map[string]interface{}{
+			"dryRun":     dryRun,
+			"version":    version,
+			"target":     store.Version,
+			"migrations": entries,
+		}
+		if backup != "" {
+			result["backup"] = backup
+		}
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(data))
+		return exitcode.Success
+	}
+
+	if len(pending) == 0 {
+		fmt.Printf("Store is up to date (version %d).\n", store.Version)
+		return exitcode.Success
+	}
+	if dryRun {
+		fmt.Printf("Would migrate store from version %d to %d:\n", version, store.Version)
+	} else {
+		fmt.Printf("Migrated store from version %d to %d:\n", version, store.Version)
+	}
+	for _, m := range pending {
+		fmt.Printf("  %d -> %d  %s\n", m.From, m.From+1, m.Description)
+	}
+	fmt.Printf("  backup:  %s\n", backup)
+	return exitcode.Success
+}
+
+// formatBytes formats n bytes with a binary unit, e.g. "1.5 MiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/exitcode"
+	"github.com/c8ab/provenskills/internal/trust"
+)
+
+const trustUsage = `Usage:
+  psk trust add <public-key-file> --identity "Name <email>" [--force] [--json]
+  psk trust list [--json]
+  psk trust remove <key-id|identity>`
+
+// RunTrust executes the "psk trust" command group.
+func RunTrust(args []string) int {
+	if len(args) < 1 {
+		fmt.Fprintf(os.Stderr, "error: subcommand is required\n\n%s\n", trustUsage)
+		return exitcode.ErrValidation
+	}
+	switch args[0] {
+	case "add":
+		return runTrustAdd(args[1:])
+	case "list":
+		return runTrustList(args[1:])
+	case "remove":
+		return runTrustRemove(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "error: unknown trust subcommand %q\n\n%s\n", args[0], trustUsage)
+		return exitcode.ErrValidation
+	}
+}
+
+// runTrustAdd executes "psk trust add".
+func runTrustAdd(args []string) int {
+	var keyPath, identity string
+	var force, jsonOutput bool
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--identity":
+			if i+1 < len(args) {
+				i++
+				identity = args[i]
+			}
+		case "--force":
+			force = true
+		case "--json":
+			jsonOutput = true
+		default:
+			if keyPath != "" || strings.HasPrefix(args[i], "-") {
+				fmt.Fprintf(os.Stderr, "error: unexpected argument %s\n\n%s\n", args[i], trustUsage)
+				return exitcode.ErrValidation
+			}
+			keyPath = args[i]
+		}
+	}
+
+	if keyPath == "" {
+		fmt.Fprintf(os.Stderr, "error: public key argument is required\n\n%s\n", trustUsage)
+		return exitcode.ErrValidation
+	}
+	if strings.TrimSpace(identity) == "" {
+		fmt.Fprintf(os.Stderr, "error: --identity flag is required\n\n%s\n", trustUsage)
+		return exitcode.ErrValidation
+	}
+
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: failed to read %s: %v\n", keyPath, err)
+		return exitcode.ErrIO
+	}
+
+	k, err := trust.New("").Add(data, identity, force)
+	if errors.Is(err, trust.ErrKeyExists) {
+		fmt.Fprintf(os.Stderr, "error: %v\n\nUse --force to change its identity.\n", err)
+		return exitcode.ErrConflict
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrValidation
+	}
+
+	if jsonOutput {
+		data, _ := json.MarshalIndent(k, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Trusted key: %s\n", k.KeyID)
+		fmt.Printf("  identity:  %s\n", k.Identity)
+		fmt.Printf("  algorithm: %s\n", k.Algorithm)
+	}
+	return exitcode.Success
+}
+
+// runTrustList executes "psk trust list".
+func runTrustList(args []string) int {
+	var jsonOutput bool
+	for _, arg := range args {
+		if arg != "--json" {
+			fmt.Fprintf(os.Stderr, "error: unexpected argument %s\n\n%s\n", arg, trustUsage)
+			return exitcode.ErrValidation
+		}
+		jsonOutput = true
+	}
+
+	keys, err := trust.New("").List()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrIO
+	}
+
+	if jsonOutput {
+		if keys == nil {
+			keys = []trust.Key{}
+		}
+		data, _ := json.MarshalIndent(keys, "", "  ")
+		fmt.Println(string(data))
+		return exitcode.Success
+	}
+
+	if len(keys) == 0 {
+		fmt.Println("No trusted keys.")
+		return exitcode.Success
+	}
+	// Calculate column widths
+	identityW, algorithmW := 8, 9 // header lengths
+	for _, k := range keys {
+		if len(k.Identity) > identityW {
+			identityW = len(k.Identity)
+		}
+		if len(k.Algorithm) > algorithmW {
+			algorithmW = len(k.Algorithm)
+		}
+	}
+
+	fmtStr := fmt.Sprintf("%%-%ds  %%-%ds  %%s\n", identityW, algorithmW)
+	fmt.Printf(fmtStr, "IDENTITY", "ALGORITHM", "KEY ID")
+	for _, k := range keys {
+		fmt.Printf(fmtStr, k.Identity, k.Algorithm, k.KeyID)
+	}
+	return exitcode.Success
+}
+
+// runTrustRemove executes "psk trust remove".
+func runTrustRemove(args []string) int {
+	if len(args) != 1 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprintf(os.Stderr, "error: expected a single key id or identity\n\n%s\n", trustUsage)
+		return exitcode.ErrValidation
+	}
+
+	k, err := trust.New("").Remove(args[0])
+	if errors.Is(err, trust.ErrKeyNotFound) {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrIO
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrValidation
+	}
+	fmt.Printf("Removed trusted key: %s (%s)\n", k.KeyID, k.Identity)
+	return exitcode.Success
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/exitcode"
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+const verifyUsage = "Usage: psk verify <name>[@<version>] [--json]"
+
+// RunVerify executes the "psk verify" command.
+func RunVerify(args []string) int {
+	var refArg string
+	var jsonOutput bool
+
+	for _, arg := range args {
+		switch {
+		case arg == "--json":
+			jsonOutput = true
+		case !strings.HasPrefix(arg, "-") && refArg == "":
+			refArg = arg
+		default:
+			fmt.Fprintf(os.Stderr, "error: unexpected argument %s\n\n%s\n", arg, verifyUsage)
+			return exitcode.ErrValidation
+		}
+	}
+
+	if refArg == "" {
+		fmt.Fprintf(os.Stderr, "error: skill argument is required\n\n%s\n", verifyUsage)
+		return exitcode.ErrValidation
+	}
+
+	name, version, err := parseSkillQuery(refArg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrValidation
+	}
+
+	s, code := openStore()
+	if s == nil {
+		return code
+	}
+	if version, code = resolveSkillVersion(s, name, version); version == "" {
+		return code
+	}
+	if !s.Exists(name, version) {
+		fmt.Fprintf(os.Stderr, "error: skill %s@%s not found in store\n", name, version)
+		return exitcode.ErrIO
+	}
+	dir := s.ArtifactPath(name, version)
+
+	manifest, err := store.ReadManifest(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrIO
+	}
+	if manifest.SourceHash == "" {
+		fmt.Fprintf(os.Stderr, "error: skill %s@%s has no sourceHash to verify against\n\nRebuild it with this version of psk.\n", name, version)
+		return exitcode.ErrValidation
+	}
+
+	actual, err := store.HashTree(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrIO
+	}
+	ok := actual == manifest.SourceHash
+
+	// Pinpoint which files differ when per-file digests were recorded
+	var problems []string
+	if !ok && len(manifest.Contents.Files()) > 0 {
+		problems, err = store.CheckContents(dir, manifest.Contents)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return exitcode.ErrIO
+		}
+	}
+
+	if jsonOutput {
+		result := map[string]interface{}{
+			"name":     name,
+			"version":  version,
+			"verified": ok,
+			"expected": manifest.SourceHash,
+			"actual":   actual,
+		}
+		if len(problems) > 0 {
+			result["problems"] = problems
+		}
+		data, _ := json.MarshalIndent(result, "", "  ")
+		if ok {
+			fmt.Println(string(data))
+		} else {
+			fmt.Fprintln(os.Stderr, string(data))
+		}
+	} else if ok {
+		fmt.Printf("Verified skill: %s@%s\n", name, version)
+		fmt.Printf("  sourceHash: %s\n", actual)
+	} else {
+		fmt.Fprintf(os.Stderr, "error: verification failed for %s@%s\n\n", name, version)
+		fmt.Fprintf(os.Stderr, "  expected: %s\n", manifest.SourceHash)
+		fmt.Fprintf(os.Stderr, "  actual:   %s\n", actual)
+		if len(problems) > 0 {
+			fmt.Fprintln(os.Stderr)
+			for _, p := range problems {
+				fmt.Fprintf(os.Stderr, "  - %s\n", p)
+			}
+		}
+	}
+
+	if !ok {
+		return exitcode.ErrIntegrity
+	}
+	return exitcode.Success
+}
@@ -0,0 +1,222 @@
+package cli
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/cosign"
+	"github.com/c8ab/provenskills/internal/exitcode"
+	"github.com/c8ab/provenskills/internal/oci"
+	"github.com/c8ab/provenskills/internal/signing"
+	"github.com/c8ab/provenskills/internal/trust"
+)
+
+const verifyCosignUsage = "Usage: psk verify-cosign <registry>/<repository>[:<tag>|@<digest>] [--key <file>] [--json]"
+
+// cosignKey is a public key cosign signatures are checked against.
+type cosignKey struct {
+	keyID    string
+	identity string
+	pub      crypto.PublicKey
+}
+
+// cosignResult is the outcome of checking one cosign signature.
+type cosignResult struct {
+	Status   string `json:"status"`
+	KeyID    string `json:"keyId,omitempty"`
+	Identity string `json:"identity,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// RunVerifyCosign executes the "psk verify-cosign" command. Signatures are
+// checked against the key given with --key, or against every key in the
+// trust store.
+func RunVerifyCosign(args []string) int {
+	var refArg, keyPath string
+	var jsonOutput bool
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--key":
+			if i+1 < len(args) {
+				i++
+				keyPath = args[i]
+			}
+		case "--json":
+			jsonOutput = true
+		default:
+			if refArg != "" || strings.HasPrefix(args[i], "-") {
+				fmt.Fprintf(os.Stderr, "error: unexpected argument %s\n\n%s\n", args[i], verifyCosignUsage)
+				return exitcode.ErrValidation
+			}
+			refArg = args[i]
+		}
+	}
+
+	if refArg == "" {
+		fmt.Fprintf(os.Stderr, "error: registry reference is required\n\n%s\n", verifyCosignUsage)
+		return exitcode.ErrValidation
+	}
+	ref, err := oci.ParseReference(refArg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrValidation
+	}
+	if ref.Tag == "" && ref.Digest == "" {
+		fmt.Fprintf(os.Stderr, "error: reference must include a tag or digest\n\n%s\n", verifyCosignUsage)
+		return exitcode.ErrValidation
+	}
+
+	keys, code := cosignKeys(keyPath)
+	if code != exitcode.Success {
+		return code
+	}
+
+	ctx := context.Background()
+	client := oci.NewClient()
+	data, err := client.GetManifest(ctx, ref)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		if errors.Is(err, oci.ErrNotFound) {
+			return exitcode.ErrNotFound
+		}
+		return exitcode.ErrIO
+	}
+	digest := oci.Digest(data)
+
+	sigs, err := cosign.Fetch(ctx, client, ref, digest)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: failed to fetch cosign signatures: %v\n", err)
+		return exitcode.ErrIO
+	}
+	if len(sigs) == 0 {
+		fmt.Fprintf(os.Stderr, "error: no cosign signatures found for %s\n", ref)
+		return exitcode.ErrUnsigned
+	}
+
+	results := make([]cosignResult, 0, len(sigs))
+	var trusted, invalid int
+	for _, sig := range sigs {
+		r := checkCosignSignature(sig, digest, keys)
+		switch r.Status {
+		case trust.StatusTrusted:
+			trusted++
+		case trust.StatusInvalid:
+			invalid++
+		}
+		results = append(results, r)
+	}
+
+	code, reason := exitcode.Success, ""
+	switch {
+	case trusted > 0:
+	case invalid > 0:
+		code, reason = exitcode.ErrBadSignature, fmt.Sprintf("%d signature(s) do not sign %s", invalid, digest)
+	case keyPath != "":
+		code, reason = exitcode.ErrUntrustedSigner, "no signature was made by "+keyPath
+	default:
+		code, reason = exitcode.ErrUntrustedSigner, "no signature was made by a key in the trust store"
+	}
+
+	if jsonOutput {
+		result := map[string]interface{}{
+			"reference":  ref.String(),
+			"digest":     digest,
+			"verified":   code == exitcode.Success,
+			"signatures": results,
+		}
+		if reason != "" {
+			result["error"] = reason
+		}
+		data, _ := json.MarshalIndent(result, "", "  ")
+		if code == exitcode.Success {
+			fmt.Println(string(data))
+		} else {
+			fmt.Fprintln(os.Stderr, string(data))
+		}
+		return code
+	}
+
+	out := os.Stdout
+	if code == exitcode.Success {
+		fmt.Printf("Verified cosign signature: %s\n", ref)
+	} else {
+		out = os.Stderr
+		fmt.Fprintf(os.Stderr, "error: cosign verification failed for %s: %s\n\n", ref, reason)
+	}
+	fmt.Fprintf(out, "  digest:     %s\n", digest)
+	fmt.Fprintln(out, "  signatures:")
+	for _, r := range results {
+		line := fmt.Sprintf("    %-10s", r.Status)
+		if r.KeyID != "" {
+			line += " " + r.KeyID
+		}
+		if r.Identity != "" {
+			line += "  " + r.Identity
+		}
+		if r.Error != "" {
+			line += "  " + r.Error
+		}
+		fmt.Fprintln(out, line)
+	}
+	return code
+}
+
+// cosignKeys returns the key at keyPath, or every trusted key when keyPath
+// is empty.
+func cosignKeys(keyPath string) ([]cosignKey, int) {
+	if keyPath != "" {
+		pub, err := signing.LoadPublicKey(keyPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: failed to load key %s: %v\n", keyPath, err)
+			if os.IsNotExist(err) {
+				return nil, exitcode.ErrIO
+			}
+			return nil, exitcode.ErrValidation
+		}
+		keyID, err := signing.KeyID(pub)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return nil, exitcode.ErrValidation
+		}
+		return []cosignKey{{keyID: keyID, pub: pub}}, exitcode.Success
+	}
+
+	trusted, err := trust.New("").List()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return nil, exitcode.ErrIO
+	}
+	keys := make([]cosignKey, 0, len(trusted))
+	for _, k := range trusted {
+		pub, err := k.Public()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return nil, exitcode.ErrIO
+		}
+		keys = append(keys, cosignKey{keyID: k.KeyID, identity: k.Identity, pub: pub})
+	}
+	return keys, exitcode.Success
+}
+
+// checkCosignSignature finds the key that made sig. Cosign signatures do
+// not name their key, so each candidate is tried in turn; a signature no
+// candidate made is untrusted.
+func checkCosignSignature(sig cosign.Signature, digest string, keys []cosignKey) cosignResult {
+	for _, k := range keys {
+		if sig.Verify(k.pub) != nil {
+			continue
+		}
+		r := cosignResult{KeyID: k.keyID, Identity: k.identity, Status: trust.StatusTrusted}
+		if err := sig.Check(digest); err != nil {
+			r.Status, r.Error = trust.StatusInvalid, err.Error()
+		}
+		return r
+	}
+	return cosignResult{Status: trust.StatusUntrusted}
+}
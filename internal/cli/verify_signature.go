@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/exitcode"
+	"github.com/c8ab/provenskills/internal/signing"
+	"github.com/c8ab/provenskills/internal/store"
+	"github.com/c8ab/provenskills/internal/trust"
+)
+
+const verifySignatureUsage = "Usage: psk verify-signature <name>[@<version>] [--require-maintainer] [--json]"
+
+// RunVerifySignature executes the "psk verify-signature" command.
+func RunVerifySignature(args []string) int {
+	var refArg string
+	var requireMaintainer, jsonOutput bool
+
+	for _, arg := range args {
+		switch {
+		case arg == "--require-maintainer":
+			requireMaintainer = true
+		case arg == "--json":
+			jsonOutput = true
+		case !strings.HasPrefix(arg, "-") && refArg == "":
+			refArg = arg
+		default:
+			fmt.Fprintf(os.Stderr, "error: unexpected argument %s\n\n%s\n", arg, verifySignatureUsage)
+			return exitcode.ErrValidation
+		}
+	}
+
+	if refArg == "" {
+		fmt.Fprintf(os.Stderr, "error: skill argument is required\n\n%s\n", verifySignatureUsage)
+		return exitcode.ErrValidation
+	}
+
+	name, version, err := parseSkillQuery(refArg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrValidation
+	}
+
+	s, code := openStore()
+	if s == nil {
+		return code
+	}
+	if version, code = resolveSkillVersion(s, name, version); version == "" {
+		return code
+	}
+	if !s.Exists(name, version) {
+		fmt.Fprintf(os.Stderr, "error: skill %s@%s not found in store\n", name, version)
+		return exitcode.ErrIO
+	}
+	dir := s.ArtifactPath(name, version)
+
+	manifest, err := store.ReadManifest(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrIO
+	}
+	sigs, err := signing.Load(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrBadSignature
+	}
+	if len(sigs) == 0 {
+		fmt.Fprintf(os.Stderr, "error: skill %s@%s is not signed\n\nSign it with: psk sign %s@%s --key <file>\n", name, version, name, version)
+		return exitcode.ErrUnsigned
+	}
+
+	digest, code := storedDigest(dir)
+	if digest == "" {
+		return code
+	}
+
+	results, err := trust.New("").Verify(digest, sigs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return exitcode.ErrIO
+	}
+
+	code, reason := signatureVerdict(results, manifest.Maintainer, requireMaintainer)
+
+	if jsonOutput {
+		entries := make([]map[string]interface{}, 0, len(results))
+		for _, r := range results {
+			entry := map[string]interface{}{
+				"keyId":     r.Signature.KeyID,
+				"algorithm": r.Signature.Algorithm,
+				"status":    r.Status,
+			}
+			if r.Key != nil {
+				entry["identity"] = r.Key.Identity
+				entry["maintainer"] = trust.MatchesMaintainer(r.Key.Identity, manifest.Maintainer)
+			}
+			if r.Err != nil {
+				entry["error"] = r.Err.Error()
+			}
+			entries = append(entries, entry)
+		}
+		result := map[string]interface{}{
+			"name":       name,
+			"version":    version,
+			"digest":     digest,
+			"maintainer": manifest.Maintainer,
+			"verified":   code == exitcode.Success,
+			"signatures": entries,
+		}
+		if reason != "" {
+			result["error"] = reason
+		}
+		data, _ := json.MarshalIndent(result, "", "  ")
+		if code == exitcode.Success {
+			fmt.Println(string(data))
+		} else {
+			fmt.Fprintln(os.Stderr, string(data))
+		}
+		return code
+	}
+
+	out := os.Stdout
+	if code == exitcode.Success {
+		fmt.Printf("Verified signature: %s@%s\n", name, version)
+	} else {
+		out = os.Stderr
+		fmt.Fprintf(os.Stderr, "error: signature verification failed for %s@%s: %s\n\n", name, version, reason)
+	}
+	printSignatureResults(out, digest, manifest.Maintainer, results)
+	return code
+}
+
+// signatureVerdict decides the outcome of verify-signature. At least one
+// signature must verify and come from a trusted key; with
+// requireMaintainer that key's identity must also match the maintainer.
+// Otherwise a bad signature takes precedence over an unknown signer.
+func signatureVerdict(results []trust.Result, maintainer string, requireMaintainer bool) (int, string) {
+	var trusted, invalid int
+	byMaintainer := false
+	for _, r := range results {
+		switch r.Status {
+		case trust.StatusTrusted:
+			trusted++
+			if trust.MatchesMaintainer(r.Key.Identity, maintainer) {
+				byMaintainer = true
+			}
+		case trust.StatusInvalid:
+			invalid++
+		}
+	}
+
+	switch {
+	case trusted > 0 && requireMaintainer && !byMaintainer:
+		return exitcode.ErrUntrustedSigner, fmt.Sprintf("no trusted signer matches maintainer %q", maintainer)
+	case trusted > 0:
+		return exitcode.Success, ""
+	case invalid > 0:
+		return exitcode.ErrBadSignature, fmt.Sprintf("%d signature(s) do not verify", invalid)
+	default:
+		return exitcode.ErrUntrustedSigner, "signed only by keys that are not in the trust store"
+	}
+}
+
+// printSignatureResults writes the per-signature report of
+// verify-signature.
+func printSignatureResults(w io.Writer, digest, maintainer string, results []trust.Result) {
+	fmt.Fprintf(w, "  digest:     %s\n", digest)
+	fmt.Fprintf(w, "  maintainer: %s\n", maintainer)
+	fmt.Fprintln(w, "  signatures:")
+	for _, r := range results {
+		line := fmt.Sprintf("    %-10s %s", r.Status, r.Signature.KeyID)
+		switch {
+		case r.Key != nil:
+			line += "  " + r.Key.Identity
+			if trust.MatchesMaintainer(r.Key.Identity, maintainer) {
+				line += " (maintainer)"
+			}
+		case r.Err != nil:
+			line += "  " + r.Err.Error()
+		}
+		fmt.Fprintln(w, line)
+	}
+}
@@ -0,0 +1,252 @@
+// Package cosign reads and writes signatures in the layout used by
+// sigstore cosign, so registries' existing cosign verification policies
+// apply to skill artifacts unchanged.
+//
+// A cosign signature signs a "simple signing" JSON payload naming the
+// signed manifest digest. Signatures are stored as layers of an image
+// manifest tagged "sha256-<hex>.sig" in the signed artifact's repository,
+// or, by newer cosign releases, as OCI 1.1 referrers of the artifact.
+package cosign
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/oci"
+	"github.com/c8ab/provenskills/internal/signing"
+)
+
+const (
+	// MediaTypeSimpleSigning is the media type of a simple signing
+	// payload layer.
+	MediaTypeSimpleSigning = "application/vnd.dev.cosign.simplesigning.v1+json"
+	// ArtifactTypeSignature identifies a cosign signature stored as an
+	// OCI 1.1 referrer.
+	ArtifactTypeSignature = "application/vnd.dev.cosign.artifact.sig.v1+json"
+	// AnnotationSignature carries the base64 signature over a payload
+	// layer.
+	AnnotationSignature = "dev.cosignproject.cosign/signature"
+	// SignatureType is the critical.type of a simple signing payload.
+	SignatureType = "cosign container image signature"
+
+	mediaTypeImageConfig = "application/vnd.oci.image.config.v1+json"
+)
+
+// ErrWrongDigest is returned by Signature.Check when a payload signs a
+// different manifest.
+var ErrWrongDigest = errors.New("signature is for a different digest")
+
+// Payload is a simple signing payload.
+type Payload struct {
+	Critical Critical               `json:"critical"`
+	Optional map[string]interface{} `json:"optional"`
+}
+
+// Critical holds the claims a verifier must check.
+type Critical struct {
+	Identity Identity `json:"identity"`
+	Image    Image    `json:"image"`
+	Type     string   `json:"type"`
+}
+
+// Identity names the repository the signed image was pushed to.
+type Identity struct {
+	DockerReference string `json:"docker-reference"`
+}
+
+// Image names the signed manifest.
+type Image struct {
+	DockerManifestDigest string `json:"docker-manifest-digest"`
+}
+
+// Signature is a simple signing payload and its base64 signature.
+type Signature struct {
+	Payload   []byte
+	Signature string
+}
+
+// SignatureTag returns the tag cosign stores the signatures of the
+// manifest with the given digest under.
+func SignatureTag(digest string) string {
+	return strings.Replace(digest, ":", "-", 1) + ".sig"
+}
+
+// Sign signs the manifest digest pushed to the repository in ref. ECDSA
+// keys sign the SHA-256 of the payload and Ed25519 keys the payload
+// itself, as cosign does.
+func Sign(key crypto.Signer, ref oci.Reference, digest string) (Signature, error) {
+	payload, err := json.Marshal(Payload{Critical: Critical{
+		Identity: Identity{DockerReference: ref.Registry + "/" + ref.Repository},
+		Image:    Image{DockerManifestDigest: digest},
+		Type:     SignatureType,
+	}})
+	if err != nil {
+		return Signature{}, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	sig, err := signing.SignPayload(key, payload)
+	if err != nil {
+		return Signature{}, fmt.Errorf("failed to sign: %w", err)
+	}
+	return Signature{Payload: payload, Signature: base64.StdEncoding.EncodeToString(sig)}, nil
+}
+
+// Verify checks that s was made by pub. It does not look at the payload
+// claims; see Check.
+func (s Signature) Verify(pub crypto.PublicKey) error {
+	sig, err := base64.StdEncoding.DecodeString(s.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to decode signature: %w", err)
+	}
+	return signing.VerifyPayload(pub, s.Payload, sig)
+}
+
+// Check checks that the payload of s signs the manifest with digest.
+func (s Signature) Check(digest string) error {
+	var p Payload
+	if err := json.Unmarshal(s.Payload, &p); err != nil {
+		return fmt.Errorf("failed to parse payload: %w", err)
+	}
+	if p.Critical.Type != SignatureType {
+		return fmt.Errorf("unexpected payload type %q", p.Critical.Type)
+	}
+	if p.Critical.Image.DockerManifestDigest != digest {
+		return fmt.Errorf("%w: %s", ErrWrongDigest, p.Critical.Image.DockerManifestDigest)
+	}
+	return nil
+}
+
+// Push adds sigs to the signature manifest of digest in the repository of
+// ref, keeping the signatures already there.
+func Push(ctx context.Context, c *oci.Client, ref oci.Reference, digest string, sigs ...Signature) error {
+	tagRef := ref
+	tagRef.Tag, tagRef.Digest = SignatureTag(digest), ""
+
+	var layers []oci.Descriptor
+	data, err := c.GetManifest(ctx, tagRef)
+	switch {
+	case errors.Is(err, oci.ErrNotFound):
+	case err != nil:
+		return err
+	default:
+		var m oci.Manifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			return fmt.Errorf("failed to parse signature manifest: %w", err)
+		}
+		layers = m.Layers
+	}
+
+	for _, s := range sigs {
+		desc := oci.NewDescriptor(MediaTypeSimpleSigning, s.Payload)
+		desc.Annotations = map[string]string{AnnotationSignature: s.Signature}
+		if containsLayer(layers, desc) {
+			continue
+		}
+		if err := c.PushBlob(ctx, tagRef, desc.Digest, s.Payload); err != nil {
+			return err
+		}
+		layers = append(layers, desc)
+	}
+
+	config, err := imageConfig(layers)
+	if err != nil {
+		return err
+	}
+	configDesc := oci.NewDescriptor(mediaTypeImageConfig, config)
+	if err := c.PushBlob(ctx, tagRef, configDesc.Digest, config); err != nil {
+		return err
+	}
+	data, err = json.Marshal(oci.Manifest{
+		SchemaVersion: 2,
+		MediaType:     oci.MediaTypeImageManifest,
+		Config:        configDesc,
+		Layers:        layers,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal signature manifest: %w", err)
+	}
+	_, err = c.PushManifest(ctx, tagRef, tagRef.Tag, oci.MediaTypeImageManifest, data)
+	return err
+}
+
+// Fetch returns the cosign signatures of the manifest with digest in the
+// repository of ref, from both the signature tag and OCI 1.1 referrers.
+func Fetch(ctx context.Context, c *oci.Client, ref oci.Reference, digest string) ([]Signature, error) {
+	tagRef := ref
+	tagRef.Tag, tagRef.Digest = SignatureTag(digest), ""
+	manifests := []oci.Reference{tagRef}
+
+	descs, err := c.Referrers(ctx, ref, digest, ArtifactTypeSignature)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range descs {
+		byDigest := ref
+		byDigest.Tag, byDigest.Digest = "", d.Digest
+		manifests = append(manifests, byDigest)
+	}
+
+	var sigs []Signature
+	for _, mref := range manifests {
+		data, err := c.GetManifest(ctx, mref)
+		if errors.Is(err, oci.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var m oci.Manifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("failed to parse signature manifest: %w", err)
+		}
+		for _, layer := range m.Layers {
+			sig, ok := layer.Annotations[AnnotationSignature]
+			if layer.MediaType != MediaTypeSimpleSigning || !ok {
+				continue
+			}
+			payload, err := c.GetBlob(ctx, mref, layer)
+			if err != nil {
+				return nil, err
+			}
+			sigs = append(sigs, Signature{Payload: payload, Signature: sig})
+		}
+	}
+	return sigs, nil
+}
+
+// containsLayer reports whether layers holds desc with the same signature.
+func containsLayer(layers []oci.Descriptor, desc oci.Descriptor) bool {
+	for _, l := range layers {
+		if l.Digest == desc.Digest && l.Annotations[AnnotationSignature] == desc.Annotations[AnnotationSignature] {
+			return true
+		}
+	}
+	return false
+}
+
+// imageConfig returns the image config of a signature manifest. The
+// payload layers are stored uncompressed, so their diff ids are their
+// digests.
+func imageConfig(layers []oci.Descriptor) ([]byte, error) {
+	diffIDs := make([]string, 0, len(layers))
+	for _, l := range layers {
+		diffIDs = append(diffIDs, l.Digest)
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"architecture": "",
+		"os":           "",
+		"config":       map[string]interface{}{},
+		"rootfs": map[string]interface{}{
+			"type":     "layers",
+			"diff_ids": diffIDs,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal signature config: %w", err)
+	}
+	return data, nil
+}
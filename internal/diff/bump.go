@@ -0,0 +1,150 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/policy"
+	"github.com/c8ab/provenskills/internal/skill"
+)
+
+// Bump is the part of a SemVer version that a release increments.
+type Bump int
+
+// Version bumps, from smallest to largest.
+const (
+	BumpNone Bump = iota
+	BumpPatch
+	BumpMinor
+	BumpMajor
+)
+
+func (b Bump) String() string {
+	switch b {
+	case BumpPatch:
+		return "patch"
+	case BumpMinor:
+		return "minor"
+	case BumpMajor:
+		return "major"
+	}
+	return "none"
+}
+
+// MarshalJSON encodes the bump as its name.
+func (b Bump) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + b.String() + `"`), nil
+}
+
+// Reason is a change between two versions of a skill and the bump it
+// requires.
+type Reason struct {
+	Bump   Bump   `json:"bump"`
+	Change string `json:"change"`
+}
+
+// RequiredBump returns the smallest version bump that the changes in r
+// allow, and the changes that require a bump, largest bump first.
+//
+// Changes that can break users of the skill require a major bump: new
+// allowed-tools, removed scripts, and a changed compatibility or license.
+// New files and removed references or assets require a minor bump; any
+// other change to the frontmatter, the SKILL.md body or a file at least a
+// patch. The version, source hash, maintainer and build timestamp are not
+// changes of their own.
+func RequiredBump(r Result) (Bump, []Reason) {
+	reasons := []Reason{}
+	for _, c := range r.Frontmatter {
+		switch c.Field {
+		case "name", "metadata.version":
+		case "allowed-tools":
+			added, removed := toolChanges(c.Old, c.New)
+			if len(added) > 0 {
+				reasons = append(reasons, Reason{BumpMajor, "allowed-tools: adds " + strings.Join(added, ", ")})
+			}
+			if len(removed) > 0 {
+				reasons = append(reasons, Reason{BumpPatch, "allowed-tools: removes " + strings.Join(removed, ", ")})
+			}
+		case "compatibility", "license":
+			reasons = append(reasons, Reason{BumpMajor, fmt.Sprintf("%s: changed from %q to %q", c.Field, c.Old, c.New)})
+		default:
+			reasons = append(reasons, Reason{BumpPatch, c.Field + ": changed"})
+		}
+	}
+	if r.Body != "" {
+		reasons = append(reasons, Reason{BumpPatch, "SKILL.md: body changed"})
+	}
+	for _, f := range r.Files {
+		switch {
+		case f.Status == StatusAdded:
+			reasons = append(reasons, Reason{BumpMinor, f.Path + ": added"})
+		case f.Status == StatusRemoved && strings.HasPrefix(f.Path, "scripts/"):
+			reasons = append(reasons, Reason{BumpMajor, f.Path + ": removed"})
+		case f.Status == StatusRemoved:
+			reasons = append(reasons, Reason{BumpMinor, f.Path + ": removed"})
+		default:
+			reasons = append(reasons, Reason{BumpPatch, f.Path + ": modified"})
+		}
+	}
+
+	sort.SliceStable(reasons, func(i, j int) bool {
+		return reasons[i].Bump > reasons[j].Bump
+	})
+	required := BumpNone
+	if len(reasons) > 0 {
+		required = reasons[0].Bump
+	}
+	return required, reasons
+}
+
+// toolChanges returns the tools of the allowed-tools value to that are not
+// in from, and those of from that are not in to.
+func toolChanges(from, to string) (added, removed []string) {
+	fromTools := policy.SplitTools(from)
+	toTools := policy.SplitTools(to)
+	in := func(tools []string, t string) bool {
+		for _, o := range tools {
+			if o == t {
+				return true
+			}
+		}
+		return false
+	}
+	for _, t := range toTools {
+		if !in(fromTools, t) {
+			added = append(added, t)
+		}
+	}
+	for _, t := range fromTools {
+		if !in(toTools, t) {
+			removed = append(removed, t)
+		}
+	}
+	return added, removed
+}
+
+// VersionBump returns the bump from one version to a higher one, or
+// BumpNone if to is not higher than from.
+//
+// Below 1.0.0 the minor version counts as major and the patch version as
+// minor, as SemVer makes no promises there. Moving between prereleases of
+// a version, or from a prerelease to its release, counts as major too,
+// since prereleases may change anything.
+func VersionBump(from, to skill.Version) Bump {
+	switch {
+	case to.Compare(from) <= 0:
+		return BumpNone
+	case to.Major != from.Major:
+		return BumpMajor
+	case to.Minor != from.Minor && to.Major == 0:
+		return BumpMajor
+	case to.Minor != from.Minor:
+		return BumpMinor
+	case to.Patch != from.Patch && to.Major == 0:
+		return BumpMinor
+	case to.Patch != from.Patch:
+		return BumpPatch
+	}
+	return BumpMajor
+}
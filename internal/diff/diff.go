@@ -0,0 +1,149 @@
+// Package diff compares two versions of a skill for review.
+package diff
+
+import (
+	"sort"
+
+	"github.com/c8ab/provenskills/internal/skill"
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+// File change statuses.
+const (
+	StatusAdded    = "added"
+	StatusRemoved  = "removed"
+	StatusModified = "modified"
+)
+
+// Side is one of the two skills being compared: a stored artifact or a
+// source directory.
+type Side struct {
+	// Label names the side in output, e.g. "my-skill@1.0.0" or a path.
+	Label    string
+	Manifest store.Manifest
+	Skill    skill.SkillFrontmatter
+	// Body is the SKILL.md content after the frontmatter.
+	Body string
+	// Source is true for a source directory, whose manifest is computed
+	// and has no maintainer or build timestamp.
+	Source bool
+}
+
+// FieldChange is a manifest or frontmatter field whose value differs.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// FileChange is a skill file that was added, removed or modified. Old is
+// nil for an added file and New for a removed one.
+type FileChange struct {
+	Path   string           `json:"path"`
+	Status string           `json:"status"`
+	Old    *store.FileEntry `json:"old,omitempty"`
+	New    *store.FileEntry `json:"new,omitempty"`
+}
+
+// Result is the difference between two skills.
+type Result struct {
+	Old         string        `json:"old"`
+	New         string        `json:"new"`
+	Manifest    []FieldChange `json:"manifest"`
+	Frontmatter []FieldChange `json:"frontmatter"`
+	// Body is a unified diff of the SKILL.md bodies, or "" if they match.
+	Body  string       `json:"body"`
+	Files []FileChange `json:"files"`
+}
+
+// Identical reports whether the two skills have no differences.
+func (r Result) Identical() bool {
+	return len(r.Manifest) == 0 && len(r.Frontmatter) == 0 && r.Body == "" && len(r.Files) == 0
+}
+
+// Compare returns the differences from one skill to the other: manifest
+// fields, frontmatter fields, the SKILL.md body and the files under
+// scripts/, references/, assets/ and elsewhere. The maintainer and build
+// timestamp are only compared between two stored artifacts.
+func Compare(from, to Side) Result {
+	r := Result{
+		Old:         from.Label,
+		New:         to.Label,
+		Manifest:    []FieldChange{},
+		Frontmatter: []FieldChange{},
+		Files:       []FileChange{},
+	}
+
+	om, nm := from.Manifest, to.Manifest
+	manifestFields := [][3]string{
+		{"name", om.Name, nm.Name},
+		{"version", om.Version, nm.Version},
+		{"description", om.Description, nm.Description},
+		{"author", om.Author, nm.Author},
+	}
+	if !from.Source && !to.Source {
+		manifestFields = append(manifestFields,
+			[3]string{"maintainer", om.Maintainer, nm.Maintainer},
+			[3]string{"buildTimestamp", om.BuildTimestamp, nm.BuildTimestamp})
+	}
+	manifestFields = append(manifestFields, [3]string{"sourceHash", om.SourceHash, nm.SourceHash})
+	r.Manifest = changedFields(r.Manifest, manifestFields)
+
+	of, nf := from.Skill, to.Skill
+	r.Frontmatter = changedFields(r.Frontmatter, [][3]string{
+		{"name", of.Name, nf.Name},
+		{"description", of.Description, nf.Description},
+		{"license", of.License, nf.License},
+		{"compatibility", of.Compatibility, nf.Compatibility},
+		{"allowed-tools", of.AllowedTools, nf.AllowedTools},
+		{"metadata.version", of.Metadata.Version, nf.Metadata.Version},
+		{"metadata.author", of.Metadata.Author, nf.Metadata.Author},
+	})
+
+	r.Body = Unified(from.Label+"/SKILL.md", to.Label+"/SKILL.md", from.Body, to.Body)
+	r.Files = compareFiles(om.Contents.Files(), nm.Contents.Files())
+	return r
+}
+
+// changedFields appends a FieldChange to changes for each {field, old,
+// new} whose values differ.
+func changedFields(changes []FieldChange, fields [][3]string) []FieldChange {
+	for _, f := range fields {
+		if f[1] != f[2] {
+			changes = append(changes, FieldChange{Field: f[0], Old: f[1], New: f[2]})
+		}
+	}
+	return changes
+}
+
+// compareFiles returns the file changes from one list of files to the
+// other, sorted by path. A file is modified if its digest or executable
+// bit changed.
+func compareFiles(from, to []store.FileEntry) []FileChange {
+	byPath := map[string]store.FileEntry{}
+	for _, f := range from {
+		byPath[f.Path] = f
+	}
+
+	changes := []FileChange{}
+	for _, f := range to {
+		f := f
+		o, ok := byPath[f.Path]
+		delete(byPath, f.Path)
+		switch {
+		case !ok:
+			changes = append(changes, FileChange{Path: f.Path, Status: StatusAdded, New: &f})
+		case o.Digest != f.Digest || o.Executable != f.Executable:
+			changes = append(changes, FileChange{Path: f.Path, Status: StatusModified, Old: &o, New: &f})
+		}
+	}
+	for _, f := range byPath {
+		f := f
+		changes = append(changes, FileChange{Path: f.Path, Status: StatusRemoved, Old: &f})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
@@ -0,0 +1,232 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines around each hunk.
+const contextLines = 3
+
+// edit is one line of an edit script: ' ' kept, '-' deleted or '+'
+// inserted.
+type edit struct {
+	kind byte
+	line string
+}
+
+// Unified returns a unified diff of the texts a and b, labelled oldName
+// and newName, or "" if they are equal.
+func Unified(oldName, newName, a, b string) string {
+	if a == b {
+		return ""
+	}
+	edits := diffLines(splitLines(a), splitLines(b))
+
+	// Line numbers before each edit, in a and in b
+	oldNo := make([]int, len(edits)+1)
+	newNo := make([]int, len(edits)+1)
+	for i, e := range edits {
+		oldNo[i+1], newNo[i+1] = oldNo[i], newNo[i]
+		if e.kind != '+' {
+			oldNo[i+1]++
+		}
+		if e.kind != '-' {
+			newNo[i+1]++
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+	for i := 0; i < len(edits); {
+		for i < len(edits) && edits[i].kind == ' ' {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+		start := i - contextLines
+		if start < 0 {
+			start = 0
+		}
+		// Extend the hunk over changes separated by little context
+		end := i
+		for {
+			for end < len(edits) && edits[end].kind != ' ' {
+				end++
+			}
+			next := end
+			for next < len(edits) && edits[next].kind == ' ' {
+				next++
+			}
+			if next < len(edits) && next-end <= 2*contextLines {
+				end = next
+				continue
+			}
+			end += contextLines
+			if end > len(edits) {
+				end = len(edits)
+			}
+			break
+		}
+
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n",
+			hunkRange(oldNo[start], oldNo[end]-oldNo[start]),
+			hunkRange(newNo[start], newNo[end]-newNo[start]))
+		for _, e := range edits[start:end] {
+			sb.WriteByte(e.kind)
+			sb.WriteString(e.line)
+			if !strings.HasSuffix(e.line, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+	return sb.String()
+}
+
+// hunkRange formats the range of a hunk that covers count lines after
+// line start, as in GNU diff.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// splitLines splits s after each newline. A final line without a newline
+// is kept as is.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns a shortest edit script turning a into b, using the
+// linear space variant of the Myers algorithm: the middle snake of a
+// shortest path splits the problem in two, so memory grows with the input
+// rather than with the square of the edit distance. Within each run of
+// changes, deletions come before insertions.
+func diffLines(a, b []string) []edit {
+	var edits []edit
+	var walk func(a, b []string)
+	walk = func(a, b []string) {
+		// Common prefix and suffix
+		i := 0
+		for i < len(a) && i < len(b) && a[i] == b[i] {
+			edits = append(edits, edit{' ', a[i]})
+			i++
+		}
+		a, b = a[i:], b[i:]
+		j := 0
+		for j < len(a) && j < len(b) && a[len(a)-1-j] == b[len(b)-1-j] {
+			j++
+		}
+		suffix := a[len(a)-j:]
+		a, b = a[:len(a)-j], b[:len(b)-j]
+
+		switch {
+		case len(a) == 0:
+			for _, line := range b {
+				edits = append(edits, edit{'+', line})
+			}
+		case len(b) == 0:
+			for _, line := range a {
+				edits = append(edits, edit{'-', line})
+			}
+		default:
+			x, y, u, v := middleSnake(a, b)
+			walk(a[:x], b[:y])
+			for _, line := range a[x:u] {
+				edits = append(edits, edit{' ', line})
+			}
+			walk(a[u:], b[v:])
+		}
+		for _, line := range suffix {
+			edits = append(edits, edit{' ', line})
+		}
+	}
+	walk(a, b)
+
+	for i := 0; i < len(edits); {
+		if edits[i].kind == ' ' {
+			i++
+			continue
+		}
+		j := i
+		for j < len(edits) && edits[j].kind != ' ' {
+			j++
+		}
+		run := edits[i:j]
+		sort.SliceStable(run, func(p, q int) bool {
+			return run[p].kind == '-' && run[q].kind == '+'
+		})
+		i = j
+	}
+	return edits
+}
+
+// middleSnake returns the middle snake of a shortest edit script turning
+// a into b, the diagonal run of equal lines from (x, y) to (u, v) where
+// paths searched from both ends meet. a and b must not be empty.
+func middleSnake(a, b []string) (x, y, u, v int) {
+	n, m := len(a), len(b)
+	delta := n - m
+	odd := delta%2 != 0
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+	// Furthest x reached on each diagonal k = x - y from the start, and
+	// furthest distance from the end on each diagonal of the reversed
+	// texts
+	forward := make([]int, 2*maxD+3)
+	backward := make([]int, 2*maxD+3)
+
+	for d := 0; d <= maxD; d++ {
+		for k := -d; k <= d; k += 2 {
+			var px int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				px = forward[offset+k+1]
+			} else {
+				px = forward[offset+k-1] + 1
+			}
+			py := px - k
+			sx, sy := px, py
+			for px < n && py < m && a[px] == b[py] {
+				px++
+				py++
+			}
+			forward[offset+k] = px
+			if r := delta - k; odd && r >= -(d-1) && r <= d-1 && px >= n-backward[offset+r] {
+				return sx, sy, px, py
+			}
+		}
+		for k := -d; k <= d; k += 2 {
+			var p int
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				p = backward[offset+k+1]
+			} else {
+				p = backward[offset+k-1] + 1
+			}
+			q := p - k
+			sp, sq := p, q
+			for p < n && q < m && a[n-1-p] == b[m-1-q] {
+				p++
+				q++
+			}
+			backward[offset+k] = p
+			if f := delta - k; !odd && f >= -d && f <= d && forward[offset+f] >= n-p {
+				return n - p, m - q, n - sp, m - sq
+			}
+		}
+	}
+	return 0, 0, 0, 0
+}
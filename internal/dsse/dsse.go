@@ -0,0 +1,139 @@
+// Package dsse implements Dead Simple Signing Envelopes
+// (https://github.com/secure-systems-lab/dsse), the signature wrapper used
+// for in-toto attestations.
+package dsse
+
+import (
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/c8ab/provenskills/internal/signing"
+)
+
+// ErrNoValidSignature is returned by Verify when no signature in the
+// envelope verifies with the given key.
+var ErrNoValidSignature = errors.New("no valid signature for key")
+
+// Envelope is a DSSE envelope in its JSON serialization.
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is one signature over an envelope's payload. KeyID is the
+// signing.KeyID of the signing key.
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// PAE returns the DSSE pre-authentication encoding of a payload, which is
+// what signatures cover.
+func PAE(payloadType string, payload []byte) []byte {
+	buf := []byte("DSSEv1 ")
+	buf = strconv.AppendInt(buf, int64(len(payloadType)), 10)
+	buf = append(buf, ' ')
+	buf = append(buf, payloadType...)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, int64(len(payload)), 10)
+	buf = append(buf, ' ')
+	return append(buf, payload...)
+}
+
+// Sign wraps payload in an envelope signed by key.
+func Sign(key crypto.Signer, payloadType string, payload []byte) (Envelope, error) {
+	e := Envelope{
+		PayloadType: payloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+	}
+	if err := e.AddSignature(key); err != nil {
+		return Envelope{}, err
+	}
+	return e, nil
+}
+
+// AddSignature signs the envelope's payload with another key.
+func (e *Envelope) AddSignature(key crypto.Signer) error {
+	payload, err := e.DecodePayload()
+	if err != nil {
+		return err
+	}
+	keyID, err := signing.KeyID(key.Public())
+	if err != nil {
+		return err
+	}
+	sig, err := signing.SignPayload(key, PAE(e.PayloadType, payload))
+	if err != nil {
+		return fmt.Errorf("failed to sign envelope: %w", err)
+	}
+	e.Signatures = append(e.Signatures, Signature{
+		KeyID: keyID,
+		Sig:   base64.StdEncoding.EncodeToString(sig),
+	})
+	return nil
+}
+
+// DecodePayload returns the raw payload bytes.
+func (e Envelope) DecodePayload() ([]byte, error) {
+	payload, err := base64.StdEncoding.DecodeString(e.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode envelope payload: %w", err)
+	}
+	return payload, nil
+}
+
+// Verify checks that at least one signature in the envelope was made by
+// pub over its payload.
+func (e Envelope) Verify(pub crypto.PublicKey) error {
+	keyID, err := signing.KeyID(pub)
+	if err != nil {
+		return err
+	}
+	payload, err := e.DecodePayload()
+	if err != nil {
+		return err
+	}
+	pae := PAE(e.PayloadType, payload)
+	for _, s := range e.Signatures {
+		if s.KeyID != "" && s.KeyID != keyID {
+			continue
+		}
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err != nil {
+			continue
+		}
+		if signing.VerifyPayload(pub, pae, sig) == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w %s", ErrNoValidSignature, keyID)
+}
+
+// KeyIDs returns the key ids named by the envelope's signatures.
+func (e Envelope) KeyIDs() []string {
+	ids := make([]string, 0, len(e.Signatures))
+	for _, s := range e.Signatures {
+		ids = append(ids, s.KeyID)
+	}
+	return ids
+}
+
+// Parse decodes a serialized envelope.
+func Parse(data []byte) (Envelope, error) {
+	var e Envelope
+	if err := json.Unmarshal(data, &e); err != nil {
+		return Envelope{}, fmt.Errorf("failed to parse envelope: %w", err)
+	}
+	if e.PayloadType == "" || e.Payload == "" {
+		return Envelope{}, fmt.Errorf("envelope is missing payloadType or payload")
+	}
+	if len(e.Signatures) == 0 {
+		return Envelope{}, fmt.Errorf("envelope has no signatures")
+	}
+	return e, nil
+}
@@ -12,4 +12,23 @@ const (
 	ErrConflict = 3
 	// ErrIO indicates a filesystem or IO error.
 	ErrIO = 4
+	// ErrNotFound indicates the requested artifact does not exist in the
+	// registry.
+	ErrNotFound = 5
+	// ErrIntegrity indicates stored artifact content does not match its
+	// recorded digests.
+	ErrIntegrity = 6
+	// ErrUnsigned indicates a stored artifact carries no signatures.
+	ErrUnsigned = 7
+	// ErrUntrustedSigner indicates an artifact's signatures verify but none
+	// was made by a trusted key (or, when required, by its maintainer).
+	ErrUntrustedSigner = 8
+	// ErrBadSignature indicates a signature does not verify against the
+	// artifact it accompanies.
+	ErrBadSignature = 9
+	// ErrPolicyDenied indicates a skill violates the admission policy.
+	ErrPolicyDenied = 10
+	// ErrLocked indicates the store stayed locked by another process for
+	// longer than the lock timeout.
+	ErrLocked = 11
 )
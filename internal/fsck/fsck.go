@@ -0,0 +1,139 @@
+// Package fsck checks the integrity of artifacts in the local store.
+package fsck
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/c8ab/provenskills/internal/oci"
+	"github.com/c8ab/provenskills/internal/signing"
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+// Checks that can report a problem.
+const (
+	CheckManifest   = "manifest"
+	CheckIndex      = "index"
+	CheckContents   = "contents"
+	CheckSourceHash = "sourceHash"
+	CheckDigest     = "digest"
+	CheckSignature  = "signature"
+)
+
+// Problem is an integrity problem found in a stored artifact.
+type Problem struct {
+	Check   string `json:"check"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	return p.Check + ": " + p.Message
+}
+
+// Result is the outcome of checking one stored artifact.
+type Result struct {
+	Name     string    `json:"name"`
+	Version  string    `json:"version"`
+	Path     string    `json:"path"`
+	Digest   string    `json:"digest,omitempty"`
+	Problems []Problem `json:"problems"`
+}
+
+// OK reports whether the artifact has no problems.
+func (r Result) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Check checks the artifact in e of store s: that manifest.json parses
+// and describes e's name and version, that its files match index.json and
+// their blobs exist, that the files recorded in its contents, if any,
+// exist with the recorded digests, that its sourceHash recomputes, that it
+// still packs to the digest recorded when it was stored and that its
+// signatures verify against the artifact digest.
+func Check(s *store.Store, e store.Entry) Result {
+	r := Result{Name: e.Name, Version: e.Version, Path: e.Path, Problems: []Problem{}}
+	add := func(check, format string, args ...interface{}) {
+		r.Problems = append(r.Problems, Problem{Check: check, Message: fmt.Sprintf(format, args...)})
+	}
+
+	m, err := store.ReadManifest(filepath.Join(e.Path, "manifest.json"))
+	if err != nil {
+		add(CheckManifest, "%v", err)
+		return r
+	}
+	if m.Name != e.Name || m.Version != e.Version {
+		add(CheckManifest, "describes %s@%s but is stored as %s@%s", m.Name, m.Version, e.Name, e.Version)
+	}
+
+	idx, err := store.ReadIndex(e.Path)
+	switch {
+	case os.IsNotExist(err):
+		// Artifacts of version 1 stores have no index
+	case err != nil:
+		add(CheckIndex, "%v", err)
+	default:
+		for _, f := range idx.Files {
+			sum, err := store.FileDigest(filepath.Join(e.Path, filepath.FromSlash(f.Path)))
+			switch {
+			case os.IsNotExist(err):
+				add(CheckIndex, "missing: %s", f.Path)
+			case err != nil:
+				add(CheckIndex, "%v", err)
+			case sum != f.Digest:
+				add(CheckIndex, "modified: %s", f.Path)
+			}
+			if _, err := os.Stat(s.BlobPath(f.Digest)); err != nil {
+				add(CheckIndex, "missing blob %s for %s", f.Digest, f.Path)
+			}
+		}
+	}
+
+	if len(m.Contents.Files()) > 0 {
+		problems, err := store.CheckContents(e.Path, m.Contents)
+		if err != nil {
+			add(CheckContents, "%v", err)
+		}
+		for _, p := range problems {
+			add(CheckContents, "%s", p)
+		}
+	}
+
+	if m.SourceHash != "" {
+		actual, err := store.HashTree(e.Path)
+		switch {
+		case err != nil:
+			add(CheckSourceHash, "%v", err)
+		case actual != m.SourceHash:
+			add(CheckSourceHash, "recorded %s, computed %s", m.SourceHash, actual)
+		}
+	}
+
+	recorded, err := oci.RecordedDigest(e.Path)
+	if err != nil {
+		add(CheckDigest, "%v", err)
+	}
+	artifact, err := oci.Pack(e.Path)
+	if err != nil {
+		add(CheckSignature, "cannot compute artifact digest: %v", err)
+		return r
+	}
+	r.Digest = artifact.Digest()
+	if recorded != "" {
+		if recorded != r.Digest {
+			add(CheckDigest, "recorded %s, but the artifact now packs to %s", recorded, r.Digest)
+		}
+		r.Digest = recorded
+	}
+	sigs, err := signing.Load(e.Path)
+	if err != nil {
+		add(CheckSignature, "%v", err)
+		return r
+	}
+	for _, sig := range sigs {
+		if err := sig.Verify(r.Digest); err != nil {
+			add(CheckSignature, "key %s: %v", sig.KeyID, err)
+		}
+	}
+	return r
+}
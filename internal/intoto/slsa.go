@@ -0,0 +1,38 @@
+package intoto
+
+// PredicateSLSAProvenance is the predicate type of SLSA v1 provenance.
+const PredicateSLSAProvenance = "https://slsa.dev/provenance/v1"
+
+// Provenance is a SLSA v1 provenance predicate
+// (https://slsa.dev/spec/v1.0/provenance).
+type Provenance struct {
+	BuildDefinition BuildDefinition `json:"buildDefinition"`
+	RunDetails      RunDetails      `json:"runDetails"`
+}
+
+// BuildDefinition describes the inputs of a build.
+type BuildDefinition struct {
+	BuildType            string                 `json:"buildType"`
+	ExternalParameters   map[string]interface{} `json:"externalParameters"`
+	InternalParameters   map[string]interface{} `json:"internalParameters,omitempty"`
+	ResolvedDependencies []ResourceDescriptor   `json:"resolvedDependencies,omitempty"`
+}
+
+// RunDetails describes the builder and this particular run.
+type RunDetails struct {
+	Builder  Builder       `json:"builder"`
+	Metadata BuildMetadata `json:"metadata,omitempty"`
+}
+
+// Builder identifies the entity that ran the build.
+type Builder struct {
+	ID      string            `json:"id"`
+	Version map[string]string `json:"version,omitempty"`
+}
+
+// BuildMetadata records when the build ran. Timestamps are RFC 3339.
+type BuildMetadata struct {
+	InvocationID string `json:"invocationId,omitempty"`
+	StartedOn    string `json:"startedOn,omitempty"`
+	FinishedOn   string `json:"finishedOn,omitempty"`
+}
@@ -0,0 +1,86 @@
+// Package intoto defines in-toto attestation statements
+// (https://github.com/in-toto/attestation) and the SLSA provenance
+// predicate psk produces.
+package intoto
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// StatementType is the _type of an in-toto v1 statement.
+	StatementType = "https://in-toto.io/Statement/v1"
+	// PayloadType is the DSSE payload type of a serialized statement.
+	PayloadType = "application/vnd.in-toto+json"
+)
+
+// ResourceDescriptor identifies an artifact a statement is about or an
+// input a build consumed.
+type ResourceDescriptor struct {
+	Name        string                 `json:"name,omitempty"`
+	URI         string                 `json:"uri,omitempty"`
+	Digest      map[string]string      `json:"digest,omitempty"`
+	Annotations map[string]interface{} `json:"annotations,omitempty"`
+}
+
+// Statement binds a typed predicate to one or more subjects.
+type Statement struct {
+	Type          string               `json:"_type"`
+	Subject       []ResourceDescriptor `json:"subject"`
+	PredicateType string               `json:"predicateType"`
+	Predicate     json.RawMessage      `json:"predicate"`
+}
+
+// NewStatement returns a statement about subject carrying predicate, which
+// is marshaled to JSON.
+func NewStatement(subject ResourceDescriptor, predicateType string, predicate interface{}) (Statement, error) {
+	data, err := json.Marshal(predicate)
+	if err != nil {
+		return Statement{}, fmt.Errorf("failed to marshal predicate: %w", err)
+	}
+	return Statement{
+		Type:          StatementType,
+		Subject:       []ResourceDescriptor{subject},
+		PredicateType: predicateType,
+		Predicate:     data,
+	}, nil
+}
+
+// DigestSubject returns a subject descriptor for an artifact with an OCI
+// digest of the form "sha256:<hex>".
+func DigestSubject(name, digest string) ResourceDescriptor {
+	algorithm, hex, _ := strings.Cut(digest, ":")
+	return ResourceDescriptor{Name: name, Digest: map[string]string{algorithm: hex}}
+}
+
+// HasSubject reports whether the statement is about the artifact with the
+// given OCI digest.
+func (s Statement) HasSubject(digest string) bool {
+	algorithm, hex, _ := strings.Cut(digest, ":")
+	for _, subject := range s.Subject {
+		if subject.Digest[algorithm] == hex {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseStatement decodes a statement and checks its required fields.
+func ParseStatement(data []byte) (Statement, error) {
+	var s Statement
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Statement{}, fmt.Errorf("failed to parse statement: %w", err)
+	}
+	if s.Type != StatementType {
+		return Statement{}, fmt.Errorf("unsupported statement type %q", s.Type)
+	}
+	if len(s.Subject) == 0 {
+		return Statement{}, fmt.Errorf("statement has no subject")
+	}
+	if s.PredicateType == "" {
+		return Statement{}, fmt.Errorf("statement has no predicateType")
+	}
+	return s, nil
+}
@@ -0,0 +1,425 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound is returned when a manifest or blob does not exist in the
+// registry.
+var ErrNotFound = errors.New("not found in registry")
+
+// maxManifestSize bounds the size of manifests read from a registry.
+const maxManifestSize = 4 << 20
+
+// ResponseError is returned when a registry answers with an unexpected
+// HTTP status.
+type ResponseError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *ResponseError) Error() string {
+	msg := fmt.Sprintf("%s %s: unexpected status %d", e.Method, e.URL, e.StatusCode)
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+// Client talks to registries implementing the OCI distribution spec.
+type Client struct {
+	HTTPClient *http.Client
+	Username   string
+	Password   string
+
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+// NewClient creates a Client. Credentials are read from the
+// PSK_REGISTRY_USERNAME and PSK_REGISTRY_PASSWORD environment variables.
+func NewClient() *Client {
+	return &Client{
+		HTTPClient: http.DefaultClient,
+		Username:   os.Getenv("PSK_REGISTRY_USERNAME"),
+		Password:   os.Getenv("PSK_REGISTRY_PASSWORD"),
+		tokens:     map[string]string{},
+	}
+}
+
+// Push uploads the artifact's blobs and manifest to ref. The manifest is
+// tagged with ref.Tag, or stored by digest if no tag is set.
+func (c *Client) Push(ctx context.Context, ref Reference, a *Artifact) error {
+	blobs := []struct {
+		digest string
+		data   []byte
+	}{
+		{a.Manifest.Config.Digest, a.Config},
+		{a.Manifest.Layers[0].Digest, a.Layer},
+	}
+	for _, b := range blobs {
+		if err := c.PushBlob(ctx, ref, b.digest, b.data); err != nil {
+			return err
+		}
+	}
+
+	tag := ref.Tag
+	if tag == "" {
+		tag = a.Digest()
+	}
+	_, err := c.PushManifest(ctx, ref, tag, a.Manifest.MediaType, a.ManifestBytes)
+	return err
+}
+
+// Pull fetches the skill artifact at ref, verifying the digest of the
+// manifest and of every blob it references.
+func (c *Client) Pull(ctx context.Context, ref Reference) (*Artifact, error) {
+	return c.fetch(ctx, ref, CheckSkillManifest)
+}
+
+// fetch retrieves a single-layer artifact at ref. check is applied to the
+// manifest before any blob is downloaded.
+func (c *Client) fetch(ctx context.Context, ref Reference, check func(Manifest) error) (*Artifact, error) {
+	data, err := c.GetManifest(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if err := check(m); err != nil {
+		return nil, fmt.Errorf("%s: %w", ref, err)
+	}
+
+	config, err := c.GetBlob(ctx, ref, m.Config)
+	if err != nil {
+		return nil, err
+	}
+	layer, err := c.GetBlob(ctx, ref, m.Layers[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return &Artifact{
+		Manifest:      m,
+		ManifestBytes: data,
+		Config:        config,
+		Layer:         layer,
+	}, nil
+}
+
+// GetManifest fetches the raw manifest at ref. When ref carries a digest,
+// or the registry reports one, the content is checked against it.
+func (c *Client) GetManifest(ctx context.Context, ref Reference) ([]byte, error) {
+	resp, err := c.do(ctx, ref, http.MethodGet, c.url(ref, "manifests", ref.Identifier()), "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("manifest %s: %w", ref, ErrNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if len(data) > maxManifestSize {
+		return nil, fmt.Errorf("manifest %s exceeds %d bytes", ref, maxManifestSize)
+	}
+
+	digest := Digest(data)
+	for _, want := range []string{ref.Digest, resp.Header.Get("Docker-Content-Digest")} {
+		if want != "" && want != digest {
+			return nil, fmt.Errorf("manifest digest mismatch: expected %s, got %s", want, digest)
+		}
+	}
+	return data, nil
+}
+
+// GetBlob fetches the blob described by desc and verifies its size and
+// digest. Blobs larger than MaxArchiveSize are refused with ErrTooLarge
+// before they are requested.
+func (c *Client) GetBlob(ctx context.Context, ref Reference, desc Descriptor) ([]byte, error) {
+	if err := ValidateDigest(desc.Digest); err != nil {
+		return nil, err
+	}
+	if desc.Size < 0 {
+		return nil, fmt.Errorf("blob %s has invalid size %d", desc.Digest, desc.Size)
+	}
+	if desc.Size > MaxArchiveSize {
+		return nil, fmt.Errorf("blob %s is %d bytes, more than %d: %w", desc.Digest, desc.Size, MaxArchiveSize, ErrTooLarge)
+	}
+	resp, err := c.do(ctx, ref, http.MethodGet, c.url(ref, "blobs", desc.Digest), "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("blob %s: %w", desc.Digest, ErrNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, desc.Size+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", desc.Digest, err)
+	}
+	if err := VerifyBlob(desc, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// BlobExists reports whether the repository already holds the blob.
+func (c *Client) BlobExists(ctx context.Context, ref Reference, digest string) (bool, error) {
+	resp, err := c.do(ctx, ref, http.MethodHead, c.url(ref, "blobs", digest), "", nil)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, responseError(resp)
+	}
+}
+
+// PushBlob uploads data as a blob unless the repository already holds it.
+func (c *Client) PushBlob(ctx context.Context, ref Reference, digest string, data []byte) error {
+	exists, err := c.BlobExists(ctx, ref, digest)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	resp, err := c.do(ctx, ref, http.MethodPost, c.url(ref, "blobs", "uploads")+"/", "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return responseError(resp)
+	}
+
+	location, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return fmt.Errorf("invalid upload location: %w", err)
+	}
+	q := location.Query()
+	q.Set("digest", digest)
+	location.RawQuery = q.Encode()
+
+	put, err := c.do(ctx, ref, http.MethodPut, location.String(), "application/octet-stream", data)
+	if err != nil {
+		return err
+	}
+	defer put.Body.Close()
+	if put.StatusCode != http.StatusCreated {
+		return responseError(put)
+	}
+	return nil
+}
+
+// PushManifest uploads a manifest under tag (or digest) and returns its
+// digest.
+func (c *Client) PushManifest(ctx context.Context, ref Reference, tag, mediaType string, data []byte) (string, error) {
+	if _, err := c.putManifest(ctx, ref, tag, mediaType, data); err != nil {
+		return "", err
+	}
+	return Digest(data), nil
+}
+
+// putManifest uploads a manifest and returns the response headers.
+func (c *Client) putManifest(ctx context.Context, ref Reference, tag, mediaType string, data []byte) (http.Header, error) {
+	resp, err := c.do(ctx, ref, http.MethodPut, c.url(ref, "manifests", tag), mediaType, data)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return nil, responseError(resp)
+	}
+	return resp.Header, nil
+}
+
+// url builds a distribution API URL for the repository in ref.
+func (c *Client) url(ref Reference, kind, id string) string {
+	return fmt.Sprintf("%s://%s/v2/%s/%s/%s", ref.scheme(), ref.Registry, ref.Repository, kind, id)
+}
+
+// do sends a request, answering a single authentication challenge if the
+// registry asks for one.
+func (c *Client) do(ctx context.Context, ref Reference, method, rawURL, contentType string, body []byte) (*http.Response, error) {
+	send := func(auth string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		req.Header.Set("Accept", strings.Join([]string{MediaTypeImageManifest, MediaTypeImageIndex}, ", "))
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("registry request failed: %w", err)
+		}
+		return resp, nil
+	}
+
+	scope := "repository:" + ref.Repository + ":pull"
+	if method != http.MethodGet && method != http.MethodHead {
+		scope += ",push"
+	}
+
+	resp, err := send(c.cachedAuth(scope))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close()
+
+	auth, err := c.authenticate(ctx, challenge, scope)
+	if err != nil {
+		return nil, err
+	}
+	return send(auth)
+}
+
+func (c *Client) cachedAuth(scope string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tokens[scope]
+}
+
+// authenticate answers a WWW-Authenticate challenge with basic credentials
+// or a bearer token obtained from the challenge realm.
+func (c *Client) authenticate(ctx context.Context, challenge, scope string) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	var auth string
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if c.Username == "" {
+			return "", fmt.Errorf("registry requires credentials (set PSK_REGISTRY_USERNAME and PSK_REGISTRY_PASSWORD)")
+		}
+		req, _ := http.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.SetBasicAuth(c.Username, c.Password)
+		auth = req.Header.Get("Authorization")
+	case "bearer":
+		token, err := c.fetchToken(ctx, params, scope)
+		if err != nil {
+			return "", err
+		}
+		auth = "Bearer " + token
+	default:
+		return "", fmt.Errorf("unsupported registry authentication challenge %q", challenge)
+	}
+
+	c.mu.Lock()
+	c.tokens[scope] = auth
+	c.mu.Unlock()
+	return auth, nil
+}
+
+func (c *Client) fetchToken(ctx context.Context, params map[string]string, scope string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Scheme == "" {
+		return "", fmt.Errorf("invalid token realm %q", params["realm"])
+	}
+	q := realm.Query()
+	if params["service"] != "" {
+		q.Set("service", params["service"])
+	}
+	if params["scope"] != "" {
+		scope = params["scope"]
+	}
+	q.Set("scope", scope)
+	realm.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), http.NoBody)
+	if err != nil {
+		return "", err
+	}
+	if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", responseError(resp)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxManifestSize)).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", fmt.Errorf("token response did not contain a token")
+}
+
+// parseChallenge splits a WWW-Authenticate header into its scheme and
+// key="value" parameters.
+func parseChallenge(header string) (scheme string, params map[string]string) {
+	params = map[string]string{}
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	for rest != "" {
+		var key, value string
+		key, rest, _ = strings.Cut(strings.TrimLeft(rest, " ,"), "=")
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		if key != "" {
+			params[strings.ToLower(strings.TrimSpace(key))] = value
+		}
+	}
+	return scheme, params
+}
+
+func responseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return &ResponseError{
+		Method:     resp.Request.Method,
+		URL:        resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Body:       strings.TrimSpace(string(body)),
+	}
+}
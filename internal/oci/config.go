@@ -0,0 +1,93 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+// ConfigSchemaVersion is the manifestVersion of skill config blobs with
+// media type MediaTypeSkillConfig.
+const ConfigSchemaVersion = 1
+
+// EncodeConfig returns the config blob for m.
+func EncodeConfig(m store.Manifest) ([]byte, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return data, nil
+}
+
+// DecodeConfig parses a skill config blob and checks it against the
+// ConfigSchemaVersion schema.
+func DecodeConfig(data []byte) (store.Manifest, error) {
+	var m store.Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return store.Manifest{}, fmt.Errorf("failed to parse skill config: %w", err)
+	}
+	if m.ManifestVersion != ConfigSchemaVersion {
+		return store.Manifest{}, fmt.Errorf("unsupported skill config manifestVersion %d (expected %d)", m.ManifestVersion, ConfigSchemaVersion)
+	}
+
+	required := []struct{ field, value string }{
+		{"name", m.Name},
+		{"version", m.Version},
+		{"description", m.Description},
+		{"author", m.Author},
+		{"maintainer", m.Maintainer},
+		{"buildTimestamp", m.BuildTimestamp},
+	}
+	for _, r := range required {
+		if r.value == "" {
+			return store.Manifest{}, fmt.Errorf("skill config: %s: required field is missing", r.field)
+		}
+	}
+	if _, err := time.Parse(time.RFC3339, m.BuildTimestamp); err != nil {
+		return store.Manifest{}, fmt.Errorf("skill config: buildTimestamp: %q is not an RFC 3339 timestamp", m.BuildTimestamp)
+	}
+	return m, nil
+}
+
+// Annotations returns the manifest annotations describing m.
+func Annotations(m store.Manifest) map[string]string {
+	annotations := map[string]string{}
+	for key, value := range annotationFields(m) {
+		if value != "" {
+			annotations[key] = value
+		}
+	}
+	return annotations
+}
+
+func annotationFields(m store.Manifest) map[string]string {
+	return map[string]string{
+		AnnotationTitle:       m.Name,
+		AnnotationVersion:     m.Version,
+		AnnotationDescription: m.Description,
+		AnnotationAuthors:     m.Author,
+		AnnotationVendor:      m.Maintainer,
+		AnnotationCreated:     m.BuildTimestamp,
+	}
+}
+
+// ValidateArtifact checks that a is a well-formed skill artifact and
+// returns its decoded config. Manifest annotations, when present, must
+// agree with the config.
+func ValidateArtifact(a *Artifact) (store.Manifest, error) {
+	if err := CheckSkillManifest(a.Manifest); err != nil {
+		return store.Manifest{}, err
+	}
+	m, err := DecodeConfig(a.Config)
+	if err != nil {
+		return store.Manifest{}, err
+	}
+	for key, want := range annotationFields(m) {
+		if got, ok := a.Manifest.Annotations[key]; ok && got != want {
+			return store.Manifest{}, fmt.Errorf("annotation %s=%q does not match config value %q", key, got, want)
+		}
+	}
+	return m, nil
+}
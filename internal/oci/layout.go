@@ -0,0 +1,395 @@
+package oci
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// layoutVersion is the OCI image layout version written to oci-layout.
+	layoutVersion = "1.0.0"
+	// AnnotationRefName names a manifest within an image layout index.
+	AnnotationRefName = "org.opencontainers.image.ref.name"
+)
+
+// layoutFile is a single file of an OCI image layout.
+type layoutFile struct {
+	path string
+	data []byte
+}
+
+// blobPath returns the layout-relative path of a blob.
+func blobPath(digest string) string {
+	return "blobs/sha256/" + strings.TrimPrefix(digest, "sha256:")
+}
+
+// layoutBlobs returns the blobs of a, manifest last.
+func layoutBlobs(a *Artifact) []layoutFile {
+	return []layoutFile{
+		{blobPath(a.Manifest.Config.Digest), a.Config},
+		{blobPath(a.Manifest.Layers[0].Digest), a.Layer},
+		{blobPath(a.Digest()), a.ManifestBytes},
+	}
+}
+
+// indexEntry returns the index descriptor for a, named by refName.
+func indexEntry(a *Artifact, refName string) Descriptor {
+	d := a.Descriptor()
+	if refName != "" {
+		d.Annotations = map[string]string{AnnotationRefName: refName}
+	}
+	return d
+}
+
+// layoutEntries returns the index descriptors for a, named by refName, and
+// its unnamed referrers.
+func layoutEntries(a *Artifact, refName string, referrers []*Artifact) []Descriptor {
+	entries := []Descriptor{indexEntry(a, refName)}
+	for _, r := range referrers {
+		d := r.Descriptor()
+		d.Annotations = r.Manifest.Annotations
+		entries = append(entries, d)
+	}
+	return entries
+}
+
+func marshalLayoutMeta(index Index) (layout, indexJSON []byte, err error) {
+	layout, err = json.Marshal(map[string]string{"imageLayoutVersion": layoutVersion})
+	if err != nil {
+		return nil, nil, err
+	}
+	indexJSON, err = json.Marshal(index)
+	if err != nil {
+		return nil, nil, err
+	}
+	return layout, indexJSON, nil
+}
+
+// WriteLayout writes a into the OCI image layout at dir, naming it refName
+// in index.json, together with referrers of a such as its signatures. dir
+// must be empty, absent, or an existing image layout, in which case a is
+// added to its index and any entry with the same refName is replaced.
+func WriteLayout(dir string, a *Artifact, refName string, referrers []*Artifact) error {
+	index := Index{SchemaVersion: 2, MediaType: MediaTypeImageIndex}
+
+	if _, err := os.Stat(filepath.Join(dir, "oci-layout")); err == nil {
+		data, err := os.ReadFile(filepath.Join(dir, "index.json"))
+		if err != nil {
+			return fmt.Errorf("failed to read existing index.json: %w", err)
+		}
+		if err := json.Unmarshal(data, &index); err != nil {
+			return fmt.Errorf("failed to parse existing index.json: %w", err)
+		}
+	} else if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("%s is not empty and is not an OCI image layout", dir)
+	}
+
+	entries := layoutEntries(a, refName, referrers)
+	added := map[string]bool{}
+	for _, e := range entries {
+		added[e.Digest] = true
+	}
+	kept := index.Manifests[:0]
+	for _, d := range index.Manifests {
+		if added[d.Digest] || (refName != "" && d.Annotations[AnnotationRefName] == refName) {
+			continue
+		}
+		kept = append(kept, d)
+	}
+	kept = append(kept, entries...)
+	index.Manifests = kept
+
+	layout, indexJSON, err := marshalLayoutMeta(index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "blobs", "sha256"), 0o755); err != nil {
+		return fmt.Errorf("failed to create layout: %w", err)
+	}
+	// Blobs first, then the index that references them.
+	var files []layoutFile
+	for _, r := range append([]*Artifact{a}, referrers...) {
+		files = append(files, layoutBlobs(r)...)
+	}
+	files = append(files,
+		layoutFile{"oci-layout", layout},
+		layoutFile{"index.json", indexJSON},
+	)
+	for _, f := range files {
+		if err := writeFileAtomic(filepath.Join(dir, filepath.FromSlash(f.path)), f.data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.path, err)
+		}
+	}
+	return nil
+}
+
+// WriteLayoutTar writes a and its referrers as an OCI image layout
+// tarball.
+func WriteLayoutTar(w io.Writer, a *Artifact, refName string, referrers []*Artifact) error {
+	index := Index{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeImageIndex,
+		Manifests:     layoutEntries(a, refName, referrers),
+	}
+	layout, indexJSON, err := marshalLayoutMeta(index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+
+	tw := tar.NewWriter(w)
+	for _, d := range []string{"blobs/", "blobs/sha256/"} {
+		if err := tw.WriteHeader(&tar.Header{Name: d, Typeflag: tar.TypeDir, Mode: 0o755}); err != nil {
+			return err
+		}
+	}
+	files := []layoutFile{{"oci-layout", layout}, {"index.json", indexJSON}}
+	seen := map[string]bool{}
+	for _, r := range append([]*Artifact{a}, referrers...) {
+		for _, f := range layoutBlobs(r) {
+			// Referrers share the empty config blob.
+			if !seen[f.path] {
+				seen[f.path] = true
+				files = append(files, f)
+			}
+		}
+	}
+	for _, f := range files {
+		hdr := &tar.Header{Name: f.path, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(f.data))}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// writeFileAtomic writes data to path through a temp file + os.Rename.
+func writeFileAtomic(path string, data []byte) error {
+	tmp := fmt.Sprintf("%s.tmp.%d", path, os.Getpid())
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return errors.Join(err, os.Remove(tmp))
+	}
+	return nil
+}
+
+// Layout is an OCI image layout opened for reading.
+type Layout struct {
+	Index Index
+	read  func(path string) ([]byte, error)
+}
+
+// OpenLayout opens an OCI image layout directory, or a tarball of one if
+// path is a regular file.
+func OpenLayout(path string) (*Layout, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Layout{}
+	if info.IsDir() {
+		l.read = func(name string) ([]byte, error) {
+			return readFileLimited(filepath.Join(path, filepath.FromSlash(name)))
+		}
+	} else {
+		files, err := readTar(path)
+		if err != nil {
+			return nil, err
+		}
+		l.read = func(name string) ([]byte, error) {
+			data, ok := files[name]
+			if !ok {
+				return nil, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+			}
+			return data, nil
+		}
+	}
+
+	data, err := l.read("oci-layout")
+	if err != nil {
+		return nil, fmt.Errorf("not an OCI image layout: %w", err)
+	}
+	var meta struct {
+		ImageLayoutVersion string `json:"imageLayoutVersion"`
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse oci-layout: %w", err)
+	}
+	if meta.ImageLayoutVersion != layoutVersion {
+		return nil, fmt.Errorf("unsupported image layout version %q", meta.ImageLayoutVersion)
+	}
+
+	data, err = l.read("index.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index.json: %w", err)
+	}
+	if err := json.Unmarshal(data, &l.Index); err != nil {
+		return nil, fmt.Errorf("failed to parse index.json: %w", err)
+	}
+	return l, nil
+}
+
+// Blob reads the blob described by desc and verifies its size and digest.
+func (l *Layout) Blob(desc Descriptor) ([]byte, error) {
+	if err := ValidateDigest(desc.Digest); err != nil {
+		return nil, err
+	}
+	data, err := l.read(blobPath(desc.Digest))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", desc.Digest, err)
+	}
+	if err := VerifyBlob(desc, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Skills returns every skill artifact listed in the layout index, with all
+// blob digests verified. Index entries of other artifact types are skipped.
+func (l *Layout) Skills() ([]*Artifact, error) {
+	var artifacts []*Artifact
+	for _, desc := range l.Index.Manifests {
+		if desc.MediaType != MediaTypeImageManifest {
+			continue
+		}
+		if desc.ArtifactType != "" && desc.ArtifactType != ArtifactTypeSkill {
+			continue
+		}
+
+		data, err := l.Blob(desc)
+		if err != nil {
+			return nil, err
+		}
+		var m Manifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest %s: %w", desc.Digest, err)
+		}
+		if m.ArtifactType != ArtifactTypeSkill {
+			continue
+		}
+		if err := CheckSkillManifest(m); err != nil {
+			return nil, fmt.Errorf("manifest %s: %w", desc.Digest, err)
+		}
+
+		config, err := l.Blob(m.Config)
+		if err != nil {
+			return nil, err
+		}
+		layer, err := l.Blob(m.Layers[0])
+		if err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, &Artifact{
+			Manifest:      m,
+			ManifestBytes: data,
+			Config:        config,
+			Layer:         layer,
+		})
+	}
+
+	if len(artifacts) == 0 {
+		return nil, fmt.Errorf("image layout contains no skill artifacts (artifactType %s)", ArtifactTypeSkill)
+	}
+	return artifacts, nil
+}
+
+// Referrers returns the artifacts listed in the layout index whose subject
+// is the manifest with the given digest, with all blob digests verified.
+// Only single-layer referrers, such as signatures and attestations, are
+// returned.
+func (l *Layout) Referrers(subject string) ([]*Artifact, error) {
+	var referrers []*Artifact
+	for _, desc := range l.Index.Manifests {
+		if desc.MediaType != MediaTypeImageManifest || desc.ArtifactType == "" || desc.ArtifactType == ArtifactTypeSkill {
+			continue
+		}
+		data, err := l.Blob(desc)
+		if err != nil {
+			return nil, err
+		}
+		var m Manifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest %s: %w", desc.Digest, err)
+		}
+		if m.Subject == nil || m.Subject.Digest != subject || len(m.Layers) != 1 {
+			continue
+		}
+		config, err := l.Blob(m.Config)
+		if err != nil {
+			return nil, err
+		}
+		layer, err := l.Blob(m.Layers[0])
+		if err != nil {
+			return nil, err
+		}
+		referrers = append(referrers, &Artifact{
+			Manifest:      m,
+			ManifestBytes: data,
+			Config:        config,
+			Layer:         layer,
+		})
+	}
+	return referrers, nil
+}
+
+// readFileLimited reads a layout file, failing with ErrTooLarge if it is
+// larger than MaxFileSize.
+func readFileLimited(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(newLimitReader(f, MaxFileSize,
+		fmt.Errorf("%s is larger than %d bytes: %w", filepath.Base(path), MaxFileSize, ErrTooLarge)))
+}
+
+// readTar loads the regular files of a tarball into memory, keyed by
+// their slash-separated path. A file larger than MaxFileSize, or files
+// adding up to more than MaxArchiveSize, fail with ErrTooLarge.
+func readTar(path string) (map[string][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	files := map[string][]byte{}
+	var total int64
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tarball: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if hdr.Size > MaxFileSize {
+			return nil, fmt.Errorf("tarball entry %q is larger than %d bytes: %w", hdr.Name, MaxFileSize, ErrTooLarge)
+		}
+		if total += hdr.Size; total > MaxArchiveSize {
+			return nil, fmt.Errorf("tarball files add up to more than %d bytes: %w", MaxArchiveSize, ErrTooLarge)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, hdr.Size))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tarball: %w", err)
+		}
+		files[strings.TrimPrefix(hdr.Name, "./")] = data
+	}
+}
@@ -0,0 +1,48 @@
+package oci
+
+import (
+	"errors"
+	"io"
+)
+
+// Size limits for archives read from untrusted sources, so that a small
+// hostile tarball or gzip stream cannot fill memory or disk.
+const (
+	// MaxFileSize is the largest file accepted in a skill layer or an
+	// image layout tarball.
+	MaxFileSize = 64 << 20
+	// MaxArchiveSize is the largest uncompressed size of a skill layer or
+	// an image layout tarball.
+	MaxArchiveSize = 256 << 20
+)
+
+// ErrTooLarge is returned when an archive or a file in it exceeds a size
+// limit.
+var ErrTooLarge = errors.New("size limit exceeded")
+
+// limitReader reads from r and fails with err once more than n bytes have
+// been read, rather than stopping silently like io.LimitReader.
+type limitReader struct {
+	r    io.Reader
+	left int64 // bytes that may still be read, plus one
+	err  error
+}
+
+func newLimitReader(r io.Reader, n int64, err error) *limitReader {
+	return &limitReader{r: r, left: n + 1, err: err}
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.left <= 0 {
+		return 0, l.err
+	}
+	if int64(len(p)) > l.left {
+		p = p[:l.left]
+	}
+	n, err := l.r.Read(p)
+	l.left -= int64(n)
+	if l.left <= 0 {
+		return n, l.err
+	}
+	return n, err
+}
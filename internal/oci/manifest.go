@@ -0,0 +1,90 @@
+package oci
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+)
+
+// Descriptor describes the content addressed by a digest.
+type Descriptor struct {
+	MediaType    string            `json:"mediaType"`
+	Digest       string            `json:"digest"`
+	Size         int64             `json:"size"`
+	ArtifactType string            `json:"artifactType,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+}
+
+// Manifest is an OCI image manifest.
+type Manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType,omitempty"`
+	Config        Descriptor        `json:"config"`
+	Layers        []Descriptor      `json:"layers"`
+	Subject       *Descriptor       `json:"subject,omitempty"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// Index is an OCI image index.
+type Index struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType,omitempty"`
+	Manifests     []Descriptor      `json:"manifests"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+var digestRegex = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// Digest returns the sha256 digest of data in "sha256:<hex>" form.
+func Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// ValidateDigest checks that d is a well-formed sha256 digest.
+func ValidateDigest(d string) error {
+	if !digestRegex.MatchString(d) {
+		return fmt.Errorf("invalid digest %q", d)
+	}
+	return nil
+}
+
+// NewDescriptor returns a descriptor for data with the given media type.
+func NewDescriptor(mediaType string, data []byte) Descriptor {
+	return Descriptor{
+		MediaType: mediaType,
+		Digest:    Digest(data),
+		Size:      int64(len(data)),
+	}
+}
+
+// VerifyBlob checks that data matches the size and digest of desc.
+func VerifyBlob(desc Descriptor, data []byte) error {
+	if int64(len(data)) != desc.Size {
+		return fmt.Errorf("blob %s: expected %d bytes, got %d", desc.Digest, desc.Size, len(data))
+	}
+	if got := Digest(data); got != desc.Digest {
+		return fmt.Errorf("blob digest mismatch: expected %s, got %s", desc.Digest, got)
+	}
+	return nil
+}
+
+// CheckSkillManifest verifies that m describes a skill artifact: the skill
+// artifactType, a skill config blob and a single skill layer.
+func CheckSkillManifest(m Manifest) error {
+	if m.SchemaVersion != 2 || m.MediaType != MediaTypeImageManifest {
+		return fmt.Errorf("not an OCI image manifest (schemaVersion %d, mediaType %q)", m.SchemaVersion, m.MediaType)
+	}
+	if m.ArtifactType != ArtifactTypeSkill {
+		return fmt.Errorf("not a skill artifact (artifactType %q)", m.ArtifactType)
+	}
+	if m.Config.MediaType != MediaTypeSkillConfig {
+		return fmt.Errorf("not a skill artifact (config media type %q)", m.Config.MediaType)
+	}
+	if len(m.Layers) != 1 || m.Layers[0].MediaType != MediaTypeSkillLayer {
+		return fmt.Errorf("not a skill artifact: expected a single %s layer", MediaTypeSkillLayer)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+// Package oci packs stored skill artifacts as OCI manifests and transfers
+// them to and from OCI distribution registries.
+package oci
+
+// Media types and the artifact type identify psk artifacts to registries
+// and other OCI tooling. They are part of the artifact format and must not
+// change; a new format gets new values. See docs/oci-artifact.md.
+const (
+	// MediaTypeImageManifest is the OCI image manifest media type.
+	MediaTypeImageManifest = "application/vnd.oci.image.manifest.v1+json"
+	// MediaTypeImageIndex is the OCI image index media type.
+	MediaTypeImageIndex = "application/vnd.oci.image.index.v1+json"
+
+	// ArtifactTypeSkill identifies a Proven Skill Artifact manifest.
+	ArtifactTypeSkill = "application/vnd.provenskills.skill.v1"
+	// MediaTypeSkillConfig is the media type of the skill config blob,
+	// which carries the store manifest.
+	MediaTypeSkillConfig = "application/vnd.provenskills.skill.v1+json"
+	// MediaTypeSkillLayer is the media type of the gzipped tarball holding
+	// the skill files.
+	MediaTypeSkillLayer = "application/vnd.provenskills.skill.layer.v1.tar+gzip"
+
+	// ArtifactTypeSignature identifies a referrer carrying a psk signature
+	// over its subject.
+	ArtifactTypeSignature = "application/vnd.provenskills.signature.v1"
+	// MediaTypeSignature is the media type of a serialized psk signature.
+	MediaTypeSignature = "application/vnd.provenskills.signature.v1+json"
+
+	// ArtifactTypeAttestation identifies a referrer carrying an in-toto
+	// attestation about its subject.
+	ArtifactTypeAttestation = "application/vnd.in-toto+json"
+	// MediaTypeDSSEEnvelope is the media type of a DSSE envelope.
+	MediaTypeDSSEEnvelope = "application/vnd.dsse.envelope.v1+json"
+)
+
+// Annotation keys set on skill manifests. Each mirrors a field of the
+// config blob.
+const (
+	// AnnotationTitle carries the skill name.
+	AnnotationTitle = "org.opencontainers.image.title"
+	// AnnotationVersion carries the normalized skill version.
+	AnnotationVersion = "org.opencontainers.image.version"
+	// AnnotationDescription carries the skill description.
+	AnnotationDescription = "org.opencontainers.image.description"
+	// AnnotationAuthors carries the skill author from SKILL.md metadata.
+	AnnotationAuthors = "org.opencontainers.image.authors"
+	// AnnotationVendor carries the maintainer who packaged the skill.
+	AnnotationVendor = "org.opencontainers.image.vendor"
+	// AnnotationCreated carries the build timestamp.
+	AnnotationCreated = "org.opencontainers.image.created"
+)
+
+// AnnotationPredicateType is set on attestation referrers to the in-toto
+// predicate type of the attestation they carry.
+const AnnotationPredicateType = "in-toto.io/predicate-type"
@@ -0,0 +1,246 @@
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+// Artifact is a stored skill packed into OCI blobs.
+type Artifact struct {
+	Manifest      Manifest
+	ManifestBytes []byte
+	Config        []byte
+	Layer         []byte
+}
+
+// Digest returns the digest of the packed OCI manifest.
+func (a *Artifact) Digest() string {
+	return Digest(a.ManifestBytes)
+}
+
+// Descriptor returns a descriptor for the packed OCI manifest.
+func (a *Artifact) Descriptor() Descriptor {
+	d := NewDescriptor(MediaTypeImageManifest, a.ManifestBytes)
+	d.ArtifactType = a.Manifest.ArtifactType
+	return d
+}
+
+// Pack builds an OCI artifact from a stored skill directory. The config
+// blob is derived from the directory's manifest.json and the single layer
+// holds every other file.
+func Pack(dir string) (*Artifact, error) {
+	m, err := store.ReadManifest(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		return nil, err
+	}
+	return PackManifest(dir, m)
+}
+
+// PackManifest builds an OCI artifact from the skill files in dir with m
+// as its config, as Pack does once m is written to dir's manifest.json.
+func PackManifest(dir string, m store.Manifest) (*Artifact, error) {
+	config, err := EncodeConfig(m)
+	if err != nil {
+		return nil, err
+	}
+	layer, err := PackLayer(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	layerDesc := NewDescriptor(MediaTypeSkillLayer, layer)
+	layerDesc.Annotations = map[string]string{
+		AnnotationTitle: fmt.Sprintf("%s-%s.tar.gz", m.Name, m.Version),
+	}
+	manifest := Manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeImageManifest,
+		ArtifactType:  ArtifactTypeSkill,
+		Config:        NewDescriptor(MediaTypeSkillConfig, config),
+		Layers:        []Descriptor{layerDesc},
+		Annotations:   Annotations(m),
+	}
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	return &Artifact{
+		Manifest:      manifest,
+		ManifestBytes: manifestBytes,
+		Config:        config,
+		Layer:         layer,
+	}, nil
+}
+
+// PackLayer writes the skill files in dir, excluding entries reserved by
+// the store such as manifest.json and signatures, to a gzipped tarball.
+// The output depends only on file paths, contents and executable bits:
+// entries are in lexical order, owners are cleared, modes are normalized to
+// 0644/0755 and every timestamp is the Unix epoch. The gzip header carries
+// no name or modification time.
+func PackLayer(dir string) ([]byte, error) {
+	var buf bytes.Buffer
+	gz, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	gz.OS = 255 // unknown, independent of the packing host
+	tw := tar.NewWriter(gz)
+
+	// WalkDir visits entries in lexical order.
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if store.IsReserved(filepath.ToSlash(rel)) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return fmt.Errorf("unsupported file type: %s", rel)
+		}
+
+		hdr := &tar.Header{
+			Name:    filepath.ToSlash(rel),
+			ModTime: time.Unix(0, 0),
+			Format:  tar.FormatPAX,
+		}
+		switch {
+		case info.IsDir():
+			hdr.Typeflag = tar.TypeDir
+			hdr.Name += "/"
+			hdr.Mode = 0o755
+		case info.Mode().Perm()&0o111 != 0:
+			hdr.Typeflag = tar.TypeReg
+			hdr.Mode = 0o755
+			hdr.Size = info.Size()
+		default:
+			hdr.Typeflag = tar.TypeReg
+			hdr.Mode = 0o644
+			hdr.Size = info.Size()
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack skill files: %w", err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to pack skill files: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to pack skill files: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// UnpackLayer extracts a skill layer into dst. Entries must be regular
+// files or directories with relative paths that stay inside dst. A file
+// larger than MaxFileSize, or a layer larger than MaxArchiveSize once
+// uncompressed, fails with ErrTooLarge.
+func UnpackLayer(data []byte, dst string) error {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to read skill layer: %w", err)
+	}
+	defer gz.Close()
+	tooLarge := fmt.Errorf("uncompressed size exceeds %d bytes: %w", MaxArchiveSize, ErrTooLarge)
+	tr := tar.NewReader(newLimitReader(gz, MaxArchiveSize, tooLarge))
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read skill layer: %w", err)
+		}
+
+		name := filepath.FromSlash(strings.TrimSuffix(hdr.Name, "/"))
+		if name == "" || !filepath.IsLocal(name) {
+			return fmt.Errorf("skill layer contains unsafe path %q", hdr.Name)
+		}
+		if store.IsReserved(filepath.ToSlash(name)) {
+			return fmt.Errorf("skill layer contains reserved entry %q", hdr.Name)
+		}
+		path := filepath.Join(dst, name)
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if hdr.Size > MaxFileSize {
+				return fmt.Errorf("skill layer entry %q is larger than %d bytes: %w", hdr.Name, MaxFileSize, ErrTooLarge)
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return err
+			}
+			if err := writeFile(path, io.LimitReader(tr, hdr.Size), os.FileMode(hdr.Mode)); err != nil {
+				if errors.Is(err, ErrTooLarge) {
+					return fmt.Errorf("failed to read skill layer: %w", err)
+				}
+				return err
+			}
+		default:
+			return fmt.Errorf("skill layer contains unsupported entry %q", hdr.Name)
+		}
+	}
+}
+
+// writeFile creates path with the contents of r. Only the executable bit
+// of mode is honored.
+func writeFile(path string, r io.Reader, mode os.FileMode) error {
+	perm := os.FileMode(0o644)
+	if mode&0o111 != 0 {
+		perm = 0o755
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, r); err != nil {
+		return err
+	}
+	return f.Close()
+}
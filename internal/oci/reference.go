@@ -0,0 +1,87 @@
+package oci
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+var (
+	repositoryRegex = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+	tagRegex        = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]{0,127}$`)
+)
+
+// Reference identifies a manifest in a registry repository, either by tag
+// or by digest.
+type Reference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseReference parses "<registry>/<repository>[:<tag>|@<digest>]".
+// The first path component is always taken as the registry host.
+func ParseReference(s string) (Reference, error) {
+	registry, rest, ok := strings.Cut(s, "/")
+	if !ok || registry == "" || rest == "" {
+		return Reference{}, fmt.Errorf("invalid reference %q: expected <registry>/<repository>[:<tag>]", s)
+	}
+
+	ref := Reference{Registry: registry}
+	if repo, digest, ok := strings.Cut(rest, "@"); ok {
+		if err := ValidateDigest(digest); err != nil {
+			return Reference{}, fmt.Errorf("invalid reference %q: %w", s, err)
+		}
+		ref.Digest = digest
+		rest = repo
+	}
+	if i := strings.LastIndex(rest, ":"); i > strings.LastIndex(rest, "/") {
+		ref.Tag = rest[i+1:]
+		rest = rest[:i]
+		if !tagRegex.MatchString(ref.Tag) {
+			return Reference{}, fmt.Errorf("invalid reference %q: invalid tag %q", s, ref.Tag)
+		}
+	}
+	if !repositoryRegex.MatchString(rest) {
+		return Reference{}, fmt.Errorf("invalid reference %q: invalid repository name %q", s, rest)
+	}
+	ref.Repository = rest
+	return ref, nil
+}
+
+// Identifier returns the digest if set, otherwise the tag.
+func (r Reference) Identifier() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
+
+// String returns the reference in its canonical text form.
+func (r Reference) String() string {
+	s := r.Registry + "/" + r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// scheme returns "http" for loopback registries and "https" otherwise.
+func (r Reference) scheme() string {
+	host := r.Registry
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "localhost" {
+		return "http"
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return "http"
+	}
+	return "https"
+}
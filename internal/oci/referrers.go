@@ -0,0 +1,163 @@
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// MediaTypeEmptyJSON is the media type of the empty "{}" config used by
+// artifacts that carry no configuration.
+const MediaTypeEmptyJSON = "application/vnd.oci.empty.v1+json"
+
+// emptyJSON is the content of an empty config blob.
+var emptyJSON = []byte("{}")
+
+// NewReferrer builds an artifact of the given type that carries blob as its
+// single layer and refers to subject.
+func NewReferrer(subject Descriptor, artifactType, mediaType string, blob []byte, annotations map[string]string) (*Artifact, error) {
+	subject.Annotations = nil
+	m := Manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeImageManifest,
+		ArtifactType:  artifactType,
+		Config:        NewDescriptor(MediaTypeEmptyJSON, emptyJSON),
+		Layers:        []Descriptor{NewDescriptor(mediaType, blob)},
+		Subject:       &subject,
+		Annotations:   annotations,
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal referrer manifest: %w", err)
+	}
+	return &Artifact{
+		Manifest:      m,
+		ManifestBytes: data,
+		Config:        emptyJSON,
+		Layer:         blob,
+	}, nil
+}
+
+// PushReferrer uploads a referrer artifact built by NewReferrer to the
+// repository in ref. Registries without the referrers API are supported
+// through the referrers tag schema: an index tagged "sha256-<hex>" of the
+// subject digest that lists its referrers.
+func (c *Client) PushReferrer(ctx context.Context, ref Reference, a *Artifact) error {
+	for _, b := range []struct {
+		desc Descriptor
+		data []byte
+	}{{a.Manifest.Config, a.Config}, {a.Manifest.Layers[0], a.Layer}} {
+		if err := c.PushBlob(ctx, ref, b.desc.Digest, b.data); err != nil {
+			return err
+		}
+	}
+
+	header, err := c.putManifest(ctx, ref, a.Digest(), a.Manifest.MediaType, a.ManifestBytes)
+	if err != nil {
+		return err
+	}
+	if header.Get("OCI-Subject") != "" {
+		return nil
+	}
+
+	// Fall back to the referrers tag schema.
+	subject := a.Manifest.Subject.Digest
+	tagRef := ref
+	tagRef.Tag, tagRef.Digest = fallbackTag(subject), ""
+
+	index := Index{SchemaVersion: 2, MediaType: MediaTypeImageIndex}
+	data, err := c.GetManifest(ctx, tagRef)
+	switch {
+	case errors.Is(err, ErrNotFound):
+	case err != nil:
+		return err
+	default:
+		if err := json.Unmarshal(data, &index); err != nil {
+			return fmt.Errorf("failed to parse referrers index: %w", err)
+		}
+	}
+
+	desc := a.Descriptor()
+	desc.Annotations = a.Manifest.Annotations
+	for _, d := range index.Manifests {
+		if d.Digest == desc.Digest {
+			return nil
+		}
+	}
+	index.Manifests = append(index.Manifests, desc)
+	data, err = json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal referrers index: %w", err)
+	}
+	_, err = c.PushManifest(ctx, tagRef, tagRef.Tag, MediaTypeImageIndex, data)
+	return err
+}
+
+// Referrers lists the artifacts of artifactType that refer to the manifest
+// with the given digest. An empty artifactType matches every referrer.
+func (c *Client) Referrers(ctx context.Context, ref Reference, subject, artifactType string) ([]Descriptor, error) {
+	u := c.url(ref, "referrers", subject)
+	if artifactType != "" {
+		u += "?artifactType=" + url.QueryEscape(artifactType)
+	}
+	resp, err := c.do(ctx, ref, http.MethodGet, u, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var index Index
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(io.LimitReader(resp.Body, maxManifestSize)).Decode(&index); err != nil {
+			return nil, fmt.Errorf("failed to parse referrers: %w", err)
+		}
+	case http.StatusNotFound:
+		// No referrers API: read the referrers tag schema index.
+		tagRef := ref
+		tagRef.Tag, tagRef.Digest = fallbackTag(subject), ""
+		data, err := c.GetManifest(ctx, tagRef)
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &index); err != nil {
+			return nil, fmt.Errorf("failed to parse referrers index: %w", err)
+		}
+	default:
+		return nil, responseError(resp)
+	}
+
+	var descs []Descriptor
+	for _, d := range index.Manifests {
+		if artifactType == "" || d.ArtifactType == artifactType {
+			descs = append(descs, d)
+		}
+	}
+	return descs, nil
+}
+
+// PullReferrer fetches the referrer artifact described by desc and returns
+// it with its single layer verified.
+func (c *Client) PullReferrer(ctx context.Context, ref Reference, desc Descriptor) (*Artifact, error) {
+	byDigest := ref
+	byDigest.Tag, byDigest.Digest = "", desc.Digest
+	return c.fetch(ctx, byDigest, func(m Manifest) error {
+		if len(m.Layers) != 1 {
+			return fmt.Errorf("expected a single layer, got %d", len(m.Layers))
+		}
+		return nil
+	})
+}
+
+// fallbackTag returns the referrers tag schema tag for a subject digest.
+func fallbackTag(digest string) string {
+	return strings.Replace(digest, ":", "-", 1)
+}
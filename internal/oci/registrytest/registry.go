@@ -0,0 +1,272 @@
+// Package registrytest provides an in-memory OCI distribution registry for
+// tests.
+package registrytest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+type manifest struct {
+	mediaType string
+	data      []byte
+}
+
+// descriptor is the subset of an OCI descriptor the registry needs.
+type descriptor struct {
+	MediaType    string            `json:"mediaType"`
+	Digest       string            `json:"digest"`
+	Size         int64             `json:"size"`
+	ArtifactType string            `json:"artifactType,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+}
+
+// Registry is an in-memory registry served over HTTP on a loopback address.
+type Registry struct {
+	// DisableReferrersAPI makes the registry behave like one without the
+	// OCI 1.1 referrers API, so clients must use the referrers tag schema.
+	DisableReferrersAPI bool
+
+	server *httptest.Server
+
+	mu        sync.Mutex
+	blobs     map[string][]byte
+	uploads   map[string][]byte
+	manifests map[string]manifest          // keyed by digest
+	tags      map[string]map[string]string // repository -> tag -> digest
+	referrers map[string][]descriptor      // subject digest -> referrers
+	nextID    int
+}
+
+// New starts a Registry. It is shut down when Close is called.
+func New() *Registry {
+	r := &Registry{
+		blobs:     map[string][]byte{},
+		uploads:   map[string][]byte{},
+		manifests: map[string]manifest{},
+		tags:      map[string]map[string]string{},
+		referrers: map[string][]descriptor{},
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.serve))
+	return r
+}
+
+// Host returns the registry's host:port.
+func (r *Registry) Host() string {
+	return strings.TrimPrefix(r.server.URL, "http://")
+}
+
+// Close shuts down the registry.
+func (r *Registry) Close() {
+	r.server.Close()
+}
+
+// Manifest returns the manifest stored in repo under a tag or digest.
+func (r *Registry) Manifest(repo, reference string) ([]byte, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.lookup(repo, reference)
+	return m.data, ok
+}
+
+// Blob returns the blob with the given digest.
+func (r *Registry) Blob(digest string) ([]byte, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, ok := r.blobs[digest]
+	return b, ok
+}
+
+func (r *Registry) lookup(repo, reference string) (manifest, bool) {
+	if !strings.HasPrefix(reference, "sha256:") {
+		reference = r.tags[repo][reference]
+	}
+	m, ok := r.manifests[reference]
+	return m, ok
+}
+
+func (r *Registry) serve(w http.ResponseWriter, req *http.Request) {
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	if path == "" || path == req.URL.Path {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch {
+	case strings.Contains(path, "/referrers/"):
+		_, digest, _ := strings.Cut(path, "/referrers/")
+		r.serveReferrers(w, req, digest)
+	case strings.Contains(path, "/blobs/uploads/"):
+		repo, id, _ := strings.Cut(path, "/blobs/uploads/")
+		r.serveUpload(w, req, repo, id)
+	case strings.Contains(path, "/blobs/"):
+		_, digest, _ := strings.Cut(path, "/blobs/")
+		r.serveBlob(w, req, digest)
+	case strings.Contains(path, "/manifests/"):
+		repo, reference, _ := strings.Cut(path, "/manifests/")
+		r.serveManifest(w, req, repo, reference)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func (r *Registry) serveUpload(w http.ResponseWriter, req *http.Request, repo, id string) {
+	body, _ := io.ReadAll(req.Body)
+	switch req.Method {
+	case http.MethodPost:
+		r.nextID++
+		id = fmt.Sprintf("upload-%d", r.nextID)
+		r.uploads[id] = body
+		w.Header().Set("Location", "/v2/"+repo+"/blobs/uploads/"+id)
+		w.WriteHeader(http.StatusAccepted)
+	case http.MethodPatch:
+		if _, ok := r.uploads[id]; !ok {
+			http.NotFound(w, req)
+			return
+		}
+		r.uploads[id] = append(r.uploads[id], body...)
+		w.Header().Set("Location", "/v2/"+repo+"/blobs/uploads/"+id)
+		w.WriteHeader(http.StatusAccepted)
+	case http.MethodPut:
+		data, ok := r.uploads[id]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		data = append(data, body...)
+		digest := req.URL.Query().Get("digest")
+		if digest != sha256Digest(data) {
+			http.Error(w, "digest mismatch", http.StatusBadRequest)
+			return
+		}
+		delete(r.uploads, id)
+		r.blobs[digest] = data
+		w.Header().Set("Docker-Content-Digest", digest)
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (r *Registry) serveBlob(w http.ResponseWriter, req *http.Request, digest string) {
+	data, ok := r.blobs[digest]
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	switch req.Method {
+	case http.MethodHead, http.MethodGet:
+		w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+		w.Header().Set("Docker-Content-Digest", digest)
+		w.WriteHeader(http.StatusOK)
+		if req.Method == http.MethodGet {
+			w.Write(data)
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (r *Registry) serveManifest(w http.ResponseWriter, req *http.Request, repo, reference string) {
+	switch req.Method {
+	case http.MethodHead, http.MethodGet:
+		m, ok := r.lookup(repo, reference)
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", m.mediaType)
+		w.Header().Set("Content-Length", fmt.Sprint(len(m.data)))
+		w.Header().Set("Docker-Content-Digest", sha256Digest(m.data))
+		w.WriteHeader(http.StatusOK)
+		if req.Method == http.MethodGet {
+			w.Write(m.data)
+		}
+	case http.MethodPut:
+		data, _ := io.ReadAll(req.Body)
+		digest := sha256Digest(data)
+		if strings.HasPrefix(reference, "sha256:") && reference != digest {
+			http.Error(w, "digest mismatch", http.StatusBadRequest)
+			return
+		}
+		mediaType := req.Header.Get("Content-Type")
+		if _, exists := r.manifests[digest]; !exists {
+			r.recordReferrer(w, digest, mediaType, data)
+		}
+		r.manifests[digest] = manifest{mediaType: mediaType, data: data}
+		if !strings.HasPrefix(reference, "sha256:") {
+			if r.tags[repo] == nil {
+				r.tags[repo] = map[string]string{}
+			}
+			r.tags[repo][reference] = digest
+		}
+		w.Header().Set("Docker-Content-Digest", digest)
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// recordReferrer indexes a manifest that has a subject and, when the
+// referrers API is enabled, acknowledges it with the OCI-Subject header.
+func (r *Registry) recordReferrer(w http.ResponseWriter, digest, mediaType string, data []byte) {
+	var m struct {
+		ArtifactType string            `json:"artifactType"`
+		Config       descriptor        `json:"config"`
+		Subject      *descriptor       `json:"subject"`
+		Annotations  map[string]string `json:"annotations"`
+	}
+	if err := json.Unmarshal(data, &m); err != nil || m.Subject == nil {
+		return
+	}
+	artifactType := m.ArtifactType
+	if artifactType == "" {
+		artifactType = m.Config.MediaType
+	}
+	r.referrers[m.Subject.Digest] = append(r.referrers[m.Subject.Digest], descriptor{
+		MediaType:    mediaType,
+		Digest:       digest,
+		Size:         int64(len(data)),
+		ArtifactType: artifactType,
+		Annotations:  m.Annotations,
+	})
+	if !r.DisableReferrersAPI {
+		w.Header().Set("OCI-Subject", m.Subject.Digest)
+	}
+}
+
+func (r *Registry) serveReferrers(w http.ResponseWriter, req *http.Request, digest string) {
+	if r.DisableReferrersAPI || req.Method != http.MethodGet {
+		http.NotFound(w, req)
+		return
+	}
+	filter := req.URL.Query().Get("artifactType")
+	manifests := []descriptor{}
+	for _, d := range r.referrers[digest] {
+		if filter == "" || d.ArtifactType == filter {
+			manifests = append(manifests, d)
+		}
+	}
+	data, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.index.v1+json",
+		"manifests":     manifests,
+	})
+	w.Header().Set("Content-Type", "application/vnd.oci.image.index.v1+json")
+	w.Write(data)
+}
+
+func sha256Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
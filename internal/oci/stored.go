@@ -0,0 +1,80 @@
+package oci
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+// ErrModified is returned when a stored artifact no longer matches the OCI
+// manifest recorded for it.
+var ErrModified = errors.New("stored artifact was modified")
+
+// RecordManifest records a as the OCI manifest of the stored artifact in
+// dir. Its digest is then the artifact digest, whatever later versions of
+// Pack produce.
+func RecordManifest(dir string, a *Artifact) error {
+	if err := os.WriteFile(filepath.Join(dir, store.PackedManifestFile), a.ManifestBytes, 0o644); err != nil {
+		return fmt.Errorf("failed to record artifact manifest: %w", err)
+	}
+	return nil
+}
+
+// RecordedDigest returns the digest of the OCI manifest recorded for the
+// stored artifact in dir, or "" if none was recorded. It fails with
+// ErrModified if the recorded manifest no longer describes the artifact:
+// its config must be dir's manifest.json, whose sourceHash must match the
+// skill files.
+func RecordedDigest(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, store.PackedManifestFile))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	var recorded Manifest
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", store.PackedManifestFile, err)
+	}
+
+	m, err := store.ReadManifest(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		return "", err
+	}
+	config, err := EncodeConfig(m)
+	if err != nil {
+		return "", err
+	}
+	if Digest(config) != recorded.Config.Digest {
+		return "", fmt.Errorf("manifest.json is not the config %s of the recorded artifact %s: %w", recorded.Config.Digest, Digest(data), ErrModified)
+	}
+	sourceHash, err := store.HashTree(dir)
+	if err != nil {
+		return "", err
+	}
+	if sourceHash != m.SourceHash {
+		return "", fmt.Errorf("skill files no longer match the recorded artifact %s: %w", Digest(data), ErrModified)
+	}
+	return Digest(data), nil
+}
+
+// StoredDigest returns the digest of the stored artifact in dir, which
+// its signatures and attestations cover: the recorded digest while the
+// artifact matches it, so that changes to packing do not invalidate
+// signatures, and otherwise the digest of the manifest Pack computes.
+func StoredDigest(dir string) (string, error) {
+	digest, err := RecordedDigest(dir)
+	if digest != "" || (err != nil && !errors.Is(err, ErrModified)) {
+		return digest, err
+	}
+	a, err := Pack(dir)
+	if err != nil {
+		return "", err
+	}
+	return a.Digest(), nil
+}
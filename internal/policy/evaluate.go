@@ -0,0 +1,297 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/attest"
+	"github.com/c8ab/provenskills/internal/intoto"
+	"github.com/c8ab/provenskills/internal/signing"
+	"github.com/c8ab/provenskills/internal/skill"
+	"github.com/c8ab/provenskills/internal/store"
+	"github.com/c8ab/provenskills/internal/trust"
+)
+
+// Violation is a policy rule that a skill breaks.
+type Violation struct {
+	// Rule is the policy key that was violated, e.g. "licenses.allow".
+	Rule string `json:"rule"`
+	// Field is the offending manifest field, e.g. "license".
+	Field string `json:"field"`
+	// Value is the offending value, if any.
+	Value   string `json:"value,omitempty"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s [%s]", v.Field, v.Message, v.Rule)
+}
+
+// Input is what a policy is evaluated against.
+type Input struct {
+	Manifest store.Manifest
+	Skill    skill.SkillFrontmatter
+	// Signers are the identities of trusted keys with a valid signature
+	// over the artifact.
+	Signers []string
+	// Builders are the builder ids of SLSA provenance about the artifact
+	// signed by a trusted key.
+	Builders []string
+}
+
+// Gather collects the policy input for the stored skill in dir described
+// by m, whose artifact has the given digest. Signatures and provenance are
+// checked against the trust store ts.
+func Gather(dir string, m store.Manifest, digest string, ts *trust.Store) (Input, error) {
+	in := Input{Manifest: m}
+
+	data, err := os.ReadFile(filepath.Join(dir, "SKILL.md"))
+	if err != nil {
+		return Input{}, fmt.Errorf("failed to read SKILL.md: %w", err)
+	}
+	if in.Skill, err = skill.ParseFrontmatter(data); err != nil {
+		return Input{}, err
+	}
+
+	sigs, err := signing.Load(dir)
+	if err != nil {
+		return Input{}, err
+	}
+	results, err := ts.Verify(digest, sigs)
+	if err != nil {
+		return Input{}, err
+	}
+	for _, r := range results {
+		if r.Status == trust.StatusTrusted {
+			in.Signers = append(in.Signers, r.Key.Identity)
+		}
+	}
+
+	atts, err := attest.Load(dir)
+	if err != nil {
+		return Input{}, err
+	}
+	for _, a := range attest.Filter(atts, intoto.PredicateSLSAProvenance, digest) {
+		signers, err := attest.Verify(a.Envelope, ts)
+		if err != nil {
+			return Input{}, err
+		}
+		if !anyTrusted(signers) {
+			continue
+		}
+		var p intoto.Provenance
+		if err := json.Unmarshal(a.Statement.Predicate, &p); err != nil {
+			continue
+		}
+		in.Builders = append(in.Builders, p.RunDetails.Builder.ID)
+	}
+	return in, nil
+}
+
+// Evaluate returns every rule of p that in violates. An empty result means
+// the skill is admitted.
+func Evaluate(p Policy, in Input) []Violation {
+	var vs []Violation
+
+	sr := p.Signers
+	switch {
+	case (sr.Require || len(sr.Identities) > 0 || sr.RequireMaintainer) && len(in.Signers) == 0:
+		vs = append(vs, Violation{Rule: "signers.require", Field: "signatures", Message: "no valid signature by a trusted key"})
+	default:
+		if len(sr.Identities) > 0 && !anyMatch(in.Signers, sr.Identities, matchIdentity) {
+			vs = append(vs, Violation{
+				Rule: "signers.identities", Field: "signatures", Value: strings.Join(in.Signers, ", "),
+				Message: fmt.Sprintf("no trusted signer matches %s", strings.Join(sr.Identities, ", ")),
+			})
+		}
+		if sr.RequireMaintainer && !signedByMaintainer(in.Signers, in.Manifest.Maintainer) {
+			vs = append(vs, Violation{
+				Rule: "signers.require-maintainer", Field: "maintainer", Value: in.Manifest.Maintainer,
+				Message: fmt.Sprintf("no trusted signature by maintainer %q", in.Manifest.Maintainer),
+			})
+		}
+	}
+
+	pr := p.Provenance
+	switch {
+	case (pr.Require || len(pr.Builders) > 0) && len(in.Builders) == 0:
+		vs = append(vs, Violation{Rule: "provenance.require", Field: "provenance", Message: "no SLSA provenance signed by a trusted key"})
+	case len(pr.Builders) > 0 && !anyMatch(in.Builders, pr.Builders, matchPattern):
+		vs = append(vs, Violation{
+			Rule: "provenance.builders", Field: "provenance.runDetails.builder.id", Value: strings.Join(in.Builders, ", "),
+			Message: fmt.Sprintf("builder is not one of %s", strings.Join(pr.Builders, ", ")),
+		})
+	}
+
+	if !p.Licenses.empty() {
+		vs = append(vs, checkLicense(p.Licenses, in.Skill.License)...)
+	}
+
+	if !p.AllowedTools.empty() {
+		for _, tool := range SplitTools(in.Skill.AllowedTools) {
+			vs = append(vs, checkList("allowed-tools", "allowed-tools", p.AllowedTools, tool, matchPattern)...)
+		}
+	}
+
+	if !p.Maintainers.empty() {
+		vs = append(vs, checkList("maintainers", "maintainer", p.Maintainers, in.Manifest.Maintainer, matchIdentity)...)
+	}
+	return vs
+}
+
+// checkList checks value against an allow/deny rule named rule.
+func checkList(rule, field string, r ListRule, value string, match func(pattern, s string) bool) []Violation {
+	var vs []Violation
+	for _, pattern := range r.Deny {
+		if match(pattern, value) {
+			vs = append(vs, Violation{Rule: rule + ".deny", Field: field, Value: value, Message: fmt.Sprintf("%q is denied by %q", value, pattern)})
+			break
+		}
+	}
+	if len(r.Allow) > 0 && !anyMatch([]string{value}, r.Allow, match) {
+		vs = append(vs, Violation{Rule: rule + ".allow", Field: field, Value: value, Message: fmt.Sprintf("%q is not allowed", value)})
+	}
+	return vs
+}
+
+// checkLicense checks a license, which may be an SPDX expression. An
+// expression is acceptable if one of its OR alternatives has every AND
+// term allowed and none denied.
+func checkLicense(r ListRule, license string) []Violation {
+	license = strings.TrimSpace(license)
+	if license == "" {
+		if len(r.Allow) > 0 {
+			return []Violation{{Rule: "licenses.allow", Field: "license", Message: "no license declared"}}
+		}
+		return nil
+	}
+
+	var first []Violation
+	for _, alt := range splitExpression(license, " OR ") {
+		var vs []Violation
+		for _, term := range splitExpression(alt, " AND ") {
+			vs = append(vs, checkList("licenses", "license", r, term, matchLicense)...)
+		}
+		if len(vs) == 0 {
+			return nil
+		}
+		if first == nil {
+			first = vs
+		}
+	}
+	return first
+}
+
+// splitExpression splits an SPDX license expression on op, ignoring
+// parentheses.
+func splitExpression(expr, op string) []string {
+	expr = strings.NewReplacer("(", "", ")", "").Replace(expr)
+	var parts []string
+	for _, p := range strings.Split(expr, op) {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return parts
+}
+
+// SplitTools splits an allowed-tools value into tools. Tools are separated
+// by spaces or commas; spaces inside parentheses, as in "Bash(git add:*)",
+// belong to the tool.
+func SplitTools(s string) []string {
+	var tools []string
+	var cur strings.Builder
+	depth := 0
+	flush := func() {
+		if cur.Len() > 0 {
+			tools = append(tools, cur.String())
+			cur.Reset()
+		}
+	}
+	for _, r := range s {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case (r == ' ' || r == ',' || r == '\t' || r == '\n') && depth == 0:
+			flush()
+			continue
+		}
+		cur.WriteRune(r)
+	}
+	flush()
+	return tools
+}
+
+// matchPattern reports whether s matches pattern, in which "*" matches any
+// run of characters.
+func matchPattern(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
+
+// matchIdentity matches an identity such as "Name <email>" against a
+// pattern for the whole identity or for its email address.
+func matchIdentity(pattern, identity string) bool {
+	if matchPattern(pattern, identity) {
+		return true
+	}
+	if addr, err := mail.ParseAddress(identity); err == nil {
+		return matchPattern(strings.ToLower(pattern), strings.ToLower(addr.Address))
+	}
+	return false
+}
+
+// matchLicense matches SPDX license identifiers, which are case
+// insensitive.
+func matchLicense(pattern, license string) bool {
+	return matchPattern(strings.ToLower(pattern), strings.ToLower(license))
+}
+
+func anyMatch(values, patterns []string, match func(pattern, s string) bool) bool {
+	for _, v := range values {
+		for _, p := range patterns {
+			if match(p, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func signedByMaintainer(signers []string, maintainer string) bool {
+	for _, s := range signers {
+		if trust.MatchesMaintainer(s, maintainer) {
+			return true
+		}
+	}
+	return false
+}
+
+func anyTrusted(signers []attest.Signer) bool {
+	for _, s := range signers {
+		if s.Status == trust.StatusTrusted {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+// Package policy decides whether a skill may be admitted into the local
+// store. A policy is a YAML file listing the signers, provenance builders,
+// licenses, allowed-tools values and maintainers that are acceptable.
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFile is the path of a project policy, relative to the project
+// directory.
+const ProjectFile = ".psk/policy.yaml"
+
+// Policy is a parsed policy file. Empty rules accept everything.
+type Policy struct {
+	Signers      SignerRule     `yaml:"signers"`
+	Provenance   ProvenanceRule `yaml:"provenance"`
+	Licenses     ListRule       `yaml:"licenses"`
+	AllowedTools ListRule       `yaml:"allowed-tools"`
+	Maintainers  ListRule       `yaml:"maintainers"`
+
+	// Path is the file the policy was loaded from.
+	Path string `yaml:"-"`
+}
+
+// SignerRule constrains the signatures a skill must carry. Only signatures
+// by keys in the trust store count.
+type SignerRule struct {
+	// Require demands at least one trusted signature.
+	Require bool `yaml:"require"`
+	// Identities, if set, lists identity patterns one trusted signer must
+	// match, such as "Alice <alice@example.com>" or "*@example.com".
+	Identities []string `yaml:"identities"`
+	// RequireMaintainer demands a trusted signature by the maintainer.
+	RequireMaintainer bool `yaml:"require-maintainer"`
+}
+
+// ProvenanceRule constrains the SLSA provenance a skill must carry. Only
+// provenance signed by a key in the trust store counts.
+type ProvenanceRule struct {
+	// Require demands trusted provenance for the artifact.
+	Require bool `yaml:"require"`
+	// Builders, if set, lists builder id patterns the provenance must
+	// match.
+	Builders []string `yaml:"builders"`
+}
+
+// ListRule accepts values matching an Allow pattern (any value, if Allow
+// is empty) and no Deny pattern. Patterns may use "*" to match any run of
+// characters.
+type ListRule struct {
+	Allow []string `yaml:"allow"`
+	Deny  []string `yaml:"deny"`
+}
+
+func (r ListRule) empty() bool {
+	return len(r.Allow) == 0 && len(r.Deny) == 0
+}
+
+// Parse parses a policy file. Unknown keys are rejected so that a typo
+// does not silently disable a rule.
+func Parse(data []byte) (Policy, error) {
+	var p Policy
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		return Policy{}, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	return p, nil
+}
+
+// Load reads and parses the policy file at path.
+func Load(path string) (Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Policy{}, err
+	}
+	p, err := Parse(data)
+	if err != nil {
+		return Policy{}, fmt.Errorf("%s: %w", path, err)
+	}
+	p.Path = path
+	return p, nil
+}
+
+// Find returns the policy file that applies in dir: the PSK_POLICY
+// environment variable if set, otherwise the nearest .psk/policy.yaml in
+// dir or its parents, otherwise ~/.psk/policy.yaml. It returns "" when no
+// policy applies.
+func Find(dir string) string {
+	if path := os.Getenv("PSK_POLICY"); path != "" {
+		return path
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		for d := abs; ; d = filepath.Dir(d) {
+			path := filepath.Join(d, filepath.FromSlash(ProjectFile))
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+			if filepath.Dir(d) == d {
+				break
+			}
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		path := filepath.Join(home, filepath.FromSlash(ProjectFile))
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
@@ -0,0 +1,136 @@
+// Package provenance generates SLSA v1 provenance statements for skill
+// builds.
+package provenance
+
+import (
+	"context"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/c8ab/provenskills/internal/intoto"
+)
+
+// BuildType identifies the psk build process and the meaning of its
+// parameters. It is documented in docs/oci-artifact.md.
+const BuildType = "https://github.com/c8ab/provenskills/buildtypes/psk-build/v1"
+
+// Build describes a completed psk build.
+type Build struct {
+	// Name and Version identify the built skill.
+	Name    string
+	Version string
+	// Digest is the OCI manifest digest of the packaged artifact.
+	Digest string
+	// SourceDir is the skill directory as given on the command line.
+	SourceDir string
+	// SourceHash is the store.HashTree digest of SourceDir.
+	SourceHash      string
+	Maintainer      string
+	AllowOtherFiles bool
+	// SourceDateEpoch is the SOURCE_DATE_EPOCH value, if set.
+	SourceDateEpoch string
+	BuilderID       string
+	BuilderVersion  string
+	StartedOn       time.Time
+	FinishedOn      time.Time
+}
+
+// Generate returns an in-toto statement carrying SLSA provenance for b.
+// The source git commit is recorded when SourceDir is inside a git work
+// tree and git is available.
+func Generate(ctx context.Context, b Build) (intoto.Statement, error) {
+	deps := []intoto.ResourceDescriptor{{
+		Name:   "source",
+		Digest: digestMap(b.SourceHash),
+	}}
+	if src, ok := gitSource(ctx, b.SourceDir); ok {
+		deps = append(deps, src)
+	}
+
+	internal := map[string]interface{}{}
+	if b.SourceDateEpoch != "" {
+		internal["sourceDateEpoch"] = b.SourceDateEpoch
+	}
+
+	predicate := intoto.Provenance{
+		BuildDefinition: intoto.BuildDefinition{
+			BuildType: BuildType,
+			ExternalParameters: map[string]interface{}{
+				"source":          filepath.ToSlash(b.SourceDir),
+				"maintainer":      b.Maintainer,
+				"allowOtherFiles": b.AllowOtherFiles,
+			},
+			InternalParameters:   internal,
+			ResolvedDependencies: deps,
+		},
+		RunDetails: intoto.RunDetails{
+			Builder: intoto.Builder{
+				ID:      b.BuilderID,
+				Version: map[string]string{"psk": b.BuilderVersion},
+			},
+			Metadata: intoto.BuildMetadata{
+				StartedOn:  b.StartedOn.UTC().Format(time.RFC3339),
+				FinishedOn: b.FinishedOn.UTC().Format(time.RFC3339),
+			},
+		},
+	}
+
+	subject := intoto.DigestSubject(b.Name+"@"+b.Version, b.Digest)
+	return intoto.NewStatement(subject, intoto.PredicateSLSAProvenance, predicate)
+}
+
+// digestMap turns "sha256:<hex>" into an in-toto digest set.
+func digestMap(digest string) map[string]string {
+	algorithm, hex, _ := strings.Cut(digest, ":")
+	return map[string]string{algorithm: hex}
+}
+
+// gitSource describes the git commit that dir is checked out at.
+func gitSource(ctx context.Context, dir string) (intoto.ResourceDescriptor, bool) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	git := func(args ...string) (string, error) {
+		out, err := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...).Output()
+		return strings.TrimSpace(string(out)), err
+	}
+
+	commit, err := git("rev-parse", "HEAD")
+	if err != nil || commit == "" {
+		return intoto.ResourceDescriptor{}, false
+	}
+	src := intoto.ResourceDescriptor{
+		Name:        "git",
+		Digest:      map[string]string{"gitCommit": commit},
+		Annotations: map[string]interface{}{},
+	}
+	if remote, err := git("config", "--get", "remote.origin.url"); err == nil && remote != "" {
+		src.URI = "git+" + redactRemote(remote)
+	}
+	if prefix, err := git("rev-parse", "--show-prefix"); err == nil && prefix != "" {
+		src.Annotations["path"] = strings.TrimSuffix(prefix, "/")
+	}
+	// Uncommitted changes mean the commit alone does not reproduce the build.
+	if status, err := git("status", "--porcelain", "--", "."); err == nil && status != "" {
+		src.Annotations["dirty"] = true
+	}
+	if len(src.Annotations) == 0 {
+		src.Annotations = nil
+	}
+	return src, true
+}
+
+// redactRemote removes the user info, which often holds an access token,
+// from a git remote URL so that it is not signed into the provenance.
+// Remotes that are not URLs, such as scp-like git@host:path, are returned
+// as they are.
+func redactRemote(remote string) string {
+	u, err := url.Parse(remote)
+	if err != nil || u.Scheme == "" || u.User == nil {
+		return remote
+	}
+	u.User = nil
+	return u.String()
+}
@@ -0,0 +1,140 @@
+package sbom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+type cdxBOM struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type        string        `json:"type"`
+	BOMRef      string        `json:"bom-ref,omitempty"`
+	Name        string        `json:"name"`
+	Version     string        `json:"version,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Author      string        `json:"author,omitempty"`
+	Supplier    *cdxSupplier  `json:"supplier,omitempty"`
+	PURL        string        `json:"purl,omitempty"`
+	Hashes      []cdxHash     `json:"hashes,omitempty"`
+	Properties  []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxSupplier struct {
+	Name string `json:"name"`
+}
+
+type cdxHash struct {
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// generateCycloneDX renders inv as a CycloneDX 1.5 JSON BOM. The skill is
+// the metadata component; its files and declared packages are components.
+func generateCycloneDX(inv Inventory) ([]byte, error) {
+	skill := cdxComponent{
+		Type:        "application",
+		BOMRef:      skillPURL(inv),
+		Name:        inv.Name,
+		Version:     inv.Version,
+		Description: inv.Description,
+		Author:      inv.Author,
+		PURL:        skillPURL(inv),
+	}
+	if inv.Maintainer != "" {
+		skill.Supplier = &cdxSupplier{Name: inv.Maintainer}
+	}
+
+	bom := cdxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: serialNumber(inv.Digest),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: inv.Created,
+			Tools: cdxTools{Components: []cdxComponent{
+				{Type: "application", Name: "psk", Version: inv.ToolVersion},
+			}},
+			Component: skill,
+		},
+		Components: []cdxComponent{},
+	}
+
+	for _, f := range inv.Files {
+		bom.Components = append(bom.Components, cdxComponent{
+			Type:   "file",
+			BOMRef: "file:" + f.Path,
+			Name:   f.Path,
+			Hashes: []cdxHash{
+				{Algorithm: "SHA-1", Content: f.SHA1},
+				{Algorithm: "SHA-256", Content: f.SHA256},
+			},
+		})
+	}
+
+	dependsOn := []string{}
+	for _, p := range inv.Packages {
+		ref := p.DeclaredIn + "#" + p.Name
+		c := cdxComponent{
+			Type:       "library",
+			BOMRef:     ref,
+			Name:       p.Name,
+			Version:    p.Version,
+			PURL:       p.PURL,
+			Properties: []cdxProperty{{Name: "psk:declared-in", Value: p.DeclaredIn}},
+		}
+		if p.Requirement != "" {
+			c.Properties = append(c.Properties, cdxProperty{Name: "psk:requirement", Value: p.Requirement})
+		}
+		bom.Components = append(bom.Components, c)
+		dependsOn = append(dependsOn, ref)
+	}
+	bom.Dependencies = []cdxDependency{{Ref: skill.BOMRef, DependsOn: dependsOn}}
+
+	data, err := json.MarshalIndent(bom, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal CycloneDX BOM: %w", err)
+	}
+	return data, nil
+}
+
+// serialNumber derives a stable RFC 4122 URN for the BOM of an artifact
+// digest, so rebuilding the SBOM yields the same document.
+func serialNumber(digest string) string {
+	sum := sha256.Sum256([]byte("psk-sbom:" + digest))
+	b := sum[:16]
+	b[6] = (b[6] & 0x0f) | 0x50
+	b[8] = (b[8] & 0x3f) | 0x80
+	h := hex.EncodeToString(b)
+	return fmt.Sprintf("urn:uuid:%s-%s-%s-%s-%s", h[0:8], h[8:12], h[12:16], h[16:20], h[20:32])
+}
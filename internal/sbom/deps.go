@@ -0,0 +1,144 @@
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// requirementRegex splits a PEP 508 requirement into name, extras and
+	// the rest.
+	requirementRegex = regexp.MustCompile(`^([A-Za-z0-9](?:[A-Za-z0-9._-]*[A-Za-z0-9])?)\s*(\[[^\]]*\])?\s*(.*)$`)
+	pinnedPyRegex    = regexp.MustCompile(`^===?\s*([A-Za-z0-9][A-Za-z0-9.+!_-]*)$`)
+	pyNameRunRegex   = regexp.MustCompile(`[-_.]+`)
+	exactSemverRegex = regexp.MustCompile(`^v?=?\s*(\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?)$`)
+)
+
+// dependencyParser returns the parser for a dependency file name, or nil
+// if the file does not declare dependencies.
+func dependencyParser(name string) func(data []byte, source string) ([]Package, error) {
+	switch {
+	case name == "package.json":
+		return parsePackageJSON
+	case strings.HasPrefix(name, "requirements") && strings.HasSuffix(name, ".txt"):
+		return parseRequirements
+	default:
+		return nil
+	}
+}
+
+// parseRequirements parses a pip requirements file. Options such as -r and
+// --hash, editable installs and bare URLs are skipped.
+func parseRequirements(data []byte, source string) ([]Package, error) {
+	var pkgs []Package
+	var logical strings.Builder
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.HasSuffix(line, `\`) {
+			logical.WriteString(strings.TrimSuffix(line, `\`) + " ")
+			continue
+		}
+		logical.WriteString(line)
+		req := logical.String()
+		logical.Reset()
+
+		if i := strings.Index(req, " #"); i >= 0 {
+			req = req[:i]
+		}
+		req = strings.TrimSpace(req)
+		if req == "" || strings.HasPrefix(req, "#") || strings.HasPrefix(req, "-") {
+			continue
+		}
+		// Per-requirement options such as --hash follow the requirement.
+		if i := strings.Index(req, " --"); i >= 0 {
+			req = strings.TrimSpace(req[:i])
+		}
+		if spec, _, ok := strings.Cut(req, ";"); ok {
+			req = strings.TrimSpace(spec)
+		}
+
+		m := requirementRegex.FindStringSubmatch(req)
+		if m == nil {
+			continue
+		}
+		p := Package{
+			Ecosystem:   "pypi",
+			Name:        strings.ToLower(pyNameRunRegex.ReplaceAllString(m[1], "-")),
+			Requirement: strings.TrimSpace(m[3]),
+			DeclaredIn:  source,
+		}
+		if v := pinnedPyRegex.FindStringSubmatch(p.Requirement); v != nil {
+			p.Version = v[1]
+		}
+		p.PURL = purl("pypi", p.Name, p.Version)
+		pkgs = append(pkgs, p)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return pkgs, nil
+}
+
+// parsePackageJSON parses the dependencies and optionalDependencies of an
+// npm package.json. Development dependencies are not shipped and are
+// skipped.
+func parsePackageJSON(data []byte, source string) ([]Package, error) {
+	var pj struct {
+		Dependencies         map[string]string `json:"dependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+	}
+	if err := json.Unmarshal(data, &pj); err != nil {
+		return nil, fmt.Errorf("failed to parse package.json: %w", err)
+	}
+
+	deps := map[string]string{}
+	for name, spec := range pj.OptionalDependencies {
+		deps[name] = spec
+	}
+	for name, spec := range pj.Dependencies {
+		deps[name] = spec
+	}
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pkgs := make([]Package, 0, len(names))
+	for _, name := range names {
+		p := Package{
+			Ecosystem:   "npm",
+			Name:        name,
+			Requirement: strings.TrimSpace(deps[name]),
+			DeclaredIn:  source,
+		}
+		if v := exactSemverRegex.FindStringSubmatch(p.Requirement); v != nil {
+			p.Version = v[1]
+		}
+		p.PURL = purl("npm", p.Name, p.Version)
+		pkgs = append(pkgs, p)
+	}
+	return pkgs, nil
+}
+
+// purl returns the package URL of a package. npm scopes become the purl
+// namespace.
+func purl(ecosystem, name, version string) string {
+	s := "pkg:" + ecosystem + "/"
+	if scope, rest, ok := strings.Cut(name, "/"); ok && strings.HasPrefix(scope, "@") {
+		s += "%40" + url.PathEscape(scope[1:]) + "/" + url.PathEscape(rest)
+	} else {
+		s += url.PathEscape(name)
+	}
+	if version != "" {
+		s += "@" + url.PathEscape(version)
+	}
+	return s
+}
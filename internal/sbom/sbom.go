@@ -0,0 +1,181 @@
+// Package sbom generates software bills of materials for stored skills in
+// the SPDX 2.3 and CycloneDX 1.5 JSON formats. An SBOM lists every file of
+// the skill and the third-party packages its scripts declare in
+// requirements.txt and package.json files.
+package sbom
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+// SBOM formats.
+const (
+	FormatSPDX      = "spdx"
+	FormatCycloneDX = "cyclonedx"
+)
+
+// Formats lists the supported SBOM formats.
+var Formats = []string{FormatSPDX, FormatCycloneDX}
+
+// In-toto predicate types of SBOM attestations.
+const (
+	PredicateSPDX      = "https://spdx.dev/Document/v2.3"
+	PredicateCycloneDX = "https://cyclonedx.org/bom/v1.5"
+)
+
+// PredicateType returns the in-toto predicate type of an SBOM format.
+func PredicateType(format string) (string, error) {
+	switch format {
+	case FormatSPDX:
+		return PredicateSPDX, nil
+	case FormatCycloneDX:
+		return PredicateCycloneDX, nil
+	default:
+		return "", fmt.Errorf("unknown SBOM format %q (expected %s)", format, strings.Join(Formats, " or "))
+	}
+}
+
+// File is a file of the skill.
+type File struct {
+	Path   string
+	Size   int64
+	SHA1   string
+	SHA256 string
+}
+
+// Package is a third-party package declared by a dependency file in
+// scripts/.
+type Package struct {
+	// Ecosystem is "pypi" or "npm".
+	Ecosystem string
+	Name      string
+	// Version is set when the declaration pins an exact version.
+	Version string
+	// Requirement is the declared version constraint, e.g. ">=2.0".
+	Requirement string
+	// DeclaredIn is the path of the dependency file.
+	DeclaredIn string
+	PURL       string
+}
+
+// Inventory is everything an SBOM records about a stored skill.
+type Inventory struct {
+	Name        string
+	Version     string
+	Description string
+	Author      string
+	Maintainer  string
+	// Created is the build timestamp, so rebuilt SBOMs are reproducible.
+	Created string
+	// Digest is the OCI manifest digest of the skill artifact.
+	Digest      string
+	ToolVersion string
+	Files       []File
+	Packages    []Package
+}
+
+// Scan inventories the stored skill in dir described by m, whose artifact
+// has the given digest. Every file recorded in m.Contents is hashed from
+// disk, and dependency files under scripts/ are parsed for packages.
+func Scan(dir string, m store.Manifest, digest string) (Inventory, error) {
+	inv := Inventory{
+		Name:        m.Name,
+		Version:     m.Version,
+		Description: m.Description,
+		Author:      m.Author,
+		Maintainer:  m.Maintainer,
+		Created:     m.BuildTimestamp,
+		Digest:      digest,
+	}
+
+	paths := []string{"SKILL.md"}
+	for _, f := range m.Contents.Files() {
+		paths = append(paths, f.Path)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		f, err := hashFile(dir, p)
+		if err != nil {
+			return Inventory{}, err
+		}
+		inv.Files = append(inv.Files, f)
+	}
+
+	for _, f := range m.Contents.Scripts {
+		parse := dependencyParser(path.Base(f.Path))
+		if parse == nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f.Path)))
+		if err != nil {
+			return Inventory{}, fmt.Errorf("failed to read %s: %w", f.Path, err)
+		}
+		pkgs, err := parse(data, f.Path)
+		if err != nil {
+			return Inventory{}, fmt.Errorf("%s: %w", f.Path, err)
+		}
+		inv.Packages = append(inv.Packages, pkgs...)
+	}
+	sort.SliceStable(inv.Packages, func(i, j int) bool {
+		a, b := inv.Packages[i], inv.Packages[j]
+		if a.Ecosystem != b.Ecosystem {
+			return a.Ecosystem < b.Ecosystem
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.DeclaredIn < b.DeclaredIn
+	})
+	return inv, nil
+}
+
+// Generate renders inv as an SBOM document in the given format.
+func Generate(inv Inventory, format string) ([]byte, error) {
+	switch format {
+	case FormatSPDX:
+		return generateSPDX(inv)
+	case FormatCycloneDX:
+		return generateCycloneDX(inv)
+	default:
+		_, err := PredicateType(format)
+		return nil, err
+	}
+}
+
+// hashFile returns the SHA-1 and SHA-256 digests of the file at the
+// slash-separated path rel inside dir.
+func hashFile(dir, rel string) (File, error) {
+	f, err := os.Open(filepath.Join(dir, filepath.FromSlash(rel)))
+	if err != nil {
+		return File{}, fmt.Errorf("failed to read %s: %w", rel, err)
+	}
+	defer f.Close()
+
+	h1, h256 := sha1.New(), sha256.New()
+	n, err := io.Copy(io.MultiWriter(h1, h256), f)
+	if err != nil {
+		return File{}, fmt.Errorf("failed to read %s: %w", rel, err)
+	}
+	return File{
+		Path:   rel,
+		Size:   n,
+		SHA1:   hex.EncodeToString(h1.Sum(nil)),
+		SHA256: hex.EncodeToString(h256.Sum(nil)),
+	}, nil
+}
+
+// skillPURL returns the package URL of the skill artifact.
+func skillPURL(inv Inventory) string {
+	return "pkg:oci/" + inv.Name + "@" + strings.Replace(inv.Digest, ":", "%3A", 1)
+}
@@ -0,0 +1,183 @@
+package sbom
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"sort"
+	"strings"
+)
+
+const noAssertion = "NOASSERTION"
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Files             []spdxFile         `json:"files"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name                    string                  `json:"name"`
+	SPDXID                  string                  `json:"SPDXID"`
+	VersionInfo             string                  `json:"versionInfo,omitempty"`
+	Supplier                string                  `json:"supplier,omitempty"`
+	Originator              string                  `json:"originator,omitempty"`
+	DownloadLocation        string                  `json:"downloadLocation"`
+	FilesAnalyzed           bool                    `json:"filesAnalyzed"`
+	PackageVerificationCode *spdxVerificationCode   `json:"packageVerificationCode,omitempty"`
+	LicenseConcluded        string                  `json:"licenseConcluded"`
+	LicenseDeclared         string                  `json:"licenseDeclared"`
+	CopyrightText           string                  `json:"copyrightText"`
+	Description             string                  `json:"description,omitempty"`
+	Comment                 string                  `json:"comment,omitempty"`
+	ExternalRefs            []spdxExternalReference `json:"externalRefs,omitempty"`
+}
+
+type spdxVerificationCode struct {
+	Value string `json:"packageVerificationCodeValue"`
+}
+
+type spdxExternalReference struct {
+	Category string `json:"referenceCategory"`
+	Type     string `json:"referenceType"`
+	Locator  string `json:"referenceLocator"`
+}
+
+type spdxFile struct {
+	FileName         string         `json:"fileName"`
+	SPDXID           string         `json:"SPDXID"`
+	Checksums        []spdxChecksum `json:"checksums"`
+	LicenseConcluded string         `json:"licenseConcluded"`
+	CopyrightText    string         `json:"copyrightText"`
+}
+
+type spdxChecksum struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"checksumValue"`
+}
+
+type spdxRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+// generateSPDX renders inv as an SPDX 2.3 JSON document. The skill is the
+// described package; it contains its files and depends on the declared
+// packages.
+func generateSPDX(inv Inventory) ([]byte, error) {
+	const skillID = "SPDXRef-Skill"
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              inv.Name + "@" + inv.Version,
+		DocumentNamespace: fmt.Sprintf("https://github.com/c8ab/provenskills/spdx/%s/%s/%s", inv.Name, inv.Version, strings.TrimPrefix(inv.Digest, "sha256:")),
+		CreationInfo: spdxCreationInfo{
+			Created:  inv.Created,
+			Creators: []string{"Tool: psk-" + inv.ToolVersion},
+		},
+		Files: []spdxFile{},
+		Relationships: []spdxRelationship{
+			{Element: "SPDXRef-DOCUMENT", Type: "DESCRIBES", Related: skillID},
+		},
+	}
+
+	doc.Packages = append(doc.Packages, spdxPackage{
+		Name:                    inv.Name,
+		SPDXID:                  skillID,
+		VersionInfo:             inv.Version,
+		Supplier:                spdxActor(inv.Maintainer),
+		Originator:              spdxActor(inv.Author),
+		DownloadLocation:        noAssertion,
+		FilesAnalyzed:           true,
+		PackageVerificationCode: &spdxVerificationCode{Value: verificationCode(inv.Files)},
+		LicenseConcluded:        noAssertion,
+		LicenseDeclared:         noAssertion,
+		CopyrightText:           noAssertion,
+		Description:             inv.Description,
+		ExternalRefs: []spdxExternalReference{
+			{Category: "PACKAGE-MANAGER", Type: "purl", Locator: skillPURL(inv)},
+		},
+	})
+
+	for i, f := range inv.Files {
+		id := fmt.Sprintf("SPDXRef-File-%d", i+1)
+		doc.Files = append(doc.Files, spdxFile{
+			FileName: "./" + f.Path,
+			SPDXID:   id,
+			Checksums: []spdxChecksum{
+				{Algorithm: "SHA1", Value: f.SHA1},
+				{Algorithm: "SHA256", Value: f.SHA256},
+			},
+			LicenseConcluded: noAssertion,
+			CopyrightText:    noAssertion,
+		})
+		doc.Relationships = append(doc.Relationships, spdxRelationship{Element: skillID, Type: "CONTAINS", Related: id})
+	}
+
+	for i, p := range inv.Packages {
+		id := fmt.Sprintf("SPDXRef-Package-%d", i+1)
+		comment := "declared in " + p.DeclaredIn
+		if p.Requirement != "" {
+			comment += " as " + p.Requirement
+		}
+		doc.Packages = append(doc.Packages, spdxPackage{
+			Name:             p.Name,
+			SPDXID:           id,
+			VersionInfo:      p.Version,
+			DownloadLocation: noAssertion,
+			LicenseConcluded: noAssertion,
+			LicenseDeclared:  noAssertion,
+			CopyrightText:    noAssertion,
+			Comment:          comment,
+			ExternalRefs: []spdxExternalReference{
+				{Category: "PACKAGE-MANAGER", Type: "purl", Locator: p.PURL},
+			},
+		})
+		doc.Relationships = append(doc.Relationships, spdxRelationship{Element: skillID, Type: "DEPENDS_ON", Related: id})
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SPDX document: %w", err)
+	}
+	return data, nil
+}
+
+// spdxActor formats an identity such as "Name <email>" as an SPDX person.
+func spdxActor(identity string) string {
+	identity = strings.TrimSpace(identity)
+	if identity == "" {
+		return ""
+	}
+	if addr, err := mail.ParseAddress(identity); err == nil {
+		return fmt.Sprintf("Person: %s (%s)", addr.Name, addr.Address)
+	}
+	return "Person: " + identity
+}
+
+// verificationCode computes the SPDX package verification code: the SHA-1
+// of the sorted SHA-1 digests of every file.
+func verificationCode(files []File) string {
+	sums := make([]string, 0, len(files))
+	for _, f := range files {
+		sums = append(sums, f.SHA1)
+	}
+	sort.Strings(sums)
+	sum := sha1.Sum([]byte(strings.Join(sums, "")))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,166 @@
+// Package signing creates and verifies detached signatures over skill
+// artifacts using ECDSA P-256 or Ed25519 keys.
+package signing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+)
+
+// Supported key algorithms.
+const (
+	AlgorithmECDSAP256 = "ecdsa-p256"
+	AlgorithmEd25519   = "ed25519"
+)
+
+// GenerateKey creates a new private key for the given algorithm.
+func GenerateKey(algorithm string) (crypto.Signer, error) {
+	switch algorithm {
+	case AlgorithmECDSAP256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgorithmEd25519:
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		return priv, err
+	default:
+		return nil, fmt.Errorf("unsupported key algorithm %q (supported: %s, %s)", algorithm, AlgorithmECDSAP256, AlgorithmEd25519)
+	}
+}
+
+// Algorithm returns the algorithm name of a public key.
+func Algorithm(pub crypto.PublicKey) (string, error) {
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return "", fmt.Errorf("unsupported ECDSA curve %s", k.Curve.Params().Name)
+		}
+		return AlgorithmECDSAP256, nil
+	case ed25519.PublicKey:
+		return AlgorithmEd25519, nil
+	default:
+		return "", fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
+
+// MarshalPrivateKey encodes a private key as a PKCS #8 PEM block.
+func MarshalPrivateKey(key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// MarshalPublicKey encodes a public key as a PKIX PEM block, the format
+// expected by "cosign verify --key".
+func MarshalPublicKey(pub crypto.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal public key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// ParsePrivateKey decodes a PKCS #8 PEM private key.
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("expected a PEM \"PRIVATE KEY\" block")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	if _, err := Algorithm(signer.Public()); err != nil {
+		return nil, err
+	}
+	return signer, nil
+}
+
+// ParsePublicKey decodes a PKIX PEM public key.
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("expected a PEM \"PUBLIC KEY\" block")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	if _, err := Algorithm(pub); err != nil {
+		return nil, err
+	}
+	return pub, nil
+}
+
+// LoadPrivateKey reads a PEM private key file.
+func LoadPrivateKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePrivateKey(data)
+}
+
+// LoadPublicKey reads a PEM public key file.
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePublicKey(data)
+}
+
+// KeyID returns "sha256:<hex>" of the public key's PKIX DER encoding.
+func KeyID(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// SignPayload signs payload. ECDSA keys sign its SHA-256 digest with an
+// ASN.1 signature; Ed25519 keys sign the payload itself.
+func SignPayload(key crypto.Signer, payload []byte) ([]byte, error) {
+	switch key.Public().(type) {
+	case *ecdsa.PublicKey:
+		sum := sha256.Sum256(payload)
+		return key.Sign(rand.Reader, sum[:], crypto.SHA256)
+	case ed25519.PublicKey:
+		return key.Sign(rand.Reader, payload, crypto.Hash(0))
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key.Public())
+	}
+}
+
+// VerifyPayload checks a signature made by SignPayload.
+func VerifyPayload(pub crypto.PublicKey, payload, sig []byte) error {
+	ok := false
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		sum := sha256.Sum256(payload)
+		ok = ecdsa.VerifyASN1(k, sum[:], sig)
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(k, payload, sig)
+	default:
+		return fmt.Errorf("unsupported public key type %T", pub)
+	}
+	if !ok {
+		return fmt.Errorf("signature does not verify")
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package signing
+
+import (
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SignaturesDir is the directory inside a stored artifact that holds its
+// detached signatures, one JSON file per signing key.
+const SignaturesDir = "signatures"
+
+// Signature is a detached signature over the digest of a skill artifact's
+// OCI manifest. The signed payload is the digest string itself, e.g.
+// "sha256:3b1f...".
+type Signature struct {
+	Digest    string `json:"digest"`
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"keyId"`
+	PublicKey string `json:"publicKey"`
+	Signature string `json:"signature"`
+}
+
+// Sign signs an artifact manifest digest with key.
+func Sign(key crypto.Signer, digest string) (Signature, error) {
+	pub := key.Public()
+	algorithm, err := Algorithm(pub)
+	if err != nil {
+		return Signature{}, err
+	}
+	keyID, err := KeyID(pub)
+	if err != nil {
+		return Signature{}, err
+	}
+	pubPEM, err := MarshalPublicKey(pub)
+	if err != nil {
+		return Signature{}, err
+	}
+	sig, err := SignPayload(key, []byte(digest))
+	if err != nil {
+		return Signature{}, fmt.Errorf("failed to sign: %w", err)
+	}
+	return Signature{
+		Digest:    digest,
+		Algorithm: algorithm,
+		KeyID:     keyID,
+		PublicKey: string(pubPEM),
+		Signature: base64.StdEncoding.EncodeToString(sig),
+	}, nil
+}
+
+// Verify checks s against its embedded public key and the expected
+// artifact digest. It proves integrity only; whether the key is trusted is
+// a separate decision.
+func (s Signature) Verify(digest string) error {
+	if s.Digest != digest {
+		return fmt.Errorf("signature is for %s, artifact is %s", s.Digest, digest)
+	}
+	pub, err := ParsePublicKey([]byte(s.PublicKey))
+	if err != nil {
+		return err
+	}
+	if keyID, err := KeyID(pub); err != nil || keyID != s.KeyID {
+		return fmt.Errorf("signature keyId does not match its public key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(s.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to decode signature: %w", err)
+	}
+	return VerifyPayload(pub, []byte(digest), sig)
+}
+
+// fileName returns the signature file name for a key id.
+func fileName(keyID string) string {
+	hex := strings.TrimPrefix(keyID, "sha256:")
+	if len(hex) > 16 {
+		hex = hex[:16]
+	}
+	return hex + ".json"
+}
+
+// Save writes s into the signatures directory of a stored artifact,
+// replacing any earlier signature by the same key. It returns the path of
+// the signature file.
+func Save(artifactDir string, s Signature) (string, error) {
+	dir := filepath.Join(artifactDir, SignaturesDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create signatures directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal signature: %w", err)
+	}
+	data = append(data, '\n')
+
+	path := filepath.Join(dir, fileName(s.KeyID))
+	tmp := fmt.Sprintf("%s.tmp.%d", path, os.Getpid())
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write signature: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to write signature: %w", err)
+	}
+	return path, nil
+}
+
+// Load returns every signature stored with an artifact, ordered by key id.
+// An artifact without a signatures directory has no signatures.
+func Load(artifactDir string) ([]Signature, error) {
+	dir := filepath.Join(artifactDir, SignaturesDir)
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signatures: %w", err)
+	}
+
+	var sigs []Signature
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signature: %w", err)
+		}
+		s, err := Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		sigs = append(sigs, s)
+	}
+	sort.Slice(sigs, func(i, j int) bool {
+		return sigs[i].KeyID < sigs[j].KeyID
+	})
+	return sigs, nil
+}
+
+// Parse decodes a serialized Signature.
+func Parse(data []byte) (Signature, error) {
+	var s Signature
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Signature{}, fmt.Errorf("failed to parse signature: %w", err)
+	}
+	if s.Digest == "" || s.KeyID == "" || s.PublicKey == "" || s.Signature == "" {
+		return Signature{}, fmt.Errorf("signature is missing required fields")
+	}
+	return s, nil
+}
@@ -4,24 +4,26 @@ package skill
 import (
 	"fmt"
 	"strings"
+	"unicode"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Metadata holds the metadata block from SKILL.md frontmatter.
 type Metadata struct {
-	Version string `yaml:"version"`
-	Author  string `yaml:"author"`
+	Version string `yaml:"version" json:"version"`
+	Author  string `yaml:"author" json:"author"`
 }
 
 // SkillFrontmatter represents the parsed YAML frontmatter of a SKILL.md file.
+// Its JSON form uses the same keys as the YAML.
 type SkillFrontmatter struct {
-	Name          string   `yaml:"name"`
-	Description   string   `yaml:"description"`
-	License       string   `yaml:"license"`
-	Compatibility string   `yaml:"compatibility"`
-	Metadata      Metadata `yaml:"metadata"`
-	AllowedTools  string   `yaml:"allowed-tools"`
+	Name          string   `yaml:"name" json:"name"`
+	Description   string   `yaml:"description" json:"description"`
+	License       string   `yaml:"license" json:"license,omitempty"`
+	Compatibility string   `yaml:"compatibility" json:"compatibility,omitempty"`
+	Metadata      Metadata `yaml:"metadata" json:"metadata"`
+	AllowedTools  string   `yaml:"allowed-tools" json:"allowed-tools,omitempty"`
 }
 
 // ParseFrontmatter extracts and parses YAML frontmatter from SKILL.md content.
@@ -54,3 +56,22 @@ func ParseFrontmatter(data []byte) (SkillFrontmatter, error) {
 
 	return fm, nil
 }
+
+// Body returns the Markdown of SKILL.md content that follows its
+// frontmatter, or the whole content if it has no frontmatter.
+func Body(data []byte) string {
+	content := strings.TrimLeftFunc(string(data), unicode.IsSpace)
+	if !strings.HasPrefix(content, "---") {
+		return string(data)
+	}
+	rest := content[3:]
+	idx := strings.Index(rest, "\n---")
+	if idx == -1 {
+		return string(data)
+	}
+	rest = rest[idx+len("\n---"):]
+	if i := strings.IndexByte(rest, '\n'); i >= 0 {
+		return rest[i+1:]
+	}
+	return ""
+}
@@ -0,0 +1,356 @@
+package skill
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// semverRegex matches a SemVer 2.0 version: major.minor.patch without
+// leading zeros, an optional prerelease and optional build metadata.
+var semverRegex = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// Version is a parsed SemVer 2.0 version.
+type Version struct {
+	Major, Minor, Patch uint64
+	// Prerelease holds the dot-separated prerelease identifiers, e.g.
+	// ["rc", "1"] for 1.0.0-rc.1.
+	Prerelease []string
+	// Build holds the dot-separated build metadata identifiers. They do
+	// not affect precedence.
+	Build []string
+}
+
+// ParseVersion parses a full SemVer 2.0 version such as "1.2.0-rc.1+build.5".
+func ParseVersion(s string) (Version, error) {
+	m := semverRegex.FindStringSubmatch(s)
+	if m == nil {
+		return Version{}, fmt.Errorf("%q is not valid semver", s)
+	}
+	var v Version
+	var err error
+	for i, p := range []*uint64{&v.Major, &v.Minor, &v.Patch} {
+		if *p, err = strconv.ParseUint(m[i+1], 10, 64); err != nil {
+			return Version{}, fmt.Errorf("%q is not valid semver: %w", s, err)
+		}
+	}
+	if m[4] != "" {
+		v.Prerelease = strings.Split(m[4], ".")
+	}
+	if m[5] != "" {
+		v.Build = strings.Split(m[5], ".")
+	}
+	return v, nil
+}
+
+// String returns the version in SemVer notation.
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if len(v.Build) > 0 {
+		s += "+" + strings.Join(v.Build, ".")
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 as v has lower, equal or higher precedence
+// than o. Build metadata is ignored, so 1.0.0+a and 1.0.0+b compare equal.
+func (v Version) Compare(o Version) int {
+	if c := compareUint(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+	// A prerelease has lower precedence than its release
+	switch {
+	case len(v.Prerelease) == 0 && len(o.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(o.Prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.Prerelease) && i < len(o.Prerelease); i++ {
+		if c := compareIdentifier(v.Prerelease[i], o.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareUint(uint64(len(v.Prerelease)), uint64(len(o.Prerelease)))
+}
+
+// compareIdentifier compares prerelease identifiers: numeric identifiers
+// numerically and below alphanumeric ones, which compare in ASCII order.
+func compareIdentifier(a, b string) int {
+	an, aErr := strconv.ParseUint(a, 10, 64)
+	bn, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareUint(an, bn)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// CompareVersions compares two version strings by SemVer precedence.
+// Strings that are not valid semver sort after valid ones, in string
+// order, so that sorting never fails on a malformed version.
+func CompareVersions(a, b string) int {
+	va, aErr := ParseVersion(a)
+	vb, bErr := ParseVersion(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		if c := va.Compare(vb); c != 0 {
+			return c
+		}
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// SortVersions sorts version strings by ascending SemVer precedence.
+func SortVersions(versions []string) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return CompareVersions(versions[i], versions[j]) < 0
+	})
+}
+
+// comparator is a single "<op><version>" condition of a constraint.
+type comparator struct {
+	op string // "=", ">", ">=", "<" or "<="
+	v  Version
+}
+
+func (c comparator) match(v Version) bool {
+	cmp := v.Compare(c.v)
+	switch c.op {
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	}
+	return cmp == 0
+}
+
+// Constraint is a range of versions, such as "^1.2", "~1.2.3" or
+// ">=1.0 <2".
+type Constraint struct {
+	raw string
+	// sets are alternatives ("||"); a version matches a set if it
+	// matches every comparator of it.
+	sets [][]comparator
+}
+
+// ParseConstraint parses a version range. A range is one or more sets of
+// conditions separated by "||"; the conditions of a set, separated by
+// spaces or commas, must all hold. A condition is one of:
+//
+//	1.2.3, =1.2.3    exactly 1.2.3
+//	>1.2, >=1.2, <2, <=2.1
+//	~1.2.3           >=1.2.3 <1.3.0 (patch updates)
+//	^1.2.3           >=1.2.3 <2.0.0 (no change to the leftmost non-zero part)
+//	1.2, 1.2.x, 1    any version with that prefix
+//	*, x             any version
+//
+// Missing parts of a version are wildcards, as in npm, but an empty range
+// or alternative is an error rather than any version. Prereleases only
+// match a condition that names a prerelease of the same major.minor.patch,
+// so "^1.2" never selects 2.0.0-rc.1 or 1.3.0-beta.
+func ParseConstraint(s string) (Constraint, error) {
+	c := Constraint{raw: s}
+	for _, alt := range strings.Split(s, "||") {
+		var set []comparator
+		fields := strings.Fields(strings.ReplaceAll(alt, ",", " "))
+		if len(fields) == 0 {
+			return Constraint{}, fmt.Errorf("invalid version range %q: empty alternative, write * for any version", s)
+		}
+		for i := 0; i < len(fields); i++ {
+			f := fields[i]
+			// Allow a space between the operator and the version
+			if strings.Trim(f, "<>=~^") == "" && i+1 < len(fields) {
+				i++
+				f += fields[i]
+			}
+			cs, err := parseCondition(f)
+			if err != nil {
+				return Constraint{}, fmt.Errorf("invalid version range %q: %w", s, err)
+			}
+			set = append(set, cs...)
+		}
+		c.sets = append(c.sets, set)
+	}
+	return c, nil
+}
+
+// String returns the constraint as it was written.
+func (c Constraint) String() string {
+	return c.raw
+}
+
+// Match reports whether v satisfies the constraint.
+func (c Constraint) Match(v Version) bool {
+	for _, set := range c.sets {
+		if matchSet(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSet(set []comparator, v Version) bool {
+	for _, c := range set {
+		if !c.match(v) {
+			return false
+		}
+	}
+	if len(v.Prerelease) == 0 {
+		return true
+	}
+	for _, c := range set {
+		if len(c.v.Prerelease) > 0 && c.v.Major == v.Major && c.v.Minor == v.Minor && c.v.Patch == v.Patch {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCondition expands one condition into comparators.
+func parseCondition(s string) ([]comparator, error) {
+	op := ""
+	for _, prefix := range []string{">=", "<=", ">", "<", "=", "~", "^"} {
+		if strings.HasPrefix(s, prefix) {
+			op, s = prefix, strings.TrimPrefix(s, prefix)
+			break
+		}
+	}
+	s = strings.TrimPrefix(s, "v")
+	v, parts, err := parsePartial(s)
+	if err != nil {
+		return nil, err
+	}
+
+	// next returns the lowest version above every version matching the
+	// first n parts of v.
+	next := func(n int) Version {
+		switch n {
+		case 1:
+			return Version{Major: v.Major + 1}
+		case 2:
+			return Version{Major: v.Major, Minor: v.Minor + 1}
+		}
+		return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	}
+	lower := comparator{">=", v}
+
+	if parts == 0 {
+		if op == "<" || op == ">" {
+			// Nothing is below or above every version
+			return []comparator{{"<", Version{}}}, nil
+		}
+		return nil, nil
+	}
+	switch op {
+	case "", "=":
+		if parts == 3 {
+			return []comparator{{"=", v}}, nil
+		}
+		return []comparator{lower, {"<", next(parts)}}, nil
+	case ">":
+		if parts == 3 {
+			return []comparator{{">", v}}, nil
+		}
+		return []comparator{{">=", next(parts)}}, nil
+	case ">=":
+		return []comparator{lower}, nil
+	case "<":
+		return []comparator{{"<", v}}, nil
+	case "<=":
+		if parts == 3 {
+			return []comparator{{"<=", v}}, nil
+		}
+		return []comparator{{"<", next(parts)}}, nil
+	case "~":
+		if parts == 1 {
+			return []comparator{lower, {"<", next(1)}}, nil
+		}
+		return []comparator{lower, {"<", next(2)}}, nil
+	}
+	// "^": the leftmost non-zero part, or the last given part, is fixed
+	var fixed int
+	switch {
+	case v.Major > 0 || parts == 1:
+		fixed = 1
+	case v.Minor > 0 || parts == 2:
+		fixed = 2
+	default:
+		fixed = 3
+	}
+	return []comparator{lower, {"<", next(fixed)}}, nil
+}
+
+// parsePartial parses a version with up to three parts, of which trailing
+// parts may be missing or wildcards ("x", "X" or "*"). It returns the
+// version, with missing parts zero, and the number of parts given.
+func parsePartial(s string) (Version, int, error) {
+	if s == "" {
+		return Version{}, 0, fmt.Errorf("missing version")
+	}
+	if v, err := ParseVersion(s); err == nil {
+		return v, 3, nil
+	}
+	fields := strings.Split(s, ".")
+	if len(fields) > 3 {
+		return Version{}, 0, fmt.Errorf("%q is not a valid version", s)
+	}
+	var nums [3]uint64
+	parts := 0
+	for i, f := range fields {
+		if f == "x" || f == "X" || f == "*" {
+			break
+		}
+		if len(f) > 1 && f[0] == '0' {
+			return Version{}, 0, fmt.Errorf("%q is not a valid version", s)
+		}
+		n, err := strconv.ParseUint(f, 10, 64)
+		if err != nil {
+			return Version{}, 0, fmt.Errorf("%q is not a valid version", s)
+		}
+		nums[i] = n
+		parts++
+	}
+	for _, f := range fields[parts:] {
+		if f != "x" && f != "X" && f != "*" {
+			return Version{}, 0, fmt.Errorf("%q is not a valid version", s)
+		}
+	}
+	return Version{Major: nums[0], Minor: nums[1], Patch: nums[2]}, parts, nil
+}
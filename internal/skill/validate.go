@@ -8,11 +8,9 @@ import (
 
 var nameRegex = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
 
-// semverRegex matches major.minor.patch where each is a non-negative integer.
-var semverRegex = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
-
-// majorMinorRegex matches major.minor where each is a non-negative integer.
-var majorMinorRegex = regexp.MustCompile(`^\d+\.\d+$`)
+// majorMinorRegex matches major.minor where each is a non-negative integer
+// without leading zeros.
+var majorMinorRegex = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)$`)
 
 // Validate checks a SkillFrontmatter against all validation rules.
 // dirName is the name of the parent directory containing the SKILL.md.
@@ -54,7 +52,7 @@ func Validate(fm SkillFrontmatter, dirName string) []string {
 	// Version validation
 	if fm.Metadata.Version == "" {
 		errs = append(errs, "metadata.version: required field is missing")
-	} else if !semverRegex.MatchString(fm.Metadata.Version) && !majorMinorRegex.MatchString(fm.Metadata.Version) {
+	} else if _, err := ParseVersion(fm.Metadata.Version); err != nil && !majorMinorRegex.MatchString(fm.Metadata.Version) {
 		errs = append(errs, fmt.Sprintf("metadata.version: %q is not valid semver", fm.Metadata.Version))
 	}
 
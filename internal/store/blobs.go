@@ -0,0 +1,166 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// BlobsDir is the directory of the store, relative to its root, that holds
+// file contents by digest, as blobs/sha256/<hex> in an OCI image layout.
+// A skill cannot be named after it.
+const BlobsDir = "blobs"
+
+// IndexFile is the file of an artifact directory that lists the blob of
+// each skill file.
+const IndexFile = "index.json"
+
+// PackedManifestFile is the file of an artifact directory that records
+// the OCI manifest the artifact was packed to when it was stored.
+const PackedManifestFile = "oci-manifest.json"
+
+// Index maps the skill files of a stored artifact to their blobs.
+type Index struct {
+	SchemaVersion int `json:"schemaVersion"`
+	// Files lists every skill file, sorted by path. Digest names the blob
+	// holding the file's contents.
+	Files []FileEntry `json:"files"`
+}
+
+// ReadIndex reads the index of the artifact in dir.
+func ReadIndex(dir string) (Index, error) {
+	data, err := os.ReadFile(filepath.Join(dir, IndexFile))
+	if err != nil {
+		return Index{}, err
+	}
+	var idx Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return Index{}, fmt.Errorf("failed to parse %s: %w", IndexFile, err)
+	}
+	return idx, nil
+}
+
+// BlobPath returns the path of the blob with the given "sha256:<hex>"
+// digest.
+func (s *Store) BlobPath(digest string) string {
+	return filepath.Join(s.root, BlobsDir, "sha256", strings.TrimPrefix(digest, "sha256:"))
+}
+
+// intern moves the skill files of the artifact in dir into the blob store,
+// leaving a hard link to the blob in their place, and writes the artifact's
+// index. A file whose contents are already stored is replaced by a link to
+// the existing blob, so identical files are stored once. Files stay
+// regular copies where a link is not possible, such as when the blob's
+// executable bit differs, since a link shares it.
+func (s *Store) intern(dir string) error {
+	blobDir := filepath.Join(s.root, BlobsDir, "sha256")
+	if err := os.MkdirAll(blobDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	idx := Index{SchemaVersion: 1, Files: []FileEntry{}}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return nil
+		}
+		if IsReserved(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		sum, err := fileDigest(path)
+		if err != nil {
+			return err
+		}
+		entry := FileEntry{
+			Path:       rel,
+			Size:       info.Size(),
+			Digest:     "sha256:" + sum,
+			Executable: info.Mode().Perm()&0o111 != 0,
+		}
+		idx.Files = append(idx.Files, entry)
+		return s.linkBlob(path, entry)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store blobs: %w", err)
+	}
+
+	sort.Slice(idx.Files, func(i, j int) bool {
+		return idx.Files[i].Path < idx.Files[j].Path
+	})
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+	data = append(data, '\n')
+	path := filepath.Join(dir, IndexFile)
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", IndexFile, err)
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// linkBlob makes the file at path, described by e, share its blob.
+func (s *Store) linkBlob(path string, e FileEntry) error {
+	blob := s.BlobPath(e.Digest)
+	info, err := os.Stat(blob)
+	switch {
+	case os.IsNotExist(err):
+		// The file becomes the blob. If the link fails, because a
+		// concurrent writer stored the same contents first or the file
+		// system has no hard links, the file stays a plain copy.
+		os.Link(path, blob)
+		return nil
+	case err != nil:
+		return err
+	}
+
+	if current, _ := os.Stat(path); os.SameFile(info, current) {
+		return nil
+	}
+	if sum, err := fileDigest(blob); err != nil || "sha256:"+sum != e.Digest {
+		// The blob was modified in place through another artifact; the
+		// new file takes its place.
+		return replaceWithLink(path, blob)
+	}
+	if (info.Mode().Perm()&0o111 != 0) != e.Executable {
+		return nil
+	}
+	return replaceWithLink(blob, path)
+}
+
+// replaceWithLink atomically replaces dst with a hard link to src. It
+// leaves dst unchanged if the link cannot be made.
+func replaceWithLink(src, dst string) error {
+	tmp := dst + ".link"
+	os.Remove(tmp)
+	if err := os.Link(src, tmp); err != nil {
+		return nil
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
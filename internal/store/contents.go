@@ -0,0 +1,144 @@
+package store
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LayoutError lists files that fall outside the Agent Skills layout.
+type LayoutError struct {
+	Paths []string
+}
+
+func (e *LayoutError) Error() string {
+	return fmt.Sprintf("%d file(s) outside the skill layout (SKILL.md, scripts/, references/, assets/): %s",
+		len(e.Paths), strings.Join(e.Paths, ", "))
+}
+
+// ScanContents records every file of the skill in dir with its size and
+// sha256 digest, grouped by the conventional scripts/, references/ and
+// assets/ directories. Files outside that layout are recorded in Other if
+// allowOther is true and reported as a *LayoutError otherwise. Reserved
+// top-level entries are skipped.
+func ScanContents(dir string, allowOther bool) (Contents, error) {
+	var c Contents
+	var unknown []string
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return nil
+		}
+		if IsReserved(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if rel == "SKILL.md" {
+			c.SkillFile = rel
+			return nil
+		}
+
+		entry, err := scanFile(path, rel)
+		if err != nil {
+			return err
+		}
+		top, _, nested := strings.Cut(rel, "/")
+		switch {
+		case nested && top == "scripts":
+			c.Scripts = append(c.Scripts, entry)
+		case nested && top == "references":
+			c.References = append(c.References, entry)
+		case nested && top == "assets":
+			c.Assets = append(c.Assets, entry)
+		case allowOther:
+			c.Other = append(c.Other, entry)
+		default:
+			unknown = append(unknown, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return Contents{}, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+	if len(unknown) > 0 {
+		return Contents{}, &LayoutError{Paths: unknown}
+	}
+	return c, nil
+}
+
+// CheckContents compares the files in dir with the entries recorded in c
+// and returns a description of each difference: recorded files that are
+// missing or modified, and files present in dir but not recorded.
+func CheckContents(dir string, c Contents) ([]string, error) {
+	actual, err := ScanContents(dir, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	if c.SkillFile != "" && actual.SkillFile == "" {
+		problems = append(problems, "missing: "+c.SkillFile)
+	}
+
+	recorded := map[string]FileEntry{}
+	for _, e := range c.Files() {
+		recorded[e.Path] = e
+	}
+	present := map[string]FileEntry{}
+	for _, e := range actual.Files() {
+		present[e.Path] = e
+	}
+
+	for _, e := range c.Files() {
+		got, ok := present[e.Path]
+		switch {
+		case !ok:
+			problems = append(problems, "missing: "+e.Path)
+		case got.Digest != e.Digest || got.Size != e.Size:
+			problems = append(problems, "modified: "+e.Path)
+		case got.Executable != e.Executable:
+			problems = append(problems, "mode changed: "+e.Path)
+		}
+	}
+	for _, e := range actual.Files() {
+		if _, ok := recorded[e.Path]; !ok {
+			problems = append(problems, "added: "+e.Path)
+		}
+	}
+	return problems, nil
+}
+
+func scanFile(path, rel string) (FileEntry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return FileEntry{}, err
+	}
+	if !info.Mode().IsRegular() {
+		return FileEntry{}, fmt.Errorf("unsupported file type: %s", rel)
+	}
+	sum, err := fileDigest(path)
+	if err != nil {
+		return FileEntry{}, err
+	}
+	return FileEntry{
+		Path:       rel,
+		Size:       info.Size(),
+		Digest:     "sha256:" + sum,
+		Executable: info.Mode().Perm()&0o111 != 0,
+	}, nil
+}
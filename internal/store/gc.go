@@ -0,0 +1,214 @@
+package store
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// reasonNoArtifacts is the Reason of a skill directory that holds no
+// artifacts once the other garbage is gone.
+const reasonNoArtifacts = "skill directory has no artifacts"
+
+// Garbage is a store entry that no command will ever read again and that
+// GC may delete.
+type Garbage struct {
+	// Path is the absolute path of the entry.
+	Path string `json:"path"`
+	// Reason explains why the entry is garbage.
+	Reason string `json:"reason"`
+	// Size is the number of bytes deleting the entry reclaims.
+	Size int64 `json:"size"`
+}
+
+// FindGarbage returns the entries of the store that GC would delete:
+// temp directories of writes and removals whose process is no longer
+// running, artifacts without a readable manifest or SKILL.md, skill
+// directories left without artifacts, and blobs that no artifact uses.
+// Entries of running processes are left alone.
+func (s *Store) FindGarbage() ([]Garbage, error) {
+	entries, err := os.ReadDir(s.root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read store: %w", err)
+	}
+
+	var garbage []Garbage
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || entry.Name() == BlobsDir {
+			continue
+		}
+		name := entry.Name()
+		nameDir := filepath.Join(s.root, name)
+		versionEntries, err := os.ReadDir(nameDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read store: %w", err)
+		}
+
+		live := 0
+		for _, ve := range versionEntries {
+			if !ve.IsDir() {
+				live++
+				continue
+			}
+			path := filepath.Join(nameDir, ve.Name())
+			reason := garbageReason(path, name, ve.Name())
+			if reason == "" {
+				live++
+				continue
+			}
+			size, err := dirSize(path)
+			if err != nil {
+				return nil, err
+			}
+			garbage = append(garbage, Garbage{Path: path, Reason: reason, Size: size})
+		}
+		if live == 0 {
+			garbage = append(garbage, Garbage{Path: nameDir, Reason: reasonNoArtifacts})
+		}
+	}
+
+	blobs, err := s.unusedBlobs(garbage)
+	if err != nil {
+		return nil, err
+	}
+	return append(garbage, blobs...), nil
+}
+
+// unusedBlobs returns the blobs that are neither listed in the index of an
+// artifact that survives garbage nor linked from an artifact directory,
+// such as one still being written. A blob still linked from garbage is
+// collected by the next run.
+func (s *Store) unusedBlobs(garbage []Garbage) ([]Garbage, error) {
+	blobDir := filepath.Join(s.root, BlobsDir, "sha256")
+	blobs, err := os.ReadDir(blobDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read blobs: %w", err)
+	}
+
+	doomed := map[string]bool{}
+	for _, g := range garbage {
+		doomed[g.Path] = true
+	}
+	entries, err := s.Entries()
+	if err != nil {
+		return nil, err
+	}
+	used := map[string]bool{}
+	for _, e := range entries {
+		if doomed[e.Path] {
+			continue
+		}
+		idx, err := ReadIndex(e.Path)
+		if err != nil {
+			continue
+		}
+		for _, f := range idx.Files {
+			used[f.Digest] = true
+		}
+	}
+
+	var unused []Garbage
+	for _, b := range blobs {
+		if used["sha256:"+b.Name()] {
+			continue
+		}
+		info, err := b.Info()
+		if err != nil {
+			return nil, err
+		}
+		if linkCount(info) > 1 {
+			continue
+		}
+		unused = append(unused, Garbage{Path: filepath.Join(blobDir, b.Name()), Reason: "unreferenced blob", Size: info.Size()})
+	}
+	return unused, nil
+}
+
+// GC deletes the given garbage, as returned by FindGarbage. Skill
+// directories are only deleted while empty, so a write that started after
+// FindGarbage keeps its directory.
+func (s *Store) GC(garbage []Garbage) error {
+	for _, g := range garbage {
+		if g.Reason == reasonNoArtifacts {
+			os.Remove(g.Path)
+			continue
+		}
+		if err := os.RemoveAll(g.Path); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", g.Path, err)
+		}
+		os.Remove(filepath.Dir(g.Path))
+	}
+	return nil
+}
+
+// garbageReason returns why the directory path, stored as entry under the
+// skill directory of name, is garbage, or "" if it is not.
+func garbageReason(path, name, entry string) string {
+	if version, pid, ok := tempOwner(entry, tempSep); ok && (strings.HasPrefix(version, ".") || isLegacyTemp(path, entry)) {
+		if processRunning(pid) {
+			return ""
+		}
+		return fmt.Sprintf("orphaned temp directory of %s@%s (pid %d is not running)", name, strings.TrimPrefix(version, "."), pid)
+	}
+	if version, pid, ok := tempOwner(entry, trashSep); ok && strings.HasPrefix(version, ".") {
+		if processRunning(pid) {
+			return ""
+		}
+		return fmt.Sprintf("interrupted removal of %s@%s (pid %d is not running)", name, version[1:], pid)
+	}
+
+	m, err := ReadManifest(filepath.Join(path, "manifest.json"))
+	if err != nil {
+		return fmt.Sprintf("partial artifact: %v", err)
+	}
+	if m.Name != name || m.Version != entry {
+		return fmt.Sprintf("partial artifact: manifest.json describes %s@%s", m.Name, m.Version)
+	}
+	if _, err := os.Stat(filepath.Join(path, "SKILL.md")); err != nil {
+		return "partial artifact: SKILL.md is missing"
+	}
+	return ""
+}
+
+// tempOwner splits a temp directory name such as "1.0.0.tmp.123" at sep
+// into the version and the pid of the process that created it. A leading
+// dot is kept in the version.
+func tempOwner(entry, sep string) (version string, pid int, ok bool) {
+	i := strings.LastIndex(entry, sep)
+	if i < 0 {
+		return "", 0, false
+	}
+	pid, err := strconv.Atoi(entry[i+len(sep):])
+	if err != nil || pid <= 0 {
+		return "", 0, false
+	}
+	return entry[:i], pid, true
+}
+
+// dirSize returns the total size of the regular files under dir.
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
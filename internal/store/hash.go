@@ -0,0 +1,123 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// reservedNames are top-level entries of a stored artifact that are
+// written by psk rather than copied from the skill source.
+var reservedNames = map[string]bool{
+	"manifest.json":    true,
+	"signatures":       true,
+	"attestations":     true,
+	IndexFile:          true,
+	PackedManifestFile: true,
+}
+
+// IsReserved reports whether rel, a slash-separated path relative to an
+// artifact directory, is managed by psk rather than part of the skill.
+func IsReserved(rel string) bool {
+	top, _, _ := strings.Cut(rel, "/")
+	return reservedNames[top]
+}
+
+// HashTree returns a canonical digest of the skill files under dir.
+//
+// Each regular file contributes a line "<mode> <sha256> <path>\n", where
+// mode is 755 for executable files and 644 otherwise and path is
+// slash-separated and relative to dir. Lines are sorted by path and the
+// result is the sha256 of their concatenation, in "sha256:<hex>" form.
+// Directories, timestamps and ownership do not contribute, and reserved
+// top-level entries are skipped, so a source directory and its stored
+// copy hash the same.
+func HashTree(dir string) (string, error) {
+	type entry struct {
+		path, line string
+	}
+	var entries []entry
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return nil
+		}
+		if IsReserved(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("unsupported file type: %s", rel)
+		}
+		sum, err := fileDigest(path)
+		if err != nil {
+			return err
+		}
+		mode := "644"
+		if info.Mode().Perm()&0o111 != 0 {
+			mode = "755"
+		}
+		entries = append(entries, entry{rel, fmt.Sprintf("%s %s %s\n", mode, sum, rel)})
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", dir, err)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].path < entries[j].path
+	})
+	h := sha256.New()
+	for _, e := range entries {
+		io.WriteString(h, e.line)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// FileDigest returns the digest of a file's contents in "sha256:<hex>"
+// form.
+func FileDigest(path string) (string, error) {
+	sum, err := fileDigest(path)
+	if err != nil {
+		return "", err
+	}
+	return "sha256:" + sum, nil
+}
+
+// fileDigest returns the hex sha256 of a file's contents.
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,141 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// locksDir holds the lock files of the store. Lock files are never
+// deleted, since removing a file another process is waiting on would let
+// two processes hold "the same" lock.
+const locksDir = ".locks"
+
+// storeLockFile is the lock taken shared by writers of single artifacts
+// and exclusively by operations on the whole store, such as gc and
+// migrations.
+const storeLockFile = "store.lock"
+
+// DefaultLockTimeout is how long a store operation waits for a lock held
+// by another process unless PSK_LOCK_TIMEOUT is set.
+const DefaultLockTimeout = 30 * time.Second
+
+// lockPollInterval is how often a waiting process retries a held lock.
+const lockPollInterval = 50 * time.Millisecond
+
+// LockedError is returned when a lock of the store is still held by
+// another process after the lock timeout.
+type LockedError struct {
+	// Skill is the name@version of the locked artifact, or "" if the
+	// whole store is locked.
+	Skill string
+	// PID is the process that last took the lock, or 0 if unknown.
+	PID int
+}
+
+func (e *LockedError) Error() string {
+	holder := "another process"
+	if e.PID > 0 {
+		holder = fmt.Sprintf("pid %d", e.PID)
+	}
+	if e.Skill != "" {
+		return fmt.Sprintf("store is locked by %s (writing %s)", holder, e.Skill)
+	}
+	return fmt.Sprintf("store is locked by %s", holder)
+}
+
+// lockTimeout returns how long to wait for a lock: PSK_LOCK_TIMEOUT as a
+// duration such as "2m", or DefaultLockTimeout if unset or invalid.
+func lockTimeout() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("PSK_LOCK_TIMEOUT")); err == nil && d >= 0 {
+		return d
+	}
+	return DefaultLockTimeout
+}
+
+// Lock takes the store lock exclusively, waiting for every other store
+// operation to finish, and returns the function that releases it. It
+// fails with a *LockedError if the store is still locked after the lock
+// timeout.
+func (s *Store) Lock() (unlock func(), err error) {
+	return s.lock(storeLockFile, "", true)
+}
+
+// LockArtifact takes the store lock shared and the lock of {name}/{version}
+// exclusively, so that writers of different artifacts run concurrently
+// while writers of the same artifact, and gc or migrations, wait for each
+// other. It returns the function that releases both locks, and fails with
+// a *LockedError like Lock.
+func (s *Store) LockArtifact(name, version string) (unlock func(), err error) {
+	skill := name + "@" + version
+	unlockStore, err := s.lock(storeLockFile, skill, false)
+	if err != nil {
+		return nil, err
+	}
+	unlockArtifact, err := s.lock(skill+".lock", skill, true)
+	if err != nil {
+		unlockStore()
+		return nil, err
+	}
+	return func() {
+		unlockArtifact()
+		unlockStore()
+	}, nil
+}
+
+// lock takes the lock file of the given name, polling until the lock
+// timeout, and records the pid of this process in it for the error of
+// the next waiter.
+func (s *Store) lock(file, skill string, exclusive bool) (func(), error) {
+	dir := filepath.Join(s.root, locksDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	path := filepath.Join(dir, file)
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(lockTimeout())
+	for {
+		ok, err := tryLock(f, exclusive)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if ok {
+			break
+		}
+		if !time.Now().Before(deadline) {
+			pid := readLockPID(f)
+			f.Close()
+			return nil, &LockedError{Skill: skill, PID: pid}
+		}
+		time.Sleep(lockPollInterval)
+	}
+
+	// Holders of a shared lock overwrite each other's pid; any of them
+	// names a process the waiter is waiting on.
+	if err := f.Truncate(0); err == nil {
+		f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// readLockPID returns the pid recorded in the lock file f, or 0.
+func readLockPID(f *os.File) int {
+	buf := make([]byte, 32)
+	n, _ := f.ReadAt(buf, 0)
+	pid, err := strconv.Atoi(strings.TrimSpace(string(buf[:n])))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
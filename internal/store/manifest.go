@@ -7,12 +7,35 @@ import (
 	"os"
 )
 
-// Contents describes the files in a stored skill artifact.
+// FileEntry describes a single file in a stored skill artifact.
+type FileEntry struct {
+	Path       string `json:"path"`
+	Size       int64  `json:"size"`
+	Digest     string `json:"digest"`
+	Executable bool   `json:"executable,omitempty"`
+}
+
+// Contents describes the files in a stored skill artifact. Scripts,
+// References and Assets list the files under the Agent Skills conventional
+// directories; Other lists files outside that layout, which build only
+// accepts when explicitly allowed.
 type Contents struct {
-	SkillFile  string   `json:"skillFile"`
-	Scripts    []string `json:"scripts,omitempty"`
-	References []string `json:"references,omitempty"`
-	Assets     []string `json:"assets,omitempty"`
+	SkillFile  string      `json:"skillFile"`
+	Scripts    []FileEntry `json:"scripts,omitempty"`
+	References []FileEntry `json:"references,omitempty"`
+	Assets     []FileEntry `json:"assets,omitempty"`
+	Other      []FileEntry `json:"other,omitempty"`
+}
+
+// Files returns every file entry in c, in the order scripts, references,
+// assets, other.
+func (c Contents) Files() []FileEntry {
+	var files []FileEntry
+	files = append(files, c.Scripts...)
+	files = append(files, c.References...)
+	files = append(files, c.Assets...)
+	files = append(files, c.Other...)
+	return files
 }
 
 // Manifest represents the metadata for a stored skill artifact.
@@ -0,0 +1,204 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Version is the layout version of stores written by this package:
+// artifact files are hard links into blobs/sha256 and every artifact
+// directory has an index.json.
+const Version = 2
+
+// versionFile records the layout version of a store.
+const versionFile = ".store-version"
+
+// backupDir holds copies of the store taken before migrations.
+const backupDir = ".backup"
+
+// Migration upgrades a store from layout version From to From+1.
+type Migration struct {
+	From        int
+	Description string
+	// apply must be safe to run again after an interruption, and must
+	// replace skill files and blobs rather than write into them, since
+	// backups share them with the store through hard links.
+	apply func(s *Store) error
+}
+
+// migrations upgrade a store one version at a time, in order.
+var migrations = []Migration{
+	{From: 1, Description: "store artifact files once in blobs/sha256 and write index.json", apply: (*Store).migrateBlobs},
+}
+
+// ErrNewerVersion is returned for a store written by a newer psk.
+var ErrNewerVersion = errors.New("store was written by a newer version of psk")
+
+// ReadVersion returns the layout version of the store, or 0 if it has not
+// been initialized.
+func (s *Store) ReadVersion() (int, error) {
+	data, err := os.ReadFile(filepath.Join(s.root, versionFile))
+	if os.IsNotExist(err) {
+		// A store with artifacts but no version file predates versioning
+		if artifacts, _ := s.Entries(); len(artifacts) > 0 {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", versionFile, err)
+	}
+	version, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("invalid %s: %q", versionFile, strings.TrimSpace(string(data)))
+	}
+	return version, nil
+}
+
+// Pending returns the migrations that Open would run, in order, and the
+// store's current version.
+func (s *Store) Pending() ([]Migration, int, error) {
+	version, err := s.ReadVersion()
+	if err != nil {
+		return nil, 0, err
+	}
+	if version > Version {
+		return nil, version, fmt.Errorf("%w: version %d, this psk supports up to %d; upgrade psk", ErrNewerVersion, version, Version)
+	}
+	if version == 0 {
+		return nil, 0, nil
+	}
+	var pending []Migration
+	for _, m := range migrations {
+		if m.From >= version {
+			pending = append(pending, m)
+		}
+	}
+	return pending, version, nil
+}
+
+// BackupPath returns where Open backs up a store of the given version
+// before migrating it.
+func (s *Store) BackupPath(version int) string {
+	return filepath.Join(s.root, backupDir, fmt.Sprintf("v%d", version))
+}
+
+// Open checks the store's layout version and migrates an older store to
+// the current version. It fails with ErrNewerVersion for a store written by
+// a newer psk. Migrations run under the exclusive store lock. Before the
+// first migration the store is backed up to BackupPath. Each migration
+// records its version only once complete, so an interrupted migration
+// resumes on the next Open. It returns the path of the backup, or "" if
+// nothing was migrated.
+func (s *Store) Open() (string, error) {
+	pending, version, err := s.Pending()
+	if err != nil || len(pending) == 0 {
+		return "", err
+	}
+	unlock, err := s.Lock()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+	// Another process may have migrated the store while we waited
+	pending, version, err = s.Pending()
+	if err != nil || len(pending) == 0 {
+		return "", err
+	}
+
+	backup := s.BackupPath(version)
+	if err := s.backup(backup); err != nil {
+		return "", fmt.Errorf("failed to back up store: %w", err)
+	}
+	for _, m := range pending {
+		if err := m.apply(s); err != nil {
+			return backup, fmt.Errorf("failed to migrate store from version %d to %d: %w (backup in %s)", m.From, m.From+1, err, backup)
+		}
+		if err := s.writeVersion(m.From + 1); err != nil {
+			return backup, err
+		}
+	}
+	return backup, nil
+}
+
+// backup copies the store, except earlier backups and locks, to dest. Skill files
+// and blobs, which psk never writes into, are hard linked where possible;
+// metadata that psk rewrites in place, such as manifest.json and
+// signatures, is copied. A complete backup is kept as is, so a resumed
+// migration keeps the backup of the original store.
+func (s *Store) backup(dest string) error {
+	if _, err := os.Stat(dest); err == nil {
+		return nil
+	}
+	tmp := dest + ".tmp"
+	if err := os.RemoveAll(tmp); err != nil {
+		return err
+	}
+	err := filepath.WalkDir(s.root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(s.root, path)
+		if err != nil {
+			return err
+		}
+		if rel == backupDir || rel == locksDir {
+			return filepath.SkipDir
+		}
+		target := filepath.Join(tmp, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		// Skill files are {name}/{version}/<path> outside reserved entries
+		parts := strings.SplitN(filepath.ToSlash(rel), "/", 3)
+		if parts[0] == BlobsDir || (len(parts) == 3 && !IsReserved(parts[2])) {
+			if err := os.Link(path, target); err == nil {
+				return nil
+			}
+		}
+		return copyFile(path, target)
+	})
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
+
+// writeVersion atomically records the store's layout version.
+func (s *Store) writeVersion(version int) error {
+	path := filepath.Join(s.root, versionFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(fmt.Sprintf("%d\n", version)), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", versionFile, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", versionFile, err)
+	}
+	return nil
+}
+
+// migrateBlobs moves the files of every artifact of a version 1 store,
+// which kept a full copy of each artifact, into blobs.
+func (s *Store) migrateBlobs() error {
+	if entries, err := os.ReadDir(filepath.Join(s.root, BlobsDir)); err == nil {
+		for _, e := range entries {
+			if e.Name() != "sha256" {
+				return fmt.Errorf("skill %q conflicts with the blob directory; remove it first", BlobsDir)
+			}
+		}
+	}
+	entries, err := s.Entries()
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := s.intern(e.Path); err != nil {
+			return fmt.Errorf("%s@%s: %w", e.Name, e.Version, err)
+		}
+	}
+	return nil
+}
@@ -3,9 +3,13 @@ package store
 import (
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/skill"
 )
 
 // Store manages the local Proven Skill Artifact store.
@@ -26,18 +30,21 @@ func New(storePath string) *Store {
 	return &Store{root: storePath}
 }
 
-// Init creates the store directory and .store-version file if they don't exist.
+// Init creates the store directory and .store-version file if they don't
+// exist, and otherwise opens the store like Open.
 func (s *Store) Init() error {
 	if err := os.MkdirAll(s.root, 0o755); err != nil {
 		return fmt.Errorf("failed to create store directory: %w", err)
 	}
-	versionFile := filepath.Join(s.root, ".store-version")
-	if _, err := os.Stat(versionFile); os.IsNotExist(err) {
-		if err := os.WriteFile(versionFile, []byte("1\n"), 0o644); err != nil {
-			return fmt.Errorf("failed to write .store-version: %w", err)
-		}
+	version, err := s.ReadVersion()
+	if err != nil {
+		return err
 	}
-	return nil
+	if version == 0 {
+		return s.writeVersion(Version)
+	}
+	_, err = s.Open()
+	return err
 }
 
 // Exists checks if a skill artifact with the given name and version already
@@ -57,6 +64,58 @@ func (s *Store) ArtifactPath(name, version string) string {
 // It writes atomically via a temp directory + os.Rename.
 // If force is true, an existing artifact is replaced.
 func (s *Store) Add(name, version, sourceDir string, manifest Manifest, force bool) (string, error) {
+	if err := checkReserved(sourceDir); err != nil {
+		return "", err
+	}
+	return s.AddFunc(name, version, manifest, force, func(dir string) error {
+		return CopySkill(sourceDir, dir)
+	})
+}
+
+// CopySkill copies the skill source directory sourceDir into dir, for use
+// in an AddFunc populate function. The source must not contain entries
+// reserved by psk.
+func CopySkill(sourceDir, dir string) error {
+	if err := checkReserved(sourceDir); err != nil {
+		return err
+	}
+	if err := copyDir(sourceDir, dir); err != nil {
+		return fmt.Errorf("failed to copy skill files: %w", err)
+	}
+	return nil
+}
+
+// checkReserved fails if the skill source directory has a top-level entry
+// reserved by psk.
+func checkReserved(sourceDir string) error {
+	entries, err := os.ReadDir(sourceDir)
+	if err != nil {
+		return fmt.Errorf("failed to read skill directory: %w", err)
+	}
+	for _, entry := range entries {
+		if reservedNames[entry.Name()] {
+			return fmt.Errorf("skill directory must not contain %q (reserved by psk)", entry.Name())
+		}
+	}
+	return nil
+}
+
+// AddFunc stores an artifact at {name}/{version}/ whose files are written
+// by populate into an empty temp directory. manifest.json is written after
+// populate returns, the skill files are moved into blobs, and the result
+// is moved into place atomically. If force is true, an existing artifact
+// is replaced. The artifact is locked against other writers until AddFunc
+// returns; it fails with a *LockedError if the lock is not released within
+// the lock timeout.
+func (s *Store) AddFunc(name, version string, manifest Manifest, force bool, populate func(dir string) error) (string, error) {
+	if name == BlobsDir {
+		return "", fmt.Errorf("skill name %q is reserved by the store", name)
+	}
+	unlock, err := s.LockArtifact(name, version)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
 	destDir := filepath.Join(s.root, name, version)
 
 	if !force {
@@ -71,7 +130,7 @@ func (s *Store) Add(name, version, sourceDir string, manifest Manifest, force bo
 	}
 
 	// Write to temp directory first for atomicity
-	tmpDir := destDir + fmt.Sprintf(".tmp.%d", os.Getpid())
+	tmpDir := filepath.Join(s.root, name, tempDirName(version, tempSep))
 	if err := os.MkdirAll(tmpDir, 0o755); err != nil {
 		return "", fmt.Errorf("failed to create temp directory: %w", err)
 	}
@@ -84,9 +143,11 @@ func (s *Store) Add(name, version, sourceDir string, manifest Manifest, force bo
 		}
 	}()
 
-	// Copy skill files from source to temp dir
-	if err := copyDir(sourceDir, tmpDir); err != nil {
-		return "", fmt.Errorf("failed to copy skill files: %w", err)
+	if err := populate(tmpDir); err != nil {
+		return "", err
+	}
+	if err := s.intern(tmpDir); err != nil {
+		return "", err
 	}
 
 	// Write manifest.json
@@ -109,7 +170,147 @@ func (s *Store) Add(name, version, sourceDir string, manifest Manifest, force bo
 	return destDir, nil
 }
 
-// List returns all manifests in the store, sorted by name then version.
+// Writes and removals in progress work in a temp directory of the skill
+// directory named .{version}.tmp.{pid} or .{version}.rm.{pid}. The leading
+// dot keeps the name from ever parsing as a version: {version}.tmp.{pid}
+// is a valid prerelease of its own.
+const (
+	tempSep  = ".tmp."
+	trashSep = ".rm."
+)
+
+// tempDirName returns the name of the temp directory of this process for
+// version, with sep telling a write from a removal.
+func tempDirName(version, sep string) string {
+	return fmt.Sprintf(".%s%s%d", version, sep, os.Getpid())
+}
+
+// isLegacyTemp reports whether the directory path, stored as entry under a
+// skill directory, is the temp directory of a write by an older psk, which
+// named them {version}.tmp.{pid}. Since such a name is also a prerelease
+// version, it is only taken as a temp directory if its manifest.json does
+// not describe that version.
+func isLegacyTemp(path, entry string) bool {
+	if _, _, ok := tempOwner(entry, tempSep); !ok || strings.HasPrefix(entry, ".") {
+		return false
+	}
+	m, err := ReadManifest(filepath.Join(path, "manifest.json"))
+	return err != nil || m.Version != entry
+}
+
+// Remove deletes the artifact at {name}/{version}/ and, if no other
+// version remains, the {name}/ directory. The artifact is renamed out of
+// place before it is deleted so that it disappears from the store
+// atomically. The {name}/ directory is only removed while empty, so a
+// concurrent Add of the same name keeps it. Like AddFunc, Remove locks the
+// artifact.
+func (s *Store) Remove(name, version string) error {
+	unlock, err := s.LockArtifact(name, version)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	nameDir := filepath.Join(s.root, name)
+	trash := filepath.Join(nameDir, tempDirName(version, trashSep))
+	if err := os.Rename(filepath.Join(nameDir, version), trash); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("skill %s@%s not found in store: %w", name, version, fs.ErrNotExist)
+		}
+		return fmt.Errorf("failed to remove %s@%s: %w", name, version, err)
+	}
+	if err := os.RemoveAll(trash); err != nil {
+		return fmt.Errorf("failed to remove %s@%s: %w", name, version, err)
+	}
+	os.Remove(nameDir)
+	return nil
+}
+
+// Versions returns the versions of name in the store, sorted by semver
+// precedence.
+func (s *Store) Versions(name string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(s.root, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read store: %w", err)
+	}
+	var versions []string
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		// Temp directories of in-progress writes have a manifest for
+		// another version name.
+		m, err := ReadManifest(filepath.Join(s.root, name, e.Name(), "manifest.json"))
+		if err != nil || m.Version != e.Name() {
+			continue
+		}
+		versions = append(versions, e.Name())
+	}
+	skill.SortVersions(versions)
+	return versions, nil
+}
+
+// Resolve returns the highest version of name in the store that satisfies
+// c, or "" if none does. Versions that are not valid semver never match.
+func (s *Store) Resolve(name string, c skill.Constraint) (string, error) {
+	versions, err := s.Versions(name)
+	if err != nil {
+		return "", err
+	}
+	for i := len(versions) - 1; i >= 0; i-- {
+		if v, err := skill.ParseVersion(versions[i]); err == nil && c.Match(v) {
+			return versions[i], nil
+		}
+	}
+	return "", nil
+}
+
+// Entry is a {name}/{version} directory of the store.
+type Entry struct {
+	Name    string
+	Version string
+	Path    string
+}
+
+// Entries returns every {name}/{version} directory of the store, whether
+// or not it holds a readable artifact, sorted by name then version. Temp
+// directories of writes and removals in progress are left out.
+func (s *Store) Entries() ([]Entry, error) {
+	entries, err := os.ReadDir(s.root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read store: %w", err)
+	}
+	var result []Entry
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || entry.Name() == BlobsDir {
+			continue
+		}
+		name := entry.Name()
+		versionEntries, err := os.ReadDir(filepath.Join(s.root, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read store: %w", err)
+		}
+		for _, ve := range versionEntries {
+			if !ve.IsDir() || strings.HasPrefix(ve.Name(), ".") {
+				continue
+			}
+			path := filepath.Join(s.root, name, ve.Name())
+			if isLegacyTemp(path, ve.Name()) {
+				continue
+			}
+			result = append(result, Entry{Name: name, Version: ve.Name(), Path: path})
+		}
+	}
+	return result, nil
+}
+
+// List returns all manifests in the store, sorted by name then semver
+// precedence.
 func (s *Store) List() ([]Manifest, error) {
 	var manifests []Manifest
 
@@ -124,7 +325,7 @@ func (s *Store) List() ([]Manifest, error) {
 	}
 
 	for _, entry := range entries {
-		if !entry.IsDir() {
+		if !entry.IsDir() || entry.Name() == BlobsDir {
 			continue
 		}
 		name := entry.Name()
@@ -133,12 +334,12 @@ func (s *Store) List() ([]Manifest, error) {
 			continue
 		}
 		for _, ve := range versionEntries {
-			if !ve.IsDir() {
+			// Dot-prefixed entries are artifacts being removed
+			if !ve.IsDir() || strings.HasPrefix(ve.Name(), ".") {
 				continue
 			}
-			manifestPath := filepath.Join(s.root, name, ve.Name(), "manifest.json")
-			m, err := ReadManifest(manifestPath)
-			if err != nil {
+			m, err := ReadManifest(filepath.Join(s.root, name, ve.Name(), "manifest.json"))
+			if err != nil || isLegacyTemp(filepath.Join(s.root, name, ve.Name()), ve.Name()) {
 				continue
 			}
 			manifests = append(manifests, m)
@@ -149,13 +350,15 @@ func (s *Store) List() ([]Manifest, error) {
 		if manifests[i].Name != manifests[j].Name {
 			return manifests[i].Name < manifests[j].Name
 		}
-		return manifests[i].Version < manifests[j].Version
+		return skill.CompareVersions(manifests[i].Version, manifests[j].Version) < 0
 	})
 
 	return manifests, nil
 }
 
-// copyDir copies all files and directories from src to dst.
+// copyDir copies all files and directories from src to dst. Directories
+// are created 0755 and files 0644, or 0755 if the source is executable, so
+// the copy does not depend on the source's umask or ownership.
 func copyDir(src, dst string) error {
 	entries, err := os.ReadDir(src)
 	if err != nil {
@@ -182,7 +385,8 @@ func copyDir(src, dst string) error {
 	return nil
 }
 
-// copyFile copies a single file from src to dst.
+// copyFile copies a single file from src to dst, keeping only the
+// executable bit of its mode.
 func copyFile(src, dst string) error {
 	in, err := os.Open(src)
 	if err != nil {
@@ -190,7 +394,16 @@ func copyFile(src, dst string) error {
 	}
 	defer in.Close()
 
-	out, err := os.Create(dst)
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	perm := os.FileMode(0o644)
+	if info.Mode().Perm()&0o111 != 0 {
+		perm = 0o755
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
 	if err != nil {
 		return err
 	}
@@ -199,5 +412,8 @@ func copyFile(src, dst string) error {
 	if _, err := io.Copy(out, in); err != nil {
 		return err
 	}
+	if err := out.Chmod(perm); err != nil {
+		return err
+	}
 	return out.Close()
 }
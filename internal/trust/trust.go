@@ -0,0 +1,221 @@
+// Package trust manages the set of public keys whose signatures psk
+// accepts, each bound to a signer identity such as "Name <email>".
+package trust
+
+import (
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/c8ab/provenskills/internal/signing"
+)
+
+// ErrKeyExists is returned by Add when the key is already trusted.
+var ErrKeyExists = errors.New("key is already trusted")
+
+// ErrKeyNotFound is returned when no trusted key matches a query.
+var ErrKeyNotFound = errors.New("no trusted key matches")
+
+// Key is a trusted public key and the identity it speaks for.
+type Key struct {
+	KeyID     string `json:"keyId"`
+	Identity  string `json:"identity"`
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"publicKey"`
+	Added     string `json:"added"`
+}
+
+// Public returns the parsed public key.
+func (k Key) Public() (crypto.PublicKey, error) {
+	return signing.ParsePublicKey([]byte(k.PublicKey))
+}
+
+// Store is a directory of trusted keys, one JSON file per key.
+type Store struct {
+	root string
+}
+
+// New creates a trust Store. If path is empty, it defaults to the
+// PSK_TRUST environment variable or ~/.psk/trust/.
+func New(path string) *Store {
+	if path == "" {
+		path = os.Getenv("PSK_TRUST")
+	}
+	if path == "" {
+		home, _ := os.UserHomeDir()
+		path = filepath.Join(home, ".psk", "trust")
+	}
+	return &Store{root: path}
+}
+
+// Root returns the trust store directory.
+func (s *Store) Root() string {
+	return s.root
+}
+
+// keyPath returns the file holding the key with the given id.
+func (s *Store) keyPath(keyID string) string {
+	return filepath.Join(s.root, strings.TrimPrefix(keyID, "sha256:")+".json")
+}
+
+// Add trusts the PEM public key pubPEM as identity. If force is true, an
+// already trusted key is re-bound to the new identity.
+func (s *Store) Add(pubPEM []byte, identity string, force bool) (Key, error) {
+	identity = strings.TrimSpace(identity)
+	if identity == "" {
+		return Key{}, fmt.Errorf("identity is required")
+	}
+	pub, err := signing.ParsePublicKey(pubPEM)
+	if err != nil {
+		return Key{}, err
+	}
+	algorithm, err := signing.Algorithm(pub)
+	if err != nil {
+		return Key{}, err
+	}
+	keyID, err := signing.KeyID(pub)
+	if err != nil {
+		return Key{}, err
+	}
+	// Re-encode so the stored PEM is canonical.
+	canonical, err := signing.MarshalPublicKey(pub)
+	if err != nil {
+		return Key{}, err
+	}
+
+	path := s.keyPath(keyID)
+	if _, err := os.Stat(path); err == nil && !force {
+		return Key{}, fmt.Errorf("%s: %w", keyID, ErrKeyExists)
+	}
+
+	k := Key{
+		KeyID:     keyID,
+		Identity:  identity,
+		Algorithm: algorithm,
+		PublicKey: string(canonical),
+		Added:     time.Now().UTC().Format(time.RFC3339),
+	}
+	data, err := json.MarshalIndent(k, "", "  ")
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to marshal trusted key: %w", err)
+	}
+	data = append(data, '\n')
+
+	if err := os.MkdirAll(s.root, 0o755); err != nil {
+		return Key{}, fmt.Errorf("failed to create trust store: %w", err)
+	}
+	tmp := fmt.Sprintf("%s.tmp.%d", path, os.Getpid())
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return Key{}, fmt.Errorf("failed to write trusted key: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return Key{}, fmt.Errorf("failed to write trusted key: %w", err)
+	}
+	return k, nil
+}
+
+// Lookup returns the trusted key with the given id. The boolean is false
+// when the key is not trusted.
+func (s *Store) Lookup(keyID string) (Key, bool, error) {
+	if !strings.HasPrefix(keyID, "sha256:") || strings.ContainsAny(keyID, `/\`) {
+		return Key{}, false, nil
+	}
+	k, err := readKey(s.keyPath(keyID))
+	if errors.Is(err, os.ErrNotExist) {
+		return Key{}, false, nil
+	}
+	if err != nil {
+		return Key{}, false, err
+	}
+	if k.KeyID != keyID {
+		return Key{}, false, fmt.Errorf("trusted key file for %s records key id %s", keyID, k.KeyID)
+	}
+	return k, true, nil
+}
+
+// List returns every trusted key ordered by identity, then key id. A
+// missing trust store holds no keys.
+func (s *Store) List() ([]Key, error) {
+	entries, err := os.ReadDir(s.root)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trust store: %w", err)
+	}
+
+	var keys []Key
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		k, err := readKey(filepath.Join(s.root, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Identity != keys[j].Identity {
+			return keys[i].Identity < keys[j].Identity
+		}
+		return keys[i].KeyID < keys[j].KeyID
+	})
+	return keys, nil
+}
+
+// Remove stops trusting the key selected by query, which is a key id, a
+// unique prefix of its hex digest, or an identity held by a single key.
+func (s *Store) Remove(query string) (Key, error) {
+	keys, err := s.List()
+	if err != nil {
+		return Key{}, err
+	}
+
+	var matches []Key
+	hexQuery := strings.TrimPrefix(query, "sha256:")
+	for _, k := range keys {
+		if k.Identity == query || (hexQuery != "" && strings.HasPrefix(strings.TrimPrefix(k.KeyID, "sha256:"), hexQuery)) {
+			matches = append(matches, k)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Key{}, fmt.Errorf("%q: %w", query, ErrKeyNotFound)
+	case 1:
+	default:
+		return Key{}, fmt.Errorf("%q matches %d trusted keys; use a full key id", query, len(matches))
+	}
+
+	if err := os.Remove(s.keyPath(matches[0].KeyID)); err != nil {
+		return Key{}, fmt.Errorf("failed to remove trusted key: %w", err)
+	}
+	return matches[0], nil
+}
+
+// readKey reads and checks a trusted key file.
+func readKey(path string) (Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Key{}, err
+	}
+	var k Key
+	if err := json.Unmarshal(data, &k); err != nil {
+		return Key{}, fmt.Errorf("%s: failed to parse trusted key: %w", filepath.Base(path), err)
+	}
+	pub, err := signing.ParsePublicKey([]byte(k.PublicKey))
+	if err != nil {
+		return Key{}, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	if keyID, err := signing.KeyID(pub); err != nil || keyID != k.KeyID {
+		return Key{}, fmt.Errorf("%s: keyId does not match its public key", filepath.Base(path))
+	}
+	return k, nil
+}
@@ -0,0 +1,78 @@
+package trust
+
+import (
+	"net/mail"
+	"strings"
+
+	"github.com/c8ab/provenskills/internal/signing"
+)
+
+// Signature verification outcomes.
+const (
+	// StatusTrusted means the signature verifies and its key is trusted.
+	StatusTrusted = "trusted"
+	// StatusUntrusted means the signature verifies but its key is not in
+	// the trust store.
+	StatusUntrusted = "untrusted"
+	// StatusInvalid means the signature does not verify against the
+	// artifact digest.
+	StatusInvalid = "invalid"
+)
+
+// Result is the verification outcome of one signature.
+type Result struct {
+	Signature signing.Signature
+	Status    string
+	// Key is the trusted key that made the signature, set when Status is
+	// StatusTrusted.
+	Key *Key
+	// Err explains why a signature is StatusInvalid.
+	Err error
+}
+
+// Verify checks each signature against the artifact digest and looks up
+// its key in the trust store.
+func (s *Store) Verify(digest string, sigs []signing.Signature) ([]Result, error) {
+	results := make([]Result, 0, len(sigs))
+	for _, sig := range sigs {
+		r := Result{Signature: sig}
+		if err := sig.Verify(digest); err != nil {
+			r.Status, r.Err = StatusInvalid, err
+			results = append(results, r)
+			continue
+		}
+		k, ok, err := s.Lookup(sig.KeyID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			r.Status, r.Key = StatusTrusted, &k
+		} else {
+			r.Status = StatusUntrusted
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// MatchesMaintainer reports whether a signer identity names the same
+// person as a manifest maintainer. When both carry an email address in
+// "Name <email>" form the addresses are compared case-insensitively;
+// otherwise the whole strings must be equal.
+func MatchesMaintainer(identity, maintainer string) bool {
+	a, b := emailOf(identity), emailOf(maintainer)
+	if a != "" && b != "" {
+		return strings.EqualFold(a, b)
+	}
+	return strings.TrimSpace(identity) == strings.TrimSpace(maintainer)
+}
+
+// emailOf returns the address in "Name <email>" or a bare address, or ""
+// when s is not an address.
+func emailOf(s string) string {
+	addr, err := mail.ParseAddress(strings.TrimSpace(s))
+	if err != nil {
+		return ""
+	}
+	return addr.Address
+}
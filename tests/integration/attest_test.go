@@ -0,0 +1,142 @@
+package integration
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePredicate writes a JSON predicate file and returns its path.
+func writePredicate(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "predicate.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAttestAndList(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir, "PSK_TRUST=" + t.TempDir()}
+	buildValidSkill(t, bin, storeDir)
+	prefix := generateKey(t, bin, "ed25519")
+
+	for _, predicateType := range []string{
+		"https://example.com/security-reviewed/v1",
+		"https://example.com/eval-passed/v1",
+	} {
+		stdout, stderr, exitCode := runPSK(t, bin, env,
+			"attest", "valid-skill@1.0.0",
+			"--predicate", writePredicate(t, `{"result":"pass"}`),
+			"--type", predicateType, "--key", prefix+".key",
+		)
+		if exitCode != 0 {
+			t.Fatalf("attest failed with exit code %d\nstderr: %s", exitCode, stderr)
+		}
+		if !strings.Contains(stdout, "Attested skill: valid-skill@1.0.0") {
+			t.Errorf("expected stdout to contain 'Attested skill: valid-skill@1.0.0', got:\n%s", stdout)
+		}
+	}
+
+	stdout, stderr, exitCode := runPSK(t, bin, env, "attestations", "valid-skill@1.0.0")
+	if exitCode != 0 {
+		t.Fatalf("attestations failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	for _, want := range []string{"security-reviewed/v1", "eval-passed/v1", "untrusted"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected stdout to contain %q, got:\n%s", want, stdout)
+		}
+	}
+
+	_, stderr, exitCode = runPSK(t, bin, env,
+		"trust", "add", prefix+".pub", "--identity", "Reviewer <review@example.com>",
+	)
+	if exitCode != 0 {
+		t.Fatalf("trust add failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	stdout, _, exitCode = runPSK(t, bin, env,
+		"attestations", "valid-skill@1.0.0", "--type", "https://example.com/eval-passed/v1", "--json",
+	)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	var result []struct {
+		PredicateType string `json:"predicateType"`
+		Current       bool   `json:"current"`
+		Signers       []struct {
+			Status   string `json:"status"`
+			Identity string `json:"identity"`
+		} `json:"signers"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, stdout)
+	}
+	if len(result) != 1 || !result[0].Current || len(result[0].Signers) != 1 ||
+		result[0].Signers[0].Status != "trusted" || result[0].Signers[0].Identity != "Reviewer <review@example.com>" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestAttestRejectsInvalidInput(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	buildValidSkill(t, bin, storeDir)
+	prefix := generateKey(t, bin, "ed25519")
+
+	tests := []struct {
+		name      string
+		predicate string
+		typ       string
+	}{
+		{"relative type", `{"result":"pass"}`, "security-reviewed"},
+		{"array predicate", `["pass"]`, "https://example.com/security-reviewed/v1"},
+		{"invalid json", `{"result":`, "https://example.com/security-reviewed/v1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, exitCode := runPSK(t, bin,
+				[]string{"PSK_STORE=" + storeDir},
+				"attest", "valid-skill@1.0.0",
+				"--predicate", writePredicate(t, tc.predicate),
+				"--type", tc.typ, "--key", prefix+".key",
+			)
+			if exitCode != 2 {
+				t.Errorf("expected exit code 2, got %d", exitCode)
+			}
+		})
+	}
+}
+
+func TestAttestationsTravelWithExportAndImport(t *testing.T) {
+	bin := buildPSK(t)
+	srcStore := t.TempDir()
+	prefix := buildWithProvenance(t, bin, srcStore)
+
+	_, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + srcStore},
+		"attest", "valid-skill@1.0.0",
+		"--predicate", writePredicate(t, `{"result":"pass"}`),
+		"--type", "https://example.com/security-reviewed/v1", "--key", prefix+".key",
+	)
+	if exitCode != 0 {
+		t.Fatalf("attest failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+
+	tarPath := filepath.Join(t.TempDir(), "skill.tar")
+	if _, stderr, exitCode := runPSK(t, bin, []string{"PSK_STORE=" + srcStore}, "export", "valid-skill@1.0.0", tarPath); exitCode != 0 {
+		t.Fatalf("export failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+
+	dstStore := t.TempDir()
+	stdout, stderr, exitCode := runPSK(t, bin, []string{"PSK_STORE=" + dstStore}, "import", tarPath, "--json")
+	if exitCode != 0 {
+		t.Fatalf("import failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, `"attestations": 2`) {
+		t.Errorf("expected import to report 2 attestations, got:\n%s", stdout)
+	}
+}
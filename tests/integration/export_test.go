@@ -0,0 +1,93 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// buildValidSkill builds the valid-skill fixture into store.
+func buildValidSkill(t *testing.T, bin, store string) {
+	t.Helper()
+	_, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + store},
+		"build", filepath.Join(testdataDir(t), "valid-skill"),
+		"--maintainer", "Test <test@example.com>",
+	)
+	if exitCode != 0 {
+		t.Fatalf("build failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+}
+
+func TestExportLayoutDirectory(t *testing.T) {
+	bin := buildPSK(t)
+	store := t.TempDir()
+	buildValidSkill(t, bin, store)
+
+	dest := filepath.Join(t.TempDir(), "layout")
+	stdout, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + store},
+		"export", "valid-skill@1.0.0", "--format", "oci-layout", dest,
+	)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "Exported skill: valid-skill@1.0.0") {
+		t.Errorf("expected stdout to contain 'Exported skill: valid-skill@1.0.0', got:\n%s", stdout)
+	}
+
+	for _, name := range []string{"oci-layout", "index.json", "blobs/sha256"} {
+		if _, err := os.Stat(filepath.Join(dest, name)); err != nil {
+			t.Errorf("%s not found in layout: %v", name, err)
+		}
+	}
+}
+
+func TestExportLayoutTarball(t *testing.T) {
+	bin := buildPSK(t)
+	store := t.TempDir()
+	buildValidSkill(t, bin, store)
+
+	dest := filepath.Join(t.TempDir(), "valid-skill.tar")
+	_, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + store},
+		"export", "valid-skill@1.0.0", dest,
+	)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+	info, err := os.Stat(dest)
+	if err != nil {
+		t.Fatalf("tarball not written: %v", err)
+	}
+	if info.Size() == 0 {
+		t.Error("tarball is empty")
+	}
+}
+
+func TestExportUnsupportedFormat(t *testing.T) {
+	bin := buildPSK(t)
+	store := t.TempDir()
+	buildValidSkill(t, bin, store)
+
+	_, _, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + store},
+		"export", "valid-skill@1.0.0", "--format", "zip", t.TempDir(),
+	)
+	if exitCode != 2 {
+		t.Fatalf("expected exit code 2, got %d", exitCode)
+	}
+}
+
+func TestExportMissingSkill(t *testing.T) {
+	bin := buildPSK(t)
+
+	_, _, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + t.TempDir()},
+		"export", "valid-skill@1.0.0", t.TempDir(),
+	)
+	if exitCode != 4 {
+		t.Fatalf("expected exit code 4, got %d", exitCode)
+	}
+}
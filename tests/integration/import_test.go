@@ -0,0 +1,199 @@
+package integration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/oci"
+)
+
+// exportValidSkill builds valid-skill into a fresh store and exports it
+// to dest.
+func exportValidSkill(t *testing.T, bin, dest string) {
+	t.Helper()
+	store := t.TempDir()
+	buildValidSkill(t, bin, store)
+	_, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + store},
+		"export", "valid-skill@1.0.0", dest,
+	)
+	if exitCode != 0 {
+		t.Fatalf("export failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+}
+
+func TestImportLayoutDirectory(t *testing.T) {
+	bin := buildPSK(t)
+	layout := filepath.Join(t.TempDir(), "layout")
+	exportValidSkill(t, bin, layout)
+
+	store := t.TempDir()
+	stdout, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + store},
+		"import", layout,
+	)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "Imported skill: valid-skill@1.0.0") {
+		t.Errorf("expected stdout to contain 'Imported skill: valid-skill@1.0.0', got:\n%s", stdout)
+	}
+	for _, name := range []string{"SKILL.md", "manifest.json"} {
+		if _, err := os.Stat(filepath.Join(store, "valid-skill", "1.0.0", name)); err != nil {
+			t.Errorf("%s not found in store: %v", name, err)
+		}
+	}
+}
+
+func TestImportTarball(t *testing.T) {
+	bin := buildPSK(t)
+	tarball := filepath.Join(t.TempDir(), "valid-skill.tar")
+	exportValidSkill(t, bin, tarball)
+
+	store := t.TempDir()
+	_, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + store},
+		"import", tarball,
+	)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+
+	// Importing again conflicts unless --force is given
+	_, _, exitCode = runPSK(t, bin, []string{"PSK_STORE=" + store}, "import", tarball)
+	if exitCode != 3 {
+		t.Fatalf("second import expected exit code 3, got %d", exitCode)
+	}
+	_, _, exitCode = runPSK(t, bin, []string{"PSK_STORE=" + store}, "import", tarball, "--force")
+	if exitCode != 0 {
+		t.Fatalf("forced import expected exit code 0, got %d", exitCode)
+	}
+}
+
+func TestImportRejectsInvalidSkill(t *testing.T) {
+	bin := buildPSK(t)
+	source := t.TempDir()
+	buildValidSkill(t, bin, source)
+
+	// Tamper with the stored SKILL.md so it no longer validates
+	skillMD := filepath.Join(source, "valid-skill", "1.0.0", "SKILL.md")
+	if err := os.WriteFile(skillMD, []byte("---\nname: valid-skill\n---\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	layout := filepath.Join(t.TempDir(), "layout")
+	if _, _, exitCode := runPSK(t, bin, []string{"PSK_STORE=" + source}, "export", "valid-skill@1.0.0", layout); exitCode != 0 {
+		t.Fatalf("export failed with exit code %d", exitCode)
+	}
+
+	store := t.TempDir()
+	_, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + store},
+		"import", layout,
+	)
+	if exitCode != 2 {
+		t.Fatalf("expected exit code 2, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stderr, "validation failed") {
+		t.Errorf("expected stderr to contain 'validation failed', got:\n%s", stderr)
+	}
+	if _, err := os.Stat(filepath.Join(store, "valid-skill", "1.0.0")); !os.IsNotExist(err) {
+		t.Error("invalid skill was registered in the store")
+	}
+}
+
+func TestImportRejectsMismatchedArtifact(t *testing.T) {
+	bin := buildPSK(t)
+	a, m := packScriptedSkill(t, bin)
+	hidden := m
+	hidden.Contents.Scripts = nil
+
+	tests := []struct {
+		name     string
+		artifact *oci.Artifact
+		want     string
+	}{
+		// Manifest annotations are optional, but without them the stored
+		// skill packs to another digest than the one signed
+		{"no annotations", repackArtifact(t, a, m, nil), "digest: artifact " + repackArtifact(t, a, m, nil).Digest()},
+		{"hidden scripts", repackArtifact(t, a, hidden, oci.Annotations(hidden)), "contents: added: scripts/render.js"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := filepath.Join(t.TempDir(), "layout")
+			if err := oci.WriteLayout(layout, tt.artifact, "1.0.0", nil); err != nil {
+				t.Fatal(err)
+			}
+
+			store := t.TempDir()
+			_, stderr, exitCode := runPSK(t, bin, []string{"PSK_STORE=" + store}, "import", layout)
+			if exitCode != 2 {
+				t.Fatalf("expected exit code 2, got %d\nstderr: %s", exitCode, stderr)
+			}
+			if !strings.Contains(stderr, tt.want) {
+				t.Errorf("expected stderr to contain %q, got:\n%s", tt.want, stderr)
+			}
+			if _, err := os.Stat(filepath.Join(store, "scripted-skill", "1.0.0")); !os.IsNotExist(err) {
+				t.Error("mismatched artifact was stored")
+			}
+		})
+	}
+}
+
+func TestImportNotALayout(t *testing.T) {
+	bin := buildPSK(t)
+
+	_, _, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + t.TempDir()},
+		"import", t.TempDir(),
+	)
+	if exitCode != 4 {
+		t.Fatalf("expected exit code 4, got %d", exitCode)
+	}
+}
+
+func TestImportMultipleSkills(t *testing.T) {
+	bin := buildPSK(t)
+	validStore := t.TempDir()
+	buildValidSkill(t, bin, validStore)
+	valid, err := oci.Pack(filepath.Join(validStore, "valid-skill", "1.0.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scripted, m := packScriptedSkill(t, bin)
+	hidden := m
+	hidden.Contents.Scripts = nil
+
+	writeLayout := func(artifacts ...*oci.Artifact) string {
+		layout := filepath.Join(t.TempDir(), "layout")
+		for i, a := range artifacts {
+			if err := oci.WriteLayout(layout, a, fmt.Sprintf("skill-%d", i), nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return layout
+	}
+
+	// A conflict on the second skill is found before the first is written
+	store := t.TempDir()
+	env := []string{"PSK_STORE=" + store}
+	if _, stderr, exitCode := runPSK(t, bin, env, "import", writeLayout(scripted)); exitCode != 0 {
+		t.Fatalf("import failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	_, stderr, exitCode := runPSK(t, bin, env, "import", writeLayout(valid, scripted))
+	if exitCode != 3 || !strings.Contains(stderr, "scripted-skill@1.0.0 already exists") {
+		t.Errorf("expected exit code 3 for the existing skill, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(store, "valid-skill", "1.0.0")); !os.IsNotExist(err) {
+		t.Error("expected nothing to be imported on a conflict")
+	}
+
+	// A skill that fails validation reports those imported before it
+	store = t.TempDir()
+	_, stderr, exitCode = runPSK(t, bin, []string{"PSK_STORE=" + store}, "import", writeLayout(valid, repackArtifact(t, scripted, hidden, oci.Annotations(hidden))))
+	if exitCode != 2 || !strings.Contains(stderr, "Imported before the failure:\n  valid-skill@1.0.0\n") {
+		t.Errorf("expected exit code 2 naming the imported skill, got %d\nstderr: %s", exitCode, stderr)
+	}
+}
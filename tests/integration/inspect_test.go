@@ -0,0 +1,109 @@
+package integration
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir, "PSK_TRUST=" + t.TempDir()}
+
+	skillDir := filepath.Join(t.TempDir(), "audited-skill")
+	if err := os.MkdirAll(filepath.Join(skillDir, "scripts"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	skillMD := "---\nname: audited-skill\ndescription: A skill to inspect.\nlicense: MIT\ncompatibility: Requires git\nallowed-tools: Read Bash(git status:*)\nmetadata:\n  version: \"1.2.0\"\n  author: \"test-author\"\n---\n\n# Audited\n"
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(skillMD), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(skillDir, "scripts", "run.sh"), []byte("#!/bin/sh\necho ok\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, stderr, exitCode := runPSK(t, bin, env, "build", skillDir, "--maintainer", "Test <test@example.com>"); exitCode != 0 {
+		t.Fatalf("build failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	prefix := generateKey(t, bin, "ed25519")
+	if _, stderr, exitCode := runPSK(t, bin, env, "sign", "audited-skill@1.2.0", "--key", prefix+".key"); exitCode != 0 {
+		t.Fatalf("sign failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	if _, stderr, exitCode := runPSK(t, bin, env, "trust", "add", prefix+".pub", "--identity", "Test <test@example.com>"); exitCode != 0 {
+		t.Fatalf("trust add failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+
+	stdout, stderr, exitCode := runPSK(t, bin, env, "inspect", "audited-skill")
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+	for _, want := range []string{
+		"Skill: audited-skill@1.2.0",
+		filepath.Join(storeDir, "audited-skill", "1.2.0") + "/",
+		"license:        MIT",
+		"compatibility:  Requires git",
+		"allowed-tools:  Read Bash(git status:*)",
+		"maintainer:     Test <test@example.com>",
+		"scripts/run.sh",
+		"(executable)",
+		"trusted",
+		"Test <test@example.com>",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, stdout)
+		}
+	}
+
+	stdout, _, exitCode = runPSK(t, bin, env, "inspect", "audited-skill@1.2.0", "--json")
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	var result struct {
+		Digest   string `json:"digest"`
+		Manifest struct {
+			SourceHash string `json:"sourceHash"`
+			Contents   struct {
+				Scripts []struct {
+					Path   string `json:"path"`
+					Digest string `json:"digest"`
+				} `json:"scripts"`
+			} `json:"contents"`
+		} `json:"manifest"`
+		Frontmatter struct {
+			License      string `json:"license"`
+			AllowedTools string `json:"allowed-tools"`
+		} `json:"frontmatter"`
+		Signatures []struct {
+			Status   string `json:"status"`
+			Identity string `json:"identity"`
+		} `json:"signatures"`
+		Attestations []interface{} `json:"attestations"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, stdout)
+	}
+	if !strings.HasPrefix(result.Digest, "sha256:") || !strings.HasPrefix(result.Manifest.SourceHash, "sha256:") {
+		t.Errorf("expected digests, got %+v", result)
+	}
+	if len(result.Manifest.Contents.Scripts) != 1 || result.Manifest.Contents.Scripts[0].Path != "scripts/run.sh" {
+		t.Errorf("unexpected contents: %+v", result.Manifest.Contents)
+	}
+	if result.Frontmatter.License != "MIT" || result.Frontmatter.AllowedTools != "Read Bash(git status:*)" {
+		t.Errorf("unexpected frontmatter: %+v", result.Frontmatter)
+	}
+	if len(result.Signatures) != 1 || result.Signatures[0].Status != "trusted" || result.Signatures[0].Identity != "Test <test@example.com>" {
+		t.Errorf("unexpected signatures: %+v", result.Signatures)
+	}
+	if result.Attestations == nil {
+		t.Error("expected an empty attestations array, got null")
+	}
+
+	if _, _, exitCode := runPSK(t, bin, env, "inspect", "audited-skill@2.0.0"); exitCode != 4 {
+		t.Errorf("expected exit code 4 for a missing version, got %d", exitCode)
+	}
+	if _, _, exitCode := runPSK(t, bin, env, "inspect"); exitCode != 2 {
+		t.Errorf("expected exit code 2 without a skill, got %d", exitCode)
+	}
+}
@@ -142,3 +142,50 @@ metadata:
 	}
 	return skillDir
 }
+
+func TestSkillVersionResolution(t *testing.T) {
+	bin := buildPSK(t)
+	store := t.TempDir()
+	env := []string{"PSK_STORE=" + store}
+	for _, v := range []string{"1.9.0", "1.10.0", "2.0.0-rc.1+build.5"} {
+		if _, stderr, exitCode := runPSK(t, bin, env, "build", createTempSkill(t, "my-skill", v, "author"), "--maintainer", "Test <test@example.com>"); exitCode != 0 {
+			t.Fatalf("build %s failed with exit code %d\nstderr: %s", v, exitCode, stderr)
+		}
+	}
+
+	stdout, _, _ := runPSK(t, bin, env, "list")
+	if i, j := strings.Index(stdout, "1.9.0"), strings.Index(stdout, "1.10.0"); i < 0 || j < i {
+		t.Errorf("expected 1.9.0 to be listed before 1.10.0, got:\n%s", stdout)
+	}
+
+	for query, want := range map[string]string{
+		"my-skill":                    "1.10.0",
+		"my-skill@^1.2":               "1.10.0",
+		"my-skill@~1.9":               "1.9.0",
+		"my-skill@>=1.0 <2":           "1.10.0",
+		"my-skill@2.0.0-rc.1+build.5": "2.0.0-rc.1+build.5",
+	} {
+		stdout, stderr, exitCode := runPSK(t, bin, env, "verify", query)
+		if exitCode != 0 || !strings.Contains(stdout, "Verified skill: my-skill@"+want+"\n") {
+			t.Errorf("%s: expected my-skill@%s, got exit code %d\nstdout: %s\nstderr: %s", query, want, exitCode, stdout, stderr)
+		}
+	}
+
+	_, stderr, exitCode := runPSK(t, bin, env, "verify", "my-skill@^3")
+	if exitCode != 4 || !strings.Contains(stderr, `no version of my-skill in store matches "^3"`) {
+		t.Errorf("expected exit code 4 for an unmatched range, got %d\nstderr: %s", exitCode, stderr)
+	}
+	for _, query := range []string{"my-skill@^1.a", "my-skill@^1.0 ||"} {
+		if _, _, exitCode := runPSK(t, bin, env, "verify", query); exitCode != 2 {
+			t.Errorf("%s: expected exit code 2 for an invalid range, got %d", query, exitCode)
+		}
+	}
+
+	if _, stderr, exitCode := runPSK(t, bin, env, "build", createTempSkill(t, "beta-skill", "0.1.0-beta.2", "author"), "--maintainer", "Test <test@example.com>"); exitCode != 0 {
+		t.Fatalf("build failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	_, stderr, exitCode = runPSK(t, bin, env, "verify", "beta-skill")
+	if exitCode != 4 || !strings.Contains(stderr, "only prerelease versions of beta-skill are in store; specify one, such as beta-skill@0.1.0-beta.2") {
+		t.Errorf("expected exit code 4 naming the prerelease, got %d\nstderr: %s", exitCode, stderr)
+	}
+}
@@ -0,0 +1,149 @@
+package integration
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/oci/registrytest"
+)
+
+// writePolicy writes a policy file and returns its path.
+func writePolicy(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPolicyCheck(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir, "PSK_TRUST=" + t.TempDir()}
+	prefix := signValidSkill(t, bin, storeDir, "ed25519")
+
+	strict := writePolicy(t, "signers:\n  require: true\nlicenses:\n  allow: [MIT]\nmaintainers:\n  allow: [\"*@example.com\"]\n")
+	_, stderr, exitCode := runPSK(t, bin, env, "policy", "check", "valid-skill@1.0.0", "--policy", strict)
+	if exitCode != 10 {
+		t.Fatalf("expected exit code 10, got %d\nstderr: %s", exitCode, stderr)
+	}
+	for _, want := range []string{"policy denied valid-skill@1.0.0", "signatures: no valid signature by a trusted key [signers.require]", "license: no license declared [licenses.allow]"} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("expected stderr to contain %q, got:\n%s", want, stderr)
+		}
+	}
+	if strings.Contains(stderr, "maintainer:") {
+		t.Errorf("expected the maintainer rule to pass, got:\n%s", stderr)
+	}
+
+	_, stderr, exitCode = runPSK(t, bin, env, "policy", "check", "valid-skill@1.0.0", "--policy", strict, "--json")
+	if exitCode != 10 {
+		t.Fatalf("expected exit code 10, got %d", exitCode)
+	}
+	var result struct {
+		Allowed    bool `json:"allowed"`
+		Violations []struct {
+			Rule  string `json:"rule"`
+			Field string `json:"field"`
+		} `json:"violations"`
+	}
+	if err := json.Unmarshal([]byte(stderr), &result); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, stderr)
+	}
+	if result.Allowed || len(result.Violations) != 2 || result.Violations[1].Field != "license" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	if _, stderr, exitCode := runPSK(t, bin, env, "trust", "add", prefix+".pub", "--identity", "Test <test@example.com>"); exitCode != 0 {
+		t.Fatalf("trust add failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	signed := writePolicy(t, "signers:\n  identities: [\"*@example.com\"]\n  require-maintainer: true\n")
+	stdout, stderr, exitCode := runPSK(t, bin, append(env, "PSK_POLICY="+signed), "policy", "check", "valid-skill@1.0.0")
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "Policy allows skill: valid-skill@1.0.0") || !strings.Contains(stdout, signed) {
+		t.Errorf("unexpected output:\n%s", stdout)
+	}
+}
+
+func TestPolicyCheckErrors(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir, "HOME=" + t.TempDir()}
+	buildValidSkill(t, bin, storeDir)
+
+	if _, _, exitCode := runPSK(t, bin, env, "policy", "check", "valid-skill@1.0.0"); exitCode != 4 {
+		t.Errorf("expected exit code 4 without a policy, got %d", exitCode)
+	}
+	if _, _, exitCode := runPSK(t, bin, env, "policy", "check", "valid-skill@1.0.0", "--policy", filepath.Join(t.TempDir(), "missing.yaml")); exitCode != 4 {
+		t.Errorf("expected exit code 4 for a missing policy file, got %d", exitCode)
+	}
+	typo := writePolicy(t, "licences:\n  allow: [MIT]\n")
+	_, stderr, exitCode := runPSK(t, bin, env, "policy", "check", "valid-skill@1.0.0", "--policy", typo)
+	if exitCode != 2 || !strings.Contains(stderr, "licences") {
+		t.Errorf("expected exit code 2 naming the unknown key, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if _, _, exitCode := runPSK(t, bin, env, "policy", "lint"); exitCode != 2 {
+		t.Errorf("expected exit code 2 for an unknown subcommand, got %d", exitCode)
+	}
+}
+
+func TestImportDeniedByPolicy(t *testing.T) {
+	bin := buildPSK(t)
+	layout := filepath.Join(t.TempDir(), "layout")
+	exportValidSkill(t, bin, layout)
+
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir, "PSK_POLICY=" + writePolicy(t, "maintainers:\n  deny: [\"*@example.com\"]\n")}
+	_, stderr, exitCode := runPSK(t, bin, env, "import", layout)
+	if exitCode != 10 {
+		t.Fatalf("expected exit code 10, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stderr, "maintainer: \"Test <test@example.com>\" is denied by \"*@example.com\" [maintainers.deny]") {
+		t.Errorf("expected the maintainer violation, got:\n%s", stderr)
+	}
+	if _, err := os.Stat(filepath.Join(storeDir, "valid-skill", "1.0.0")); !os.IsNotExist(err) {
+		t.Errorf("expected denied skill to be absent from the store, got %v", err)
+	}
+}
+
+func TestPullDeniedByPolicy(t *testing.T) {
+	bin := buildPSK(t)
+	reg := registrytest.New()
+	defer reg.Close()
+	pushValidSkill(t, bin, reg)
+
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir, "PSK_TRUST=" + t.TempDir()}
+	ref := reg.Host() + "/skills/valid-skill:1.0.0"
+
+	denied := append(env, "PSK_POLICY="+writePolicy(t, "signers:\n  require: true\n"))
+	_, stderr, exitCode := runPSK(t, bin, denied, "pull", ref)
+	if exitCode != 10 {
+		t.Fatalf("expected exit code 10, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stderr, "policy denied "+ref) || !strings.Contains(stderr, "[signers.require]") {
+		t.Errorf("expected the signer violation, got:\n%s", stderr)
+	}
+	entries, err := os.ReadDir(storeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Name() == "valid-skill" {
+			if versions, _ := os.ReadDir(filepath.Join(storeDir, e.Name())); len(versions) > 0 {
+				t.Errorf("expected denied skill to leave nothing in the store, found %v", versions)
+			}
+		}
+	}
+
+	allowed := append(env, "PSK_POLICY="+writePolicy(t, "maintainers:\n  allow: [\"*@example.com\"]\n"))
+	if _, stderr, exitCode := runPSK(t, bin, allowed, "pull", ref); exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+}
@@ -0,0 +1,176 @@
+package integration
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/attest"
+	"github.com/c8ab/provenskills/internal/intoto"
+	"github.com/c8ab/provenskills/internal/oci"
+	"github.com/c8ab/provenskills/internal/oci/registrytest"
+	"github.com/c8ab/provenskills/internal/signing"
+)
+
+// buildWithProvenance builds the valid-skill fixture into storeDir with
+// signed provenance and returns the key path prefix.
+func buildWithProvenance(t *testing.T, bin, storeDir string) string {
+	t.Helper()
+	prefix := generateKey(t, bin, "ed25519")
+	_, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + storeDir},
+		"build", filepath.Join(testdataDir(t), "valid-skill"),
+		"--maintainer", "Test <test@example.com>",
+		"--provenance", "--key", prefix+".key",
+		"--builder-id", "https://ci.example.com/builder",
+	)
+	if exitCode != 0 {
+		t.Fatalf("build failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	return prefix
+}
+
+// storedProvenance returns the provenance attestations stored with the
+// artifact in dir that describe its current digest.
+func storedProvenance(t *testing.T, dir string) []attest.Attestation {
+	t.Helper()
+	atts, err := attest.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	artifact, err := oci.Pack(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return attest.Filter(atts, intoto.PredicateSLSAProvenance, artifact.Digest())
+}
+
+func TestBuildWithProvenance(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	prefix := buildWithProvenance(t, bin, storeDir)
+
+	dir := filepath.Join(storeDir, "valid-skill", "1.0.0")
+	atts := storedProvenance(t, dir)
+	if len(atts) != 1 {
+		t.Fatalf("expected 1 provenance attestation for the stored artifact, got %d", len(atts))
+	}
+	pub, err := signing.LoadPublicKey(prefix + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := atts[0].Envelope.Verify(pub); err != nil {
+		t.Errorf("provenance envelope does not verify: %v", err)
+	}
+
+	var p intoto.Provenance
+	if err := json.Unmarshal(atts[0].Statement.Predicate, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.RunDetails.Builder.ID != "https://ci.example.com/builder" {
+		t.Errorf("unexpected builder id %q", p.RunDetails.Builder.ID)
+	}
+	if p.BuildDefinition.ExternalParameters["maintainer"] != "Test <test@example.com>" {
+		t.Errorf("unexpected externalParameters: %v", p.BuildDefinition.ExternalParameters)
+	}
+
+	// Provenance lives outside the skill files
+	_, stderr, exitCode := runPSK(t, bin, []string{"PSK_STORE=" + storeDir}, "verify", "valid-skill@1.0.0")
+	if exitCode != 0 {
+		t.Errorf("expected verify to pass with provenance stored, got %d\nstderr: %s", exitCode, stderr)
+	}
+}
+
+func TestBuildProvenanceRequiresKey(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+
+	_, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + storeDir},
+		"build", filepath.Join(testdataDir(t), "valid-skill"),
+		"--maintainer", "Test <test@example.com>", "--provenance",
+	)
+	if exitCode != 2 {
+		t.Errorf("expected exit code 2, got %d", exitCode)
+	}
+	if !strings.Contains(stderr, "--key") {
+		t.Errorf("expected stderr to mention --key, got:\n%s", stderr)
+	}
+
+	_, _, exitCode = runPSK(t, bin,
+		[]string{"PSK_STORE=" + storeDir},
+		"build", filepath.Join(testdataDir(t), "valid-skill"),
+		"--maintainer", "Test <test@example.com>", "--provenance",
+		"--key", filepath.Join(t.TempDir(), "missing.key"),
+	)
+	if exitCode != 4 {
+		t.Errorf("expected exit code 4 for missing key, got %d", exitCode)
+	}
+	if _, _, exitCode := runPSK(t, bin, []string{"PSK_STORE=" + storeDir}, "verify", "valid-skill@1.0.0"); exitCode == 0 {
+		t.Error("expected no artifact to be stored when the key cannot be loaded")
+	}
+}
+
+func TestProvenanceTravelsWithExportAndImport(t *testing.T) {
+	bin := buildPSK(t)
+	srcStore := t.TempDir()
+	buildWithProvenance(t, bin, srcStore)
+
+	tarPath := filepath.Join(t.TempDir(), "skill.tar")
+	_, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + srcStore},
+		"export", "valid-skill@1.0.0", tarPath,
+	)
+	if exitCode != 0 {
+		t.Fatalf("export failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+
+	dstStore := t.TempDir()
+	stdout, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + dstStore},
+		"import", tarPath, "--json",
+	)
+	if exitCode != 0 {
+		t.Fatalf("import failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, `"attestations": 1`) {
+		t.Errorf("expected import to report 1 attestation, got:\n%s", stdout)
+	}
+	if atts := storedProvenance(t, filepath.Join(dstStore, "valid-skill", "1.0.0")); len(atts) != 1 {
+		t.Errorf("expected imported provenance, got %d attestations", len(atts))
+	}
+}
+
+func TestProvenanceTravelsWithPushAndPull(t *testing.T) {
+	bin := buildPSK(t)
+	reg := registrytest.New()
+	defer reg.Close()
+	reg.DisableReferrersAPI = true
+
+	srcStore := t.TempDir()
+	buildWithProvenance(t, bin, srcStore)
+
+	stdout, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + srcStore},
+		"push", "valid-skill@1.0.0", reg.Host()+"/skills/valid-skill:1.0.0", "--json",
+	)
+	if exitCode != 0 {
+		t.Fatalf("push failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, `"attestations": 1`) {
+		t.Errorf("expected push to report 1 attestation, got:\n%s", stdout)
+	}
+
+	dstStore := t.TempDir()
+	_, stderr, exitCode = runPSK(t, bin,
+		[]string{"PSK_STORE=" + dstStore},
+		"pull", reg.Host()+"/skills/valid-skill:1.0.0",
+	)
+	if exitCode != 0 {
+		t.Fatalf("pull failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	if atts := storedProvenance(t, filepath.Join(dstStore, "valid-skill", "1.0.0")); len(atts) != 1 {
+		t.Errorf("expected pulled provenance, got %d attestations", len(atts))
+	}
+}
@@ -0,0 +1,218 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/oci"
+	"github.com/c8ab/provenskills/internal/oci/registrytest"
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+// pushValidSkill builds the valid-skill fixture into a fresh store and
+// pushes it to reg under skills/valid-skill:1.0.0.
+func pushValidSkill(t *testing.T, bin string, reg *registrytest.Registry) {
+	t.Helper()
+	store := t.TempDir()
+
+	_, _, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + store},
+		"build", filepath.Join(testdataDir(t), "valid-skill"),
+		"--maintainer", "Test <test@example.com>",
+	)
+	if exitCode != 0 {
+		t.Fatalf("build failed with exit code %d", exitCode)
+	}
+
+	_, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + store},
+		"push", "valid-skill@1.0.0", reg.Host()+"/skills/valid-skill:1.0.0",
+	)
+	if exitCode != 0 {
+		t.Fatalf("push failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+}
+
+func TestPullSuccessful(t *testing.T) {
+	bin := buildPSK(t)
+	reg := registrytest.New()
+	defer reg.Close()
+	pushValidSkill(t, bin, reg)
+
+	store := t.TempDir()
+	stdout, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + store},
+		"pull", reg.Host()+"/skills/valid-skill:1.0.0",
+	)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "Pulled skill: valid-skill@1.0.0") {
+		t.Errorf("expected stdout to contain 'Pulled skill: valid-skill@1.0.0', got:\n%s", stdout)
+	}
+
+	want, err := os.ReadFile(filepath.Join(testdataDir(t), "valid-skill", "SKILL.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(store, "valid-skill", "1.0.0", "SKILL.md"))
+	if err != nil {
+		t.Fatalf("SKILL.md not found in store: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("pulled SKILL.md differs from source")
+	}
+
+	var manifest map[string]interface{}
+	data, err := os.ReadFile(filepath.Join(store, "valid-skill", "1.0.0", "manifest.json"))
+	if err != nil {
+		t.Fatalf("manifest.json not found in store: %v", err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("manifest.json is not valid JSON: %v", err)
+	}
+	if manifest["maintainer"] != "Test <test@example.com>" {
+		t.Errorf("expected maintainer to be preserved, got %v", manifest["maintainer"])
+	}
+
+	// The pulled skill shows up in list
+	stdout, _, _ = runPSK(t, bin, []string{"PSK_STORE=" + store}, "list")
+	if !strings.Contains(stdout, "valid-skill") {
+		t.Errorf("expected list to contain 'valid-skill', got:\n%s", stdout)
+	}
+}
+
+func TestPullNotFound(t *testing.T) {
+	bin := buildPSK(t)
+	reg := registrytest.New()
+	defer reg.Close()
+
+	_, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + t.TempDir()},
+		"pull", reg.Host()+"/skills/missing:1.0.0",
+	)
+	if exitCode != 5 {
+		t.Fatalf("expected exit code 5, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stderr, "not found") {
+		t.Errorf("expected stderr to contain 'not found', got:\n%s", stderr)
+	}
+}
+
+func TestPullConflict(t *testing.T) {
+	bin := buildPSK(t)
+	reg := registrytest.New()
+	defer reg.Close()
+	pushValidSkill(t, bin, reg)
+
+	store := t.TempDir()
+	ref := reg.Host() + "/skills/valid-skill:1.0.0"
+	if _, _, exitCode := runPSK(t, bin, []string{"PSK_STORE=" + store}, "pull", ref); exitCode != 0 {
+		t.Fatalf("first pull expected exit code 0, got %d", exitCode)
+	}
+
+	_, stderr, exitCode := runPSK(t, bin, []string{"PSK_STORE=" + store}, "pull", ref)
+	if exitCode != 3 {
+		t.Fatalf("second pull expected exit code 3, got %d", exitCode)
+	}
+	if !strings.Contains(stderr, "already exists") {
+		t.Errorf("expected stderr to contain 'already exists', got:\n%s", stderr)
+	}
+
+	if _, _, exitCode := runPSK(t, bin, []string{"PSK_STORE=" + store}, "pull", ref, "--force"); exitCode != 0 {
+		t.Fatalf("forced pull expected exit code 0, got %d", exitCode)
+	}
+}
+
+// packScriptedSkill builds the scripted-skill fixture into a fresh store
+// and returns the stored artifact packed for a registry, with its config.
+func packScriptedSkill(t *testing.T, bin string) (*oci.Artifact, store.Manifest) {
+	t.Helper()
+	storeDir := t.TempDir()
+	_, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + storeDir},
+		"build", filepath.Join(testdataDir(t), "scripted-skill"),
+		"--maintainer", "Test <test@example.com>",
+	)
+	if exitCode != 0 {
+		t.Fatalf("build failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	a, err := oci.Pack(filepath.Join(storeDir, "scripted-skill", "1.0.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := oci.DecodeConfig(a.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a, m
+}
+
+// repackArtifact returns a with config m and the given manifest
+// annotations, keeping its layer.
+func repackArtifact(t *testing.T, a *oci.Artifact, m store.Manifest, annotations map[string]string) *oci.Artifact {
+	t.Helper()
+	config, err := oci.EncodeConfig(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := a.Manifest
+	manifest.Config = oci.NewDescriptor(oci.MediaTypeSkillConfig, config)
+	manifest.Annotations = annotations
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &oci.Artifact{Manifest: manifest, ManifestBytes: data, Config: config, Layer: a.Layer}
+}
+
+func TestPullRejectsMismatchedArtifact(t *testing.T) {
+	bin := buildPSK(t)
+	reg := registrytest.New()
+	defer reg.Close()
+	a, m := packScriptedSkill(t, bin)
+
+	hidden := m
+	hidden.Contents.Scripts = nil
+	wrongHash := m
+	wrongHash.SourceHash = "sha256:" + strings.Repeat("0", 64)
+
+	tests := []struct {
+		tag      string
+		artifact *oci.Artifact
+		want     string
+	}{
+		// Valid without annotations, but psk would store it under
+		// another digest
+		{"no-annotations", repackArtifact(t, a, m, nil), "digest: artifact " + repackArtifact(t, a, m, nil).Digest()},
+		{"hidden-scripts", repackArtifact(t, a, hidden, oci.Annotations(hidden)), "contents: added: scripts/fetch.py"},
+		{"wrong-hash", repackArtifact(t, a, wrongHash, oci.Annotations(wrongHash)), "sourceHash: skill config records"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			ref, err := oci.ParseReference(reg.Host() + "/skills/scripted-skill:" + tt.tag)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := oci.NewClient().Push(context.Background(), ref, tt.artifact); err != nil {
+				t.Fatal(err)
+			}
+
+			storeDir := t.TempDir()
+			_, stderr, exitCode := runPSK(t, bin, []string{"PSK_STORE=" + storeDir}, "pull", ref.String())
+			if exitCode != 2 {
+				t.Fatalf("expected exit code 2, got %d\nstderr: %s", exitCode, stderr)
+			}
+			if !strings.Contains(stderr, tt.want) {
+				t.Errorf("expected stderr to contain %q, got:\n%s", tt.want, stderr)
+			}
+			if _, err := os.Stat(filepath.Join(storeDir, "scripted-skill", "1.0.0")); !os.IsNotExist(err) {
+				t.Error("mismatched artifact was stored")
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package integration
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/oci"
+	"github.com/c8ab/provenskills/internal/oci/registrytest"
+)
+
+func TestPushSuccessful(t *testing.T) {
+	bin := buildPSK(t)
+	store := t.TempDir()
+	reg := registrytest.New()
+	defer reg.Close()
+
+	_, _, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + store},
+		"build", filepath.Join(testdataDir(t), "valid-skill"),
+		"--maintainer", "Test <test@example.com>",
+	)
+	if exitCode != 0 {
+		t.Fatalf("build failed with exit code %d", exitCode)
+	}
+
+	stdout, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + store},
+		"push", "valid-skill@1.0.0", reg.Host()+"/skills/valid-skill",
+	)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "Pushed skill: valid-skill@1.0.0") {
+		t.Errorf("expected stdout to contain 'Pushed skill: valid-skill@1.0.0', got:\n%s", stdout)
+	}
+
+	// Tag defaults to the skill version
+	data, ok := reg.Manifest("skills/valid-skill", "1.0.0")
+	if !ok {
+		t.Fatal("manifest not found in registry under tag 1.0.0")
+	}
+	var m oci.Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("manifest is not valid JSON: %v", err)
+	}
+	if m.ArtifactType != oci.ArtifactTypeSkill {
+		t.Errorf("expected artifactType %q, got %q", oci.ArtifactTypeSkill, m.ArtifactType)
+	}
+	if !strings.Contains(stdout, oci.Digest(data)) {
+		t.Errorf("expected stdout to contain manifest digest %s, got:\n%s", oci.Digest(data), stdout)
+	}
+}
+
+func TestPushJSONOutput(t *testing.T) {
+	bin := buildPSK(t)
+	store := t.TempDir()
+	reg := registrytest.New()
+	defer reg.Close()
+
+	_, _, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + store},
+		"build", filepath.Join(testdataDir(t), "valid-skill"),
+		"--maintainer", "Test <test@example.com>",
+	)
+	if exitCode != 0 {
+		t.Fatalf("build failed with exit code %d", exitCode)
+	}
+
+	stdout, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + store},
+		"push", "valid-skill@1.0.0", reg.Host()+"/skills/valid-skill:latest", "--json",
+	)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("stdout is not valid JSON: %v\nstdout: %s", err, stdout)
+	}
+	for _, field := range []string{"name", "version", "reference", "digest"} {
+		if _, ok := result[field]; !ok {
+			t.Errorf("JSON output missing field %q", field)
+		}
+	}
+	if _, ok := reg.Manifest("skills/valid-skill", "latest"); !ok {
+		t.Error("manifest not found in registry under tag latest")
+	}
+}
+
+func TestPushMissingSkill(t *testing.T) {
+	bin := buildPSK(t)
+	store := t.TempDir()
+	reg := registrytest.New()
+	defer reg.Close()
+
+	_, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + store},
+		"push", "valid-skill@1.0.0", reg.Host()+"/skills/valid-skill",
+	)
+	if exitCode != 4 {
+		t.Fatalf("expected exit code 4, got %d", exitCode)
+	}
+	if !strings.Contains(stderr, "not found") {
+		t.Errorf("expected stderr to contain 'not found', got:\n%s", stderr)
+	}
+}
+
+func TestPushInvalidReference(t *testing.T) {
+	bin := buildPSK(t)
+	store := t.TempDir()
+
+	_, _, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + store},
+		"push", "valid-skill@", "localhost/skills",
+	)
+	if exitCode != 2 {
+		t.Fatalf("expected exit code 2, got %d", exitCode)
+	}
+}
@@ -0,0 +1,103 @@
+package integration
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+func TestBuildReproducibleWithSourceDateEpoch(t *testing.T) {
+	bin := buildPSK(t)
+
+	var digests []string
+	for i := 0; i < 2; i++ {
+		stdout, stderr, exitCode := runPSK(t, bin,
+			[]string{"PSK_STORE=" + t.TempDir(), "SOURCE_DATE_EPOCH=1700000000"},
+			"build", filepath.Join(testdataDir(t), "valid-skill"),
+			"--maintainer", "Test <test@example.com>",
+			"--json",
+		)
+		if exitCode != 0 {
+			t.Fatalf("build %d failed with exit code %d\nstderr: %s", i, exitCode, stderr)
+		}
+		var result map[string]string
+		if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+			t.Fatalf("stdout is not valid JSON: %v", err)
+		}
+		if result["digest"] == "" {
+			t.Fatal("JSON output missing digest")
+		}
+		digests = append(digests, result["digest"])
+	}
+
+	if digests[0] != digests[1] {
+		t.Errorf("expected identical digests, got %s and %s", digests[0], digests[1])
+	}
+}
+
+func TestBuildUsesSourceDateEpoch(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+
+	_, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + storeDir, "SOURCE_DATE_EPOCH=1700000000"},
+		"build", filepath.Join(testdataDir(t), "valid-skill"),
+		"--maintainer", "Test <test@example.com>",
+	)
+	if exitCode != 0 {
+		t.Fatalf("build failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+
+	m, err := store.ReadManifest(filepath.Join(storeDir, "valid-skill", "1.0.0", "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.BuildTimestamp != "2023-11-14T22:13:20Z" {
+		t.Errorf("expected buildTimestamp 2023-11-14T22:13:20Z, got %s", m.BuildTimestamp)
+	}
+}
+
+func TestBuildInvalidSourceDateEpoch(t *testing.T) {
+	bin := buildPSK(t)
+
+	_, _, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + t.TempDir(), "SOURCE_DATE_EPOCH=yesterday"},
+		"build", filepath.Join(testdataDir(t), "valid-skill"),
+		"--maintainer", "Test <test@example.com>",
+	)
+	if exitCode != 2 {
+		t.Fatalf("expected exit code 2, got %d", exitCode)
+	}
+}
+
+func TestBuildPreservesExecutableBit(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+
+	skillDir := createTempSkill(t, "exec-skill", "1.0.0", "test-author")
+	if err := os.MkdirAll(filepath.Join(skillDir, "scripts"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(skillDir, "scripts", "run.sh"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	_, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + storeDir},
+		"build", skillDir, "--maintainer", "Test <test@example.com>",
+	)
+	if exitCode != 0 {
+		t.Fatalf("build failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+
+	info, err := os.Stat(filepath.Join(storeDir, "exec-skill", "1.0.0", "scripts", "run.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o755 {
+		t.Errorf("expected stored script mode 0755, got %v", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,87 @@
+package integration
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRmVersion(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir}
+	buildValidSkill(t, bin, storeDir)
+
+	stdout, stderr, exitCode := runPSK(t, bin, env, "rm", "valid-skill@1.0")
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "Removed skill: valid-skill@1.0.0") {
+		t.Errorf("unexpected output:\n%s", stdout)
+	}
+	if _, err := os.Stat(filepath.Join(storeDir, "valid-skill")); !os.IsNotExist(err) {
+		t.Errorf("expected the empty valid-skill directory to be removed, got %v", err)
+	}
+
+	stdout, _, _ = runPSK(t, bin, env, "list")
+	if !strings.Contains(stdout, "No skills found") {
+		t.Errorf("expected an empty store, got:\n%s", stdout)
+	}
+
+	_, stderr, exitCode = runPSK(t, bin, env, "rm", "valid-skill@1.0.0")
+	if exitCode != 4 || !strings.Contains(stderr, "not found") {
+		t.Errorf("expected exit code 4 for a missing skill, got %d\nstderr: %s", exitCode, stderr)
+	}
+}
+
+func TestRmAllVersions(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir}
+	buildValidSkill(t, bin, storeDir)
+
+	// Store a second version by copying the first under a new version
+	src := filepath.Join(storeDir, "valid-skill", "1.0.0")
+	data, err := os.ReadFile(filepath.Join(src, "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(storeDir, "valid-skill", "1.1.0")
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	manifest := strings.Replace(string(data), `"version": "1.0.0"`, `"version": "1.1.0"`, 1)
+	if err := os.WriteFile(filepath.Join(dst, "manifest.json"), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, stderr, exitCode := runPSK(t, bin, env, "rm", "valid-skill"); exitCode != 2 || !strings.Contains(stderr, "--all-versions") {
+		t.Errorf("expected exit code 2 suggesting --all-versions, got %d\nstderr: %s", exitCode, stderr)
+	}
+
+	stdout, stderr, exitCode := runPSK(t, bin, env, "rm", "valid-skill", "--all-versions", "--json")
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+	var removed []struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &removed); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, stdout)
+	}
+	if len(removed) != 2 || removed[0].Version != "1.0.0" || removed[1].Version != "1.1.0" {
+		t.Errorf("expected both versions to be removed, got %+v", removed)
+	}
+	if _, err := os.Stat(filepath.Join(storeDir, "valid-skill")); !os.IsNotExist(err) {
+		t.Errorf("expected valid-skill directory to be removed, got %v", err)
+	}
+
+	if _, _, exitCode := runPSK(t, bin, env, "rm", "valid-skill", "--all-versions"); exitCode != 4 {
+		t.Errorf("expected exit code 4 for a missing skill, got %d", exitCode)
+	}
+	if _, _, exitCode := runPSK(t, bin, env, "rm", "../etc", "--all-versions"); exitCode != 2 {
+		t.Errorf("expected exit code 2 for an invalid name, got %d", exitCode)
+	}
+}
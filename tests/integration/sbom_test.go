@@ -0,0 +1,100 @@
+package integration
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildWithSBOM(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir}
+	prefix := generateKey(t, bin, "ed25519")
+
+	stdout, stderr, exitCode := runPSK(t, bin, env,
+		"build", filepath.Join(testdataDir(t), "scripted-skill"),
+		"--maintainer", "Test <test@example.com>",
+		"--sbom", "--key", prefix+".key", "--json",
+	)
+	if exitCode != 0 {
+		t.Fatalf("build failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	var built struct {
+		SBOM []string `json:"sbom"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &built); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, stdout)
+	}
+	if len(built.SBOM) != 2 {
+		t.Errorf("expected SPDX and CycloneDX attestations, got %v", built.SBOM)
+	}
+
+	stdout, stderr, exitCode = runPSK(t, bin, env, "sbom", "scripted-skill@1.0.0")
+	if exitCode != 0 {
+		t.Fatalf("sbom failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	if stderr != "" {
+		t.Errorf("expected the attested SBOM without warnings, got stderr:\n%s", stderr)
+	}
+	for _, want := range []string{`"spdxVersion": "SPDX-2.3"`, "./scripts/fetch.py", "pkg:pypi/requests@2.32.3", "pkg:npm/%40octokit/rest@21.0.2"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected SPDX output to contain %q, got:\n%s", want, stdout)
+		}
+	}
+	if strings.Contains(stdout, "eslint") {
+		t.Errorf("expected devDependencies to be left out, got:\n%s", stdout)
+	}
+
+	stdout, _, exitCode = runPSK(t, bin, env, "sbom", "scripted-skill@1.0.0", "--format", "cyclonedx")
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if !strings.Contains(stdout, `"bomFormat": "CycloneDX"`) || !strings.Contains(stdout, "pkg:npm/marked") {
+		t.Errorf("unexpected CycloneDX output:\n%s", stdout)
+	}
+
+	stdout, _, _ = runPSK(t, bin, env, "attestations", "scripted-skill@1.0.0")
+	for _, want := range []string{"https://spdx.dev/Document/v2.3", "https://cyclonedx.org/bom/v1.5"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected attestations to list %s, got:\n%s", want, stdout)
+		}
+	}
+}
+
+func TestSBOMWithoutAttestation(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir}
+
+	_, stderr, exitCode := runPSK(t, bin, env,
+		"build", filepath.Join(testdataDir(t), "scripted-skill"),
+		"--maintainer", "Test <test@example.com>", "--sbom",
+	)
+	if exitCode != 2 || !strings.Contains(stderr, "--key") {
+		t.Errorf("expected exit code 2 mentioning --key, got %d\nstderr: %s", exitCode, stderr)
+	}
+
+	_, stderr, exitCode = runPSK(t, bin, env,
+		"build", filepath.Join(testdataDir(t), "scripted-skill"),
+		"--maintainer", "Test <test@example.com>",
+	)
+	if exitCode != 0 {
+		t.Fatalf("build failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	stdout, stderr, exitCode := runPSK(t, bin, env, "sbom", "scripted-skill@1.0.0")
+	if exitCode != 0 {
+		t.Fatalf("sbom failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stderr, "no spdx SBOM attestation") {
+		t.Errorf("expected a warning about the missing attestation, got:\n%s", stderr)
+	}
+	if !strings.Contains(stdout, "pkg:pypi/requests@2.32.3") {
+		t.Errorf("expected a generated SBOM, got:\n%s", stdout)
+	}
+
+	if _, _, exitCode := runPSK(t, bin, env, "sbom", "scripted-skill@1.0.0", "--format", "swid"); exitCode != 2 {
+		t.Errorf("expected exit code 2 for an unknown format, got %d", exitCode)
+	}
+}
@@ -0,0 +1,173 @@
+package integration
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+func TestSemverCheck(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir}
+
+	dir := copySkill(t, "scripted-skill")
+	skillMD := filepath.Join(dir, "SKILL.md")
+	setVersion := func(version string) {
+		t.Helper()
+		data, err := os.ReadFile(skillMD)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(string(data), "\n")
+		for i, l := range lines {
+			if strings.HasPrefix(l, "  version:") {
+				lines[i] = `  version: "` + version + `"`
+			}
+		}
+		if err := os.WriteFile(skillMD, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Nothing to compare the first version with
+	stdout, stderr, exitCode := runPSK(t, bin, env, "semver-check", dir)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "No earlier version of scripted-skill in store") {
+		t.Errorf("expected no earlier version, got:\n%s", stdout)
+	}
+	if _, stderr, exitCode := runPSK(t, bin, env, "build", dir, "--maintainer", "Test <test@example.com>", "--check-bump"); exitCode != 0 {
+		t.Fatalf("build failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+
+	// A body edit is fine as a patch
+	data, err := os.ReadFile(skillMD)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(skillMD, append(data, []byte("\nMore details.\n")...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	setVersion("1.0.1")
+	stdout, stderr, exitCode = runPSK(t, bin, env, "semver-check", dir)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+	for _, want := range []string{"Version bump OK: scripted-skill 1.0.0 -> 1.0.1", "required: patch", "SKILL.md: body changed"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, stdout)
+		}
+	}
+
+	// Removing a script is not
+	if err := os.Remove(filepath.Join(dir, "scripts", "render.js")); err != nil {
+		t.Fatal(err)
+	}
+	_, stderr, exitCode = runPSK(t, bin, env, "semver-check", dir)
+	if exitCode != 2 {
+		t.Fatalf("expected exit code 2, got %d\nstderr: %s", exitCode, stderr)
+	}
+	for _, want := range []string{"scripted-skill 1.0.0 -> 1.0.1 is a patch bump, but the changes require a major bump", "major  scripts/render.js: removed"} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("expected stderr to contain %q, got:\n%s", want, stderr)
+		}
+	}
+
+	_, stderr, exitCode = runPSK(t, bin, env, "build", dir, "--maintainer", "Test <test@example.com>", "--check-bump")
+	if exitCode != 2 {
+		t.Fatalf("expected build to fail with exit code 2, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(storeDir, "scripted-skill", "1.0.1")); !os.IsNotExist(err) {
+		t.Errorf("expected under-bumped version not to be stored, got %v", err)
+	}
+
+	// Without --check-bump the build goes ahead
+	if _, stderr, exitCode := runPSK(t, bin, env, "build", dir, "--maintainer", "Test <test@example.com>"); exitCode != 0 {
+		t.Fatalf("build failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+
+	// A major bump passes, compared with the highest earlier version
+	setVersion("2.0.0")
+	stdout, stderr, exitCode = runPSK(t, bin, env, "build", dir, "--maintainer", "Test <test@example.com>", "--check-bump")
+	if exitCode != 0 {
+		t.Fatalf("build failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "bump:       major since 1.0.1 (none required)") {
+		t.Errorf("expected bump in build output, got:\n%s", stdout)
+	}
+
+	stdout, stderr, exitCode = runPSK(t, bin, env, "semver-check", "scripted-skill@2.0.0", "--json")
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+	var result struct {
+		Previous string `json:"previous"`
+		Required string `json:"required"`
+		Actual   string `json:"actual"`
+		OK       bool   `json:"ok"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout)
+	}
+	if result.Previous != "1.0.1" || result.Required != "none" || result.Actual != "major" || !result.OK {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	// Checked against 1.0.0, 1.0.1 is under-bumped
+	_, stderr, exitCode = runPSK(t, bin, env, "semver-check", "scripted-skill@1.0.1")
+	if exitCode != 2 {
+		t.Fatalf("expected exit code 2, got %d\nstderr: %s", exitCode, stderr)
+	}
+}
+
+func TestSemverCheckReadsStoredFiles(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir}
+
+	dir := copySkill(t, "scripted-skill")
+	if _, stderr, exitCode := runPSK(t, bin, env, "build", dir, "--maintainer", "Test <test@example.com>"); exitCode != 0 {
+		t.Fatalf("build failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+
+	// The previous manifest.json no longer lists the script about to be
+	// removed, but the stored files still have it
+	manifestPath := filepath.Join(storeDir, "scripted-skill", "1.0.0", "manifest.json")
+	m, err := store.ReadManifest(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var scripts []store.FileEntry
+	for _, f := range m.Contents.Scripts {
+		if f.Path != "scripts/render.js" {
+			scripts = append(scripts, f)
+		}
+	}
+	m.Contents.Scripts = scripts
+	if err := store.WriteManifest(manifestPath, m); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "scripts", "render.js")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "SKILL.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated := strings.Replace(string(data), `version: "1.0.0"`, `version: "1.0.1"`, 1)
+	if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(updated), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, stderr, exitCode := runPSK(t, bin, env, "semver-check", dir)
+	if exitCode != 2 || !strings.Contains(stderr, "major  scripts/render.js: removed") {
+		t.Errorf("expected the removed script to require a major bump, got exit code %d\nstderr: %s", exitCode, stderr)
+	}
+}
@@ -0,0 +1,256 @@
+package integration
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/oci"
+	"github.com/c8ab/provenskills/internal/oci/registrytest"
+	"github.com/c8ab/provenskills/internal/signing"
+)
+
+// generateKey creates a key pair with psk key generate and returns the
+// path prefix of the key files.
+func generateKey(t *testing.T, bin, algorithm string) string {
+	t.Helper()
+	prefix := filepath.Join(t.TempDir(), "signer")
+	_, stderr, exitCode := runPSK(t, bin, nil,
+		"key", "generate", "--algorithm", algorithm, "--output", prefix,
+	)
+	if exitCode != 0 {
+		t.Fatalf("key generate failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	return prefix
+}
+
+func TestKeyGenerate(t *testing.T) {
+	bin := buildPSK(t)
+	prefix := filepath.Join(t.TempDir(), "signer")
+
+	stdout, stderr, exitCode := runPSK(t, bin, nil,
+		"key", "generate", "--algorithm", "ed25519", "--output", prefix, "--json",
+	)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+	var result map[string]string
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, stdout)
+	}
+	if result["algorithm"] != "ed25519" || !strings.HasPrefix(result["keyId"], "sha256:") {
+		t.Errorf("unexpected output: %v", result)
+	}
+
+	info, err := os.Stat(prefix + ".key")
+	if err != nil {
+		t.Fatalf("private key not written: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("expected private key mode 0600, got %v", info.Mode().Perm())
+	}
+	if _, err := signing.LoadPublicKey(prefix + ".pub"); err != nil {
+		t.Errorf("public key not loadable: %v", err)
+	}
+
+	// Existing keys are not overwritten without --force
+	_, _, exitCode = runPSK(t, bin, nil, "key", "generate", "--output", prefix)
+	if exitCode != 3 {
+		t.Errorf("expected exit code 3 for existing key, got %d", exitCode)
+	}
+	_, _, exitCode = runPSK(t, bin, nil, "key", "generate", "--output", prefix, "--force")
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0 with --force, got %d", exitCode)
+	}
+}
+
+func TestKeyGenerateErrors(t *testing.T) {
+	bin := buildPSK(t)
+
+	for _, flag := range []string{"--algorithm", "--output"} {
+		_, stderr, exitCode := runPSK(t, bin, nil, "key", "generate", flag)
+		if exitCode != 2 || !strings.Contains(stderr, flag+" requires a value") {
+			t.Errorf("%s: expected exit code 2 for a missing value, got %d\nstderr: %s", flag, exitCode, stderr)
+		}
+	}
+
+	// A public key that cannot be written takes the private key with it
+	prefix := filepath.Join(t.TempDir(), "signer")
+	if err := os.MkdirAll(filepath.Join(prefix+".pub", "occupied"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	_, stderr, exitCode := runPSK(t, bin, nil, "key", "generate", "--output", prefix, "--force")
+	if exitCode != 4 || !strings.Contains(stderr, "failed to write public key") {
+		t.Errorf("expected exit code 4, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if _, err := os.Stat(prefix + ".key"); !os.IsNotExist(err) {
+		t.Errorf("expected the private key to be removed, got %v", err)
+	}
+}
+
+func TestSignStoredSkill(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	buildValidSkill(t, bin, storeDir)
+	prefix := generateKey(t, bin, "ecdsa-p256")
+
+	stdout, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + storeDir},
+		"sign", "valid-skill@1.0.0", "--key", prefix+".key",
+	)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "Signed skill: valid-skill@1.0.0") {
+		t.Errorf("expected stdout to contain 'Signed skill: valid-skill@1.0.0', got:\n%s", stdout)
+	}
+
+	dir := filepath.Join(storeDir, "valid-skill", "1.0.0")
+	sigs, err := signing.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sigs) != 1 {
+		t.Fatalf("expected 1 signature, got %d", len(sigs))
+	}
+	artifact, err := oci.Pack(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sigs[0].Verify(artifact.Digest()); err != nil {
+		t.Errorf("stored signature does not verify: %v", err)
+	}
+
+	// Signing does not disturb source hash verification
+	_, stderr, exitCode = runPSK(t, bin, []string{"PSK_STORE=" + storeDir}, "verify", "valid-skill@1.0.0")
+	if exitCode != 0 {
+		t.Errorf("expected verify to pass after signing, got %d\nstderr: %s", exitCode, stderr)
+	}
+}
+
+func TestSignCoversRecordedDigest(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir}
+	buildValidSkill(t, bin, storeDir)
+	dir := filepath.Join(storeDir, "valid-skill", "1.0.0")
+
+	artifact, err := oci.Pack(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, err := os.ReadFile(filepath.Join(dir, "oci-manifest.json"))
+	if err != nil {
+		t.Fatalf("expected build to record the artifact manifest: %v", err)
+	}
+	if oci.Digest(recorded) != artifact.Digest() {
+		t.Fatalf("recorded digest %s, but the artifact packs to %s", oci.Digest(recorded), artifact.Digest())
+	}
+
+	// Record the manifest an older packing would have produced
+	m, err := oci.DecodeConfig(artifact.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	older := repackArtifact(t, artifact, m, nil)
+	if err := oci.RecordManifest(dir, older); err != nil {
+		t.Fatal(err)
+	}
+
+	prefix := generateKey(t, bin, "ed25519")
+	if _, stderr, exitCode := runPSK(t, bin, env, "sign", "valid-skill@1.0.0", "--key", prefix+".key"); exitCode != 0 {
+		t.Fatalf("sign failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	sigs, err := signing.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sigs) != 1 || sigs[0].Verify(older.Digest()) != nil {
+		t.Fatalf("expected the signature to cover the recorded digest %s", older.Digest())
+	}
+
+	stdout, _, exitCode := runPSK(t, bin, env, "store", "fsck")
+	if exitCode != 6 || !strings.Contains(stdout, "- digest: recorded "+older.Digest()) {
+		t.Errorf("expected fsck to report the changed packing, got exit code %d:\n%s", exitCode, stdout)
+	}
+	_, stderr, exitCode := runPSK(t, bin, env, "export", "valid-skill@1.0.0", "--format", "oci-layout", filepath.Join(t.TempDir(), "layout"))
+	if exitCode != 6 || !strings.Contains(stderr, "stored as "+older.Digest()) {
+		t.Errorf("expected export to refuse a changed packing with exit code 6, got %d\nstderr: %s", exitCode, stderr)
+	}
+}
+
+func TestSignMissingKey(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	buildValidSkill(t, bin, storeDir)
+
+	_, _, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + storeDir},
+		"sign", "valid-skill@1.0.0",
+	)
+	if exitCode != 2 {
+		t.Errorf("expected exit code 2 without --key, got %d", exitCode)
+	}
+
+	_, _, exitCode = runPSK(t, bin,
+		[]string{"PSK_STORE=" + storeDir},
+		"sign", "valid-skill@1.0.0", "--key", filepath.Join(t.TempDir(), "missing.key"),
+	)
+	if exitCode != 4 {
+		t.Errorf("expected exit code 4 for missing key file, got %d", exitCode)
+	}
+}
+
+func TestPushAndPullCarrySignatures(t *testing.T) {
+	bin := buildPSK(t)
+	reg := registrytest.New()
+	defer reg.Close()
+
+	srcStore := t.TempDir()
+	buildValidSkill(t, bin, srcStore)
+	prefix := generateKey(t, bin, "ed25519")
+	_, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + srcStore},
+		"sign", "valid-skill@1.0.0", "--key", prefix+".key",
+	)
+	if exitCode != 0 {
+		t.Fatalf("sign failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+
+	stdout, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + srcStore},
+		"push", "valid-skill@1.0.0", reg.Host()+"/skills/valid-skill:1.0.0",
+	)
+	if exitCode != 0 {
+		t.Fatalf("push failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "signatures:   1") {
+		t.Errorf("expected push to report 1 signature, got:\n%s", stdout)
+	}
+
+	dstStore := t.TempDir()
+	stdout, stderr, exitCode = runPSK(t, bin,
+		[]string{"PSK_STORE=" + dstStore},
+		"pull", reg.Host()+"/skills/valid-skill:1.0.0",
+	)
+	if exitCode != 0 {
+		t.Fatalf("pull failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "signatures:   1") {
+		t.Errorf("expected pull to report 1 signature, got:\n%s", stdout)
+	}
+
+	want, err := signing.Load(filepath.Join(srcStore, "valid-skill", "1.0.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := signing.Load(filepath.Join(dstStore, "valid-skill", "1.0.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != want[0] {
+		t.Errorf("pulled signatures differ from pushed ones: %+v", got)
+	}
+}
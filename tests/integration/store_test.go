@@ -0,0 +1,218 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+func TestStoreGC(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir}
+	buildValidSkill(t, bin, storeDir)
+
+	// Leftovers of a build killed before it could clean up
+	orphan := filepath.Join(storeDir, "valid-skill", "1.1.0.tmp.999999999")
+	if err := os.MkdirAll(orphan, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(orphan, "SKILL.md"), []byte("partial"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, exitCode := runPSK(t, bin, env, "store", "gc", "--dry-run", "--json")
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+	var result struct {
+		DryRun  bool `json:"dryRun"`
+		Removed []struct {
+			Path string `json:"path"`
+		} `json:"removed"`
+		Reclaimed int64 `json:"reclaimed"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, stdout)
+	}
+	if !result.DryRun || len(result.Removed) != 1 || result.Removed[0].Path != orphan || result.Reclaimed != 7 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if _, err := os.Stat(orphan); err != nil {
+		t.Errorf("expected --dry-run to keep the temp directory, got %v", err)
+	}
+
+	stdout, stderr, exitCode = runPSK(t, bin, env, "store", "gc")
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "Removed 1 item(s), reclaiming 7 B") || !strings.Contains(stdout, "pid 999999999 is not running") {
+		t.Errorf("unexpected output:\n%s", stdout)
+	}
+	if _, err := os.Stat(orphan); !os.IsNotExist(err) {
+		t.Errorf("expected the temp directory to be removed, got %v", err)
+	}
+
+	stdout, _, _ = runPSK(t, bin, env, "store", "gc")
+	if !strings.Contains(stdout, "Nothing to collect.") {
+		t.Errorf("expected nothing left to collect, got:\n%s", stdout)
+	}
+	if _, _, exitCode := runPSK(t, bin, env, "verify", "valid-skill@1.0.0"); exitCode != 0 {
+		t.Errorf("expected the stored skill to survive gc, got exit code %d", exitCode)
+	}
+}
+
+func TestStoreFsck(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir}
+	signValidSkill(t, bin, storeDir, "ed25519")
+
+	stdout, stderr, exitCode := runPSK(t, bin, env, "store", "fsck")
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstdout: %s\nstderr: %s", exitCode, stdout, stderr)
+	}
+	if !strings.Contains(stdout, "valid-skill  1.0.0    ok") || !strings.Contains(stdout, "Checked 1 artifact(s), 0 with problems.") {
+		t.Errorf("unexpected output:\n%s", stdout)
+	}
+
+	// Tamper with the stored skill and leave a directory without a manifest
+	skillMD := filepath.Join(storeDir, "valid-skill", "1.0.0", "SKILL.md")
+	if err := os.WriteFile(skillMD, []byte("---\nname: valid-skill\n---\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(storeDir, "broken", "0.1.0"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, _, exitCode = runPSK(t, bin, env, "store", "fsck")
+	if exitCode != 6 {
+		t.Fatalf("expected exit code 6, got %d\n%s", exitCode, stdout)
+	}
+	for _, want := range []string{"broken       0.1.0    1 problem(s)", "- manifest: ", "- index: modified: SKILL.md", "- sourceHash: recorded", "- digest: ", "- signature: key ", "Checked 2 artifact(s), 2 with problems."} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, stdout)
+		}
+	}
+
+	_, stderr, exitCode = runPSK(t, bin, env, "store", "fsck", "--json")
+	if exitCode != 6 {
+		t.Fatalf("expected exit code 6, got %d", exitCode)
+	}
+	var results []struct {
+		Name     string `json:"name"`
+		Problems []struct {
+			Check string `json:"check"`
+		} `json:"problems"`
+	}
+	if err := json.Unmarshal([]byte(stderr), &results); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, stderr)
+	}
+	if len(results) != 2 || results[0].Name != "broken" || len(results[1].Problems) != 4 {
+		t.Errorf("unexpected results: %+v", results)
+	}
+}
+
+func TestStoreMigrate(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir}
+	buildValidSkill(t, bin, storeDir)
+
+	// Turn the store back into a version 1 store, which had no blobs
+	if err := os.RemoveAll(filepath.Join(storeDir, "blobs")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(storeDir, "valid-skill", "1.0.0", "index.json")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(storeDir, ".store-version"), []byte("1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, exitCode := runPSK(t, bin, env, "store", "migrate", "--dry-run")
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "Would migrate store from version 1 to 2") || !strings.Contains(stdout, "1 -> 2") {
+		t.Errorf("unexpected output:\n%s", stdout)
+	}
+	if data, _ := os.ReadFile(filepath.Join(storeDir, ".store-version")); string(data) != "1\n" {
+		t.Errorf("expected --dry-run to leave the store alone, got version %q", data)
+	}
+
+	// Any command migrates the store when it opens it
+	stdout, stderr, exitCode = runPSK(t, bin, env, "list")
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "valid-skill") || !strings.Contains(stderr, "Migrated store to version 2") {
+		t.Errorf("unexpected output:\nstdout: %s\nstderr: %s", stdout, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(storeDir, ".backup", "v1", "valid-skill", "1.0.0", "SKILL.md")); err != nil {
+		t.Errorf("expected a backup of the version 1 store, got %v", err)
+	}
+	if _, _, exitCode := runPSK(t, bin, env, "verify", "valid-skill@1.0.0"); exitCode != 0 {
+		t.Errorf("expected the migrated skill to verify, got exit code %d", exitCode)
+	}
+
+	stdout, _, _ = runPSK(t, bin, env, "store", "migrate")
+	if !strings.Contains(stdout, "Store is up to date (version 2).") {
+		t.Errorf("unexpected output:\n%s", stdout)
+	}
+}
+
+func TestStoreNewerVersion(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir}
+	if err := os.WriteFile(filepath.Join(storeDir, ".store-version"), []byte("3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{{"list"}, {"build", filepath.Join(testdataDir(t), "valid-skill"), "--maintainer", "Test <test@example.com>"}, {"store", "migrate", "--dry-run"}} {
+		_, stderr, exitCode := runPSK(t, bin, env, args...)
+		if exitCode != 2 || !strings.Contains(stderr, "newer version of psk") {
+			t.Errorf("%s: expected exit code 2 for a newer store, got %d\nstderr: %s", args[0], exitCode, stderr)
+		}
+	}
+}
+
+func TestStoreLocked(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir, "PSK_LOCK_TIMEOUT=200ms"}
+	buildValidSkill(t, bin, storeDir)
+	prefix := generateKey(t, bin, "ed25519")
+
+	unlock, err := store.New(storeDir).Lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	locked := fmt.Sprintf("store is locked by pid %d", os.Getpid())
+	for _, args := range [][]string{
+		{"build", filepath.Join(testdataDir(t), "valid-skill"), "--maintainer", "Test <test@example.com>", "--force"},
+		{"sign", "valid-skill@1.0.0", "--key", prefix + ".key"},
+		{"attest", "valid-skill@1.0.0", "--predicate", writePredicate(t, `{"result":"pass"}`), "--type", "https://example.com/eval-passed/v1", "--key", prefix + ".key"},
+		{"rm", "valid-skill@1.0.0"},
+		{"store", "gc"},
+	} {
+		_, stderr, exitCode := runPSK(t, bin, env, args...)
+		if exitCode != 11 || !strings.Contains(stderr, locked) {
+			t.Errorf("%s: expected exit code 11 naming the lock holder, got %d\nstderr: %s", args[0], exitCode, stderr)
+		}
+	}
+	if _, stderr, exitCode := runPSK(t, bin, env, "list"); exitCode != 0 {
+		t.Errorf("expected list to ignore the lock, got %d\nstderr: %s", exitCode, stderr)
+	}
+
+	unlock()
+	if _, stderr, exitCode := runPSK(t, bin, env, "rm", "valid-skill@1.0.0"); exitCode != 0 {
+		t.Errorf("expected rm to succeed once unlocked, got %d\nstderr: %s", exitCode, stderr)
+	}
+}
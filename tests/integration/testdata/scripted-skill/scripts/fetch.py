@@ -0,0 +1,4 @@
+import requests
+import yaml
+
+print(yaml.safe_dump(requests.get("https://example.com").headers))
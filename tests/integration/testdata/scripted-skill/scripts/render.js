@@ -0,0 +1,2 @@
+const { marked } = require("marked");
+console.log(marked.parse("# hello"));
@@ -0,0 +1,187 @@
+package integration
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// signValidSkill builds the valid-skill fixture into storeDir and signs it
+// with a new key of the given algorithm, returning the key path prefix.
+func signValidSkill(t *testing.T, bin, storeDir, algorithm string) string {
+	t.Helper()
+	buildValidSkill(t, bin, storeDir)
+	prefix := generateKey(t, bin, algorithm)
+	_, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + storeDir},
+		"sign", "valid-skill@1.0.0", "--key", prefix+".key",
+	)
+	if exitCode != 0 {
+		t.Fatalf("sign failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	return prefix
+}
+
+func TestVerifySignatureTrusted(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir, "PSK_TRUST=" + t.TempDir()}
+	prefix := signValidSkill(t, bin, storeDir, "ecdsa-p256")
+
+	_, stderr, exitCode := runPSK(t, bin, env,
+		"trust", "add", prefix+".pub", "--identity", "Test <test@example.com>",
+	)
+	if exitCode != 0 {
+		t.Fatalf("trust add failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+
+	stdout, stderr, exitCode := runPSK(t, bin, env,
+		"verify-signature", "valid-skill@1.0.0", "--require-maintainer",
+	)
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "Verified signature: valid-skill@1.0.0") {
+		t.Errorf("expected stdout to contain 'Verified signature: valid-skill@1.0.0', got:\n%s", stdout)
+	}
+	if !strings.Contains(stdout, "Test <test@example.com> (maintainer)") {
+		t.Errorf("expected signer to be mapped to the maintainer, got:\n%s", stdout)
+	}
+
+	stdout, _, exitCode = runPSK(t, bin, env, "verify-signature", "valid-skill@1.0.0", "--json")
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	var result struct {
+		Verified   bool `json:"verified"`
+		Signatures []struct {
+			Status     string `json:"status"`
+			Identity   string `json:"identity"`
+			Maintainer bool   `json:"maintainer"`
+		} `json:"signatures"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, stdout)
+	}
+	if !result.Verified || len(result.Signatures) != 1 || result.Signatures[0].Status != "trusted" || !result.Signatures[0].Maintainer {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestVerifySignatureUnsigned(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	buildValidSkill(t, bin, storeDir)
+
+	_, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + storeDir, "PSK_TRUST=" + t.TempDir()},
+		"verify-signature", "valid-skill@1.0.0",
+	)
+	if exitCode != 7 {
+		t.Errorf("expected exit code 7 for unsigned skill, got %d", exitCode)
+	}
+	if !strings.Contains(stderr, "is not signed") {
+		t.Errorf("expected stderr to mention the missing signature, got:\n%s", stderr)
+	}
+}
+
+func TestVerifySignatureUnknownSigner(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	signValidSkill(t, bin, storeDir, "ed25519")
+
+	_, stderr, exitCode := runPSK(t, bin,
+		[]string{"PSK_STORE=" + storeDir, "PSK_TRUST=" + t.TempDir()},
+		"verify-signature", "valid-skill@1.0.0",
+	)
+	if exitCode != 8 {
+		t.Errorf("expected exit code 8 for untrusted signer, got %d\nstderr: %s", exitCode, stderr)
+	}
+}
+
+func TestVerifySignatureMaintainerMismatch(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir, "PSK_TRUST=" + t.TempDir()}
+	prefix := signValidSkill(t, bin, storeDir, "ed25519")
+
+	_, _, exitCode := runPSK(t, bin, env,
+		"trust", "add", prefix+".pub", "--identity", "Someone Else <else@example.com>",
+	)
+	if exitCode != 0 {
+		t.Fatalf("trust add failed with exit code %d", exitCode)
+	}
+
+	_, _, exitCode = runPSK(t, bin, env, "verify-signature", "valid-skill@1.0.0")
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0 without --require-maintainer, got %d", exitCode)
+	}
+	_, stderr, exitCode := runPSK(t, bin, env, "verify-signature", "valid-skill@1.0.0", "--require-maintainer")
+	if exitCode != 8 {
+		t.Errorf("expected exit code 8 with --require-maintainer, got %d\nstderr: %s", exitCode, stderr)
+	}
+}
+
+func TestVerifySignatureBadSignature(t *testing.T) {
+	bin := buildPSK(t)
+	storeDir := t.TempDir()
+	env := []string{"PSK_STORE=" + storeDir, "PSK_TRUST=" + t.TempDir()}
+	prefix := signValidSkill(t, bin, storeDir, "ecdsa-p256")
+
+	_, _, exitCode := runPSK(t, bin, env,
+		"trust", "add", prefix+".pub", "--identity", "Test <test@example.com>",
+	)
+	if exitCode != 0 {
+		t.Fatalf("trust add failed with exit code %d", exitCode)
+	}
+
+	// Changing a file changes the artifact digest the signature covers
+	skillMD := filepath.Join(storeDir, "valid-skill", "1.0.0", "SKILL.md")
+	if err := os.WriteFile(skillMD, []byte("---\nname: valid-skill\n---\ntampered\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, stderr, exitCode := runPSK(t, bin, env, "verify-signature", "valid-skill@1.0.0")
+	if exitCode != 9 {
+		t.Errorf("expected exit code 9 for bad signature, got %d\nstderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stderr, "invalid") {
+		t.Errorf("expected stderr to list the invalid signature, got:\n%s", stderr)
+	}
+}
+
+func TestTrustListAndRemove(t *testing.T) {
+	bin := buildPSK(t)
+	env := []string{"PSK_TRUST=" + t.TempDir()}
+	prefix := generateKey(t, bin, "ed25519")
+
+	stdout, _, exitCode := runPSK(t, bin, env, "trust", "list")
+	if exitCode != 0 || !strings.Contains(stdout, "No trusted keys.") {
+		t.Errorf("expected empty trust store, got %d:\n%s", exitCode, stdout)
+	}
+
+	_, _, exitCode = runPSK(t, bin, env, "trust", "add", prefix+".pub", "--identity", "Test <test@example.com>")
+	if exitCode != 0 {
+		t.Fatalf("trust add failed with exit code %d", exitCode)
+	}
+	_, _, exitCode = runPSK(t, bin, env, "trust", "add", prefix+".pub", "--identity", "Test <test@example.com>")
+	if exitCode != 3 {
+		t.Errorf("expected exit code 3 for an already trusted key, got %d", exitCode)
+	}
+
+	stdout, _, _ = runPSK(t, bin, env, "trust", "list")
+	if !strings.Contains(stdout, "Test <test@example.com>") || !strings.Contains(stdout, "ed25519") {
+		t.Errorf("expected trusted key in list, got:\n%s", stdout)
+	}
+
+	_, stderr, exitCode := runPSK(t, bin, env, "trust", "remove", "Test <test@example.com>")
+	if exitCode != 0 {
+		t.Fatalf("trust remove failed with exit code %d\nstderr: %s", exitCode, stderr)
+	}
+	stdout, _, _ = runPSK(t, bin, env, "trust", "list", "--json")
+	if strings.TrimSpace(stdout) != "[]" {
+		t.Errorf("expected empty JSON list after remove, got:\n%s", stdout)
+	}
+}
@@ -0,0 +1,164 @@
+package unit
+
+import (
+	"context"
+	"crypto"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/attest"
+	"github.com/c8ab/provenskills/internal/intoto"
+	"github.com/c8ab/provenskills/internal/oci"
+	"github.com/c8ab/provenskills/internal/provenance"
+	"github.com/c8ab/provenskills/internal/signing"
+	"github.com/c8ab/provenskills/internal/trust"
+)
+
+const reviewPredicate = "https://example.com/security-review/v1"
+
+func TestAttestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	digest := oci.Digest([]byte("manifest"))
+
+	if atts, err := attest.Load(dir); len(atts) != 0 || err != nil {
+		t.Fatalf("expected no attestations, got %d, %v", len(atts), err)
+	}
+
+	key, _ := signing.GenerateKey(signing.AlgorithmEd25519)
+	st, err := provenance.Generate(context.Background(), testBuild(t.TempDir(), digest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := attest.Sign(key, st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := attest.Save(dir, env); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	subject := intoto.DigestSubject("valid-skill@1.0.0", digest)
+	review, err := attest.Create(key, subject, reviewPredicate, []byte(`{"result":"pass"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := attest.Save(dir, review); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	atts, err := attest.Load(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(atts) != 2 {
+		t.Fatalf("expected 2 attestations, got %d", len(atts))
+	}
+	if atts[0].Statement.PredicateType != reviewPredicate || atts[1].Statement.PredicateType != intoto.PredicateSLSAProvenance {
+		t.Errorf("expected attestations ordered by predicate type, got %q, %q",
+			atts[0].Statement.PredicateType, atts[1].Statement.PredicateType)
+	}
+	for _, a := range atts {
+		if err := a.Envelope.Verify(key.Public()); err != nil {
+			t.Errorf("%s: stored attestation does not verify: %v", a.Statement.PredicateType, err)
+		}
+	}
+
+	if got := attest.Filter(atts, intoto.PredicateSLSAProvenance, digest); len(got) != 1 {
+		t.Errorf("expected 1 provenance attestation, got %d", len(got))
+	}
+	if got := attest.Filter(atts, intoto.PredicateSLSAProvenance, oci.Digest([]byte("other"))); len(got) != 0 {
+		t.Errorf("expected no provenance about another artifact, got %d", len(got))
+	}
+}
+
+func TestAttestReplacesSameTypeAndKey(t *testing.T) {
+	dir := t.TempDir()
+	subject := intoto.DigestSubject("valid-skill@1.0.0", oci.Digest([]byte("manifest")))
+	key, _ := signing.GenerateKey(signing.AlgorithmEd25519)
+	other, _ := signing.GenerateKey(signing.AlgorithmEd25519)
+
+	for _, tc := range []struct {
+		key       crypto.Signer
+		predicate string
+	}{
+		{key, `{"result":"fail"}`},
+		{key, `{"result":"pass"}`},
+		{other, `{"result":"pass"}`},
+	} {
+		env, err := attest.Create(tc.key, subject, reviewPredicate, []byte(tc.predicate))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := attest.Save(dir, env); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	atts, err := attest.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(atts) != 2 {
+		t.Fatalf("expected one attestation per signing key, got %d", len(atts))
+	}
+	for _, a := range atts {
+		if string(a.Statement.Predicate) != `{"result":"pass"}` {
+			t.Errorf("expected the latest predicate, got %s", a.Statement.Predicate)
+		}
+	}
+}
+
+func TestAttestRejectsInvalidInput(t *testing.T) {
+	subject := intoto.DigestSubject("valid-skill@1.0.0", oci.Digest([]byte("manifest")))
+	key, _ := signing.GenerateKey(signing.AlgorithmEd25519)
+
+	for _, predicateType := range []string{"", "security-review", "/relative/path"} {
+		if _, err := attest.Create(key, subject, predicateType, []byte(`{}`)); err == nil {
+			t.Errorf("expected error for predicate type %q, got nil", predicateType)
+		}
+	}
+	for _, predicate := range []string{``, `[1, 2]`, `"text"`, `{broken`} {
+		if _, err := attest.Create(key, subject, reviewPredicate, []byte(predicate)); err == nil {
+			t.Errorf("expected error for predicate %q, got nil", predicate)
+		}
+	}
+}
+
+func TestAttestVerify(t *testing.T) {
+	ts := trust.New(t.TempDir())
+	subject := intoto.DigestSubject("valid-skill@1.0.0", oci.Digest([]byte("manifest")))
+	key, _ := signing.GenerateKey(signing.AlgorithmEd25519)
+
+	env, err := attest.Create(key, subject, reviewPredicate, []byte(`{"result":"pass"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signers, err := attest.Verify(env, ts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(signers) != 1 || signers[0].Status != trust.StatusUntrusted {
+		t.Fatalf("expected one untrusted signer, got %+v", signers)
+	}
+
+	pub, _ := signing.MarshalPublicKey(key.Public())
+	if _, err := ts.Add(pub, "Reviewer <review@example.com>", false); err != nil {
+		t.Fatal(err)
+	}
+	signers, err = attest.Verify(env, ts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(signers) != 1 || signers[0].Status != trust.StatusTrusted || signers[0].Key.Identity != "Reviewer <review@example.com>" {
+		t.Fatalf("expected one trusted signer, got %+v", signers)
+	}
+
+	env.Payload = env.Payload[:len(env.Payload)-4] + "AAAA"
+	signers, err = attest.Verify(env, ts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(signers) != 1 || signers[0].Status != trust.StatusInvalid {
+		t.Fatalf("expected tampered attestation to be invalid, got %+v", signers)
+	}
+}
@@ -0,0 +1,191 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+// addFiles stores name@version with the given files and their modes.
+func addFiles(t *testing.T, s *store.Store, name, version string, files map[string]os.FileMode) string {
+	t.Helper()
+	dir, err := s.AddFunc(name, version, store.Manifest{Name: name, Version: version}, false, func(dir string) error {
+		for rel, mode := range files {
+			path := filepath.Join(dir, filepath.FromSlash(rel))
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, []byte("contents of "+filepath.Base(rel)), mode); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func sameFile(t *testing.T, a, b string) bool {
+	t.Helper()
+	ai, err := os.Stat(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return os.SameFile(ai, bi)
+}
+
+func TestStoreDeduplicatesFiles(t *testing.T) {
+	s := store.New(t.TempDir())
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]os.FileMode{"SKILL.md": 0o644, "assets/big.bin": 0o644, "scripts/run.sh": 0o755}
+	v1 := addFiles(t, s, "my-skill", "1.0.0", files)
+	v2 := addFiles(t, s, "my-skill", "1.0.1", files)
+
+	for rel := range files {
+		a, b := filepath.Join(v1, rel), filepath.Join(v2, rel)
+		if !sameFile(t, a, b) {
+			t.Errorf("expected %s to be stored once", rel)
+		}
+		digest, err := store.FileDigest(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameFile(t, a, s.BlobPath(digest)) {
+			t.Errorf("expected %s to be its blob", rel)
+		}
+	}
+	if info, _ := os.Stat(filepath.Join(v2, "scripts", "run.sh")); info.Mode().Perm()&0o111 == 0 {
+		t.Error("expected scripts/run.sh to stay executable")
+	}
+
+	idx, err := store.ReadIndex(v2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.Files) != 3 || idx.Files[0].Path != "SKILL.md" || !idx.Files[2].Executable || !strings.HasPrefix(idx.Files[1].Digest, "sha256:") {
+		t.Errorf("unexpected index: %+v", idx)
+	}
+}
+
+func TestStoreKeepsExecutableBitApart(t *testing.T) {
+	s := store.New(t.TempDir())
+	v1 := addFiles(t, s, "my-skill", "1.0.0", map[string]os.FileMode{"SKILL.md": 0o644, "scripts/run.sh": 0o644})
+	v2 := addFiles(t, s, "my-skill", "1.0.1", map[string]os.FileMode{"SKILL.md": 0o644, "scripts/run.sh": 0o755})
+
+	a, b := filepath.Join(v1, "scripts", "run.sh"), filepath.Join(v2, "scripts", "run.sh")
+	if sameFile(t, a, b) {
+		t.Fatal("expected files with different modes not to share a blob")
+	}
+	if info, _ := os.Stat(a); info.Mode().Perm()&0o111 != 0 {
+		t.Error("expected 1.0.0 to stay non-executable")
+	}
+	if info, _ := os.Stat(b); info.Mode().Perm()&0o111 == 0 {
+		t.Error("expected 1.0.1 to stay executable")
+	}
+}
+
+func TestStoreReplacesModifiedBlob(t *testing.T) {
+	s := store.New(t.TempDir())
+	v1 := addFiles(t, s, "my-skill", "1.0.0", map[string]os.FileMode{"SKILL.md": 0o644})
+	if err := os.WriteFile(filepath.Join(v1, "SKILL.md"), []byte("tampered"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	v2 := addFiles(t, s, "my-skill", "1.0.1", map[string]os.FileMode{"SKILL.md": 0o644})
+
+	data, err := os.ReadFile(filepath.Join(v2, "SKILL.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "contents of SKILL.md" {
+		t.Errorf("expected the new artifact to keep its contents, got %q", data)
+	}
+	digest, _ := store.FileDigest(filepath.Join(v2, "SKILL.md"))
+	if !sameFile(t, filepath.Join(v2, "SKILL.md"), s.BlobPath(digest)) {
+		t.Error("expected the blob to be replaced by the new file")
+	}
+}
+
+func TestStoreMigratesVersion1(t *testing.T) {
+	root := t.TempDir()
+	for _, version := range []string{"1.0.0", "1.0.1"} {
+		dir := filepath.Join(root, "my-skill", version)
+		if err := os.MkdirAll(filepath.Join(dir, "assets"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "assets", "big.bin"), []byte("large asset"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.WriteManifest(filepath.Join(dir, "manifest.json"), store.Manifest{Name: "my-skill", Version: version}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, ".store-version"), []byte("1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := store.New(root)
+	if err := s.Init(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(root, ".store-version"))
+	if string(data) != "2\n" {
+		t.Errorf("expected .store-version 2, got %q", data)
+	}
+	a := filepath.Join(root, "my-skill", "1.0.0", "assets", "big.bin")
+	b := filepath.Join(root, "my-skill", "1.0.1", "assets", "big.bin")
+	if !sameFile(t, a, b) {
+		t.Error("expected migrated copies to share a blob")
+	}
+	if idx, err := store.ReadIndex(filepath.Dir(filepath.Dir(a))); err != nil || len(idx.Files) != 1 {
+		t.Errorf("expected an index for the migrated artifact, got %+v, %v", idx, err)
+	}
+	if manifests, _ := s.List(); len(manifests) != 2 {
+		t.Errorf("expected both artifacts to be listed, got %+v", manifests)
+	}
+}
+
+func TestStoreRejectsBlobsName(t *testing.T) {
+	s := store.New(t.TempDir())
+	if _, err := s.AddFunc(store.BlobsDir, "1.0.0", store.Manifest{}, false, func(string) error { return nil }); err == nil {
+		t.Error("expected error for a skill named after the blob directory, got nil")
+	}
+}
+
+func TestStoreGCUnreferencedBlobs(t *testing.T) {
+	s := store.New(t.TempDir())
+	v1 := addFiles(t, s, "my-skill", "1.0.0", map[string]os.FileMode{"SKILL.md": 0o644, "assets/old.bin": 0o644})
+	addFiles(t, s, "my-skill", "1.0.1", map[string]os.FileMode{"SKILL.md": 0o644})
+	old, _ := store.FileDigest(filepath.Join(v1, "assets", "old.bin"))
+	shared, _ := store.FileDigest(filepath.Join(v1, "SKILL.md"))
+
+	if garbage, _ := s.FindGarbage(); len(garbage) != 0 {
+		t.Fatalf("expected no garbage while every blob is used, got %+v", garbage)
+	}
+	if err := s.Remove("my-skill", "1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	garbage, err := s.FindGarbage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(garbage) != 1 || garbage[0].Path != s.BlobPath(old) || garbage[0].Reason != "unreferenced blob" {
+		t.Fatalf("expected only the old asset's blob, got %+v", garbage)
+	}
+	if err := s.GC(garbage); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(s.BlobPath(shared)); err != nil {
+		t.Errorf("expected the shared blob to survive, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package unit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/oci"
+)
+
+func TestPackSetsAnnotations(t *testing.T) {
+	a, err := oci.Pack(storedSkill(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		oci.AnnotationTitle:       "my-skill",
+		oci.AnnotationVersion:     "1.0.0",
+		oci.AnnotationDescription: "A test skill.",
+		oci.AnnotationAuthors:     "test-author",
+		oci.AnnotationVendor:      "Test <test@example.com>",
+		oci.AnnotationCreated:     "2026-01-01T00:00:00Z",
+	}
+	for key, value := range want {
+		if got := a.Manifest.Annotations[key]; got != value {
+			t.Errorf("annotation %s: expected %q, got %q", key, value, got)
+		}
+	}
+}
+
+func TestValidateArtifactAcceptsPacked(t *testing.T) {
+	a, err := oci.Pack(storedSkill(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := oci.ValidateArtifact(a)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Name != "my-skill" {
+		t.Errorf("expected name 'my-skill', got %q", m.Name)
+	}
+}
+
+func TestValidateArtifactRejectsWrongArtifactType(t *testing.T) {
+	a, err := oci.Pack(storedSkill(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Manifest.ArtifactType = "application/vnd.example.other"
+	if _, err := oci.ValidateArtifact(a); err == nil || !strings.Contains(err.Error(), "artifactType") {
+		t.Fatalf("expected artifactType error, got %v", err)
+	}
+}
+
+func TestValidateArtifactRejectsAnnotationMismatch(t *testing.T) {
+	a, err := oci.Pack(storedSkill(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Manifest.Annotations[oci.AnnotationVersion] = "9.9.9"
+	if _, err := oci.ValidateArtifact(a); err == nil || !strings.Contains(err.Error(), oci.AnnotationVersion) {
+		t.Fatalf("expected annotation mismatch error, got %v", err)
+	}
+}
+
+func TestDecodeConfigRejectsUnknownSchemaVersion(t *testing.T) {
+	data := []byte(`{"manifestVersion":2,"name":"my-skill","version":"1.0.0","description":"d","author":"a","maintainer":"m","buildTimestamp":"2026-01-01T00:00:00Z"}`)
+	if _, err := oci.DecodeConfig(data); err == nil || !strings.Contains(err.Error(), "manifestVersion") {
+		t.Fatalf("expected manifestVersion error, got %v", err)
+	}
+}
+
+func TestDecodeConfigRequiresFields(t *testing.T) {
+	for _, field := range []string{"name", "version", "description", "author", "maintainer", "buildTimestamp"} {
+		config := map[string]interface{}{
+			"manifestVersion": 1,
+			"name":            "my-skill",
+			"version":         "1.0.0",
+			"description":     "d",
+			"author":          "a",
+			"maintainer":      "m",
+			"buildTimestamp":  "2026-01-01T00:00:00Z",
+		}
+		delete(config, field)
+		data, _ := json.Marshal(config)
+		if _, err := oci.DecodeConfig(data); err == nil || !strings.Contains(err.Error(), field) {
+			t.Errorf("expected error for missing %s, got %v", field, err)
+		}
+	}
+}
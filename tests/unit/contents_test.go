@@ -0,0 +1,106 @@
+package unit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/oci"
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+func TestScanContentsGroupsConventionalDirs(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"SKILL.md":              "# Skill\n",
+		"scripts/run.sh":        "echo hi\n",
+		"references/guide.md":   "guide\n",
+		"assets/templates/a.md": "a\n",
+	})
+	if err := os.Chmod(filepath.Join(dir, "scripts", "run.sh"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := store.ScanContents(dir, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.SkillFile != "SKILL.md" {
+		t.Errorf("expected skillFile SKILL.md, got %q", c.SkillFile)
+	}
+	if len(c.Scripts) != 1 || c.Scripts[0].Path != "scripts/run.sh" || !c.Scripts[0].Executable {
+		t.Errorf("unexpected scripts: %+v", c.Scripts)
+	}
+	if c.Scripts[0].Size != 8 || c.Scripts[0].Digest != oci.Digest([]byte("echo hi\n")) {
+		t.Errorf("unexpected script size/digest: %+v", c.Scripts[0])
+	}
+	if len(c.References) != 1 || c.References[0].Path != "references/guide.md" {
+		t.Errorf("unexpected references: %+v", c.References)
+	}
+	if len(c.Assets) != 1 || c.Assets[0].Path != "assets/templates/a.md" {
+		t.Errorf("unexpected assets: %+v", c.Assets)
+	}
+}
+
+func TestScanContentsRejectsUnknownFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"SKILL.md":     "# Skill\n",
+		"install.sh":   "curl | sh\n",
+		"docs/more.md": "more\n",
+	})
+
+	_, err := store.ScanContents(dir, false)
+	var lerr *store.LayoutError
+	if !errors.As(err, &lerr) {
+		t.Fatalf("expected LayoutError, got %v", err)
+	}
+	if strings.Join(lerr.Paths, ",") != "docs/more.md,install.sh" {
+		t.Errorf("unexpected paths: %v", lerr.Paths)
+	}
+
+	c, err := store.ScanContents(dir, true)
+	if err != nil {
+		t.Fatalf("unexpected error with allowOther: %v", err)
+	}
+	if len(c.Other) != 2 {
+		t.Errorf("expected 2 other files, got %+v", c.Other)
+	}
+}
+
+func TestCheckContentsReportsDifferences(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"SKILL.md":            "# Skill\n",
+		"scripts/run.sh":      "echo hi\n",
+		"references/guide.md": "guide\n",
+	})
+	c, err := store.ScanContents(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeTree(t, dir, map[string]string{
+		"scripts/run.sh":   "rm -rf /\n",
+		"assets/new.png":   "png",
+		"manifest.json":    "{}",
+		"scripts/extra.sh": "x",
+	})
+	os.Remove(filepath.Join(dir, "references", "guide.md"))
+
+	problems, err := store.CheckContents(dir, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(problems, "\n")
+	for _, want := range []string{"modified: scripts/run.sh", "missing: references/guide.md", "added: scripts/extra.sh", "added: assets/new.png"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected problem %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "manifest.json") {
+		t.Errorf("manifest.json must not be reported, got:\n%s", got)
+	}
+}
@@ -0,0 +1,172 @@
+package unit
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/cosign"
+	"github.com/c8ab/provenskills/internal/oci"
+	"github.com/c8ab/provenskills/internal/oci/registrytest"
+	"github.com/c8ab/provenskills/internal/signing"
+)
+
+func TestCosignPayloadFormat(t *testing.T) {
+	ref, _ := oci.ParseReference("ghcr.io/acme/skills/my-skill:1.0.0")
+	digest := oci.Digest([]byte("manifest"))
+	key, _ := signing.GenerateKey(signing.AlgorithmECDSAP256)
+
+	sig, err := cosign.Sign(key, ref, digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The payload must match what cosign itself signs byte for byte.
+	want := `{"critical":{"identity":{"docker-reference":"ghcr.io/acme/skills/my-skill"},` +
+		`"image":{"docker-manifest-digest":"` + digest + `"},"type":"cosign container image signature"},"optional":null}`
+	if string(sig.Payload) != want {
+		t.Errorf("unexpected payload:\n got %s\nwant %s", sig.Payload, want)
+	}
+	if tag := cosign.SignatureTag(digest); tag != "sha256-"+digest[len("sha256:"):]+".sig" {
+		t.Errorf("unexpected signature tag %q", tag)
+	}
+}
+
+func TestCosignSignVerify(t *testing.T) {
+	ref, _ := oci.ParseReference("ghcr.io/acme/skills/my-skill:1.0.0")
+	digest := oci.Digest([]byte("manifest"))
+
+	for _, algorithm := range []string{signing.AlgorithmECDSAP256, signing.AlgorithmEd25519} {
+		t.Run(algorithm, func(t *testing.T) {
+			key, _ := signing.GenerateKey(algorithm)
+			other, _ := signing.GenerateKey(algorithm)
+
+			sig, err := cosign.Sign(key, ref, digest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := sig.Verify(key.Public()); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if err := sig.Verify(other.Public()); err == nil {
+				t.Error("expected error for another key, got nil")
+			}
+			if err := sig.Check(digest); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if err := sig.Check(oci.Digest([]byte("other"))); !errors.Is(err, cosign.ErrWrongDigest) {
+				t.Errorf("expected ErrWrongDigest, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCosignPushFetch(t *testing.T) {
+	reg := registrytest.New()
+	defer reg.Close()
+
+	ref, _ := oci.ParseReference(reg.Host() + "/skills/my-skill:1.0.0")
+	digest := oci.Digest([]byte("manifest"))
+	ctx := context.Background()
+	client := oci.NewClient()
+
+	if sigs, err := cosign.Fetch(ctx, client, ref, digest); err != nil || len(sigs) != 0 {
+		t.Fatalf("expected no signatures, got %d, %v", len(sigs), err)
+	}
+
+	var keys []crypto.Signer
+	for range 2 {
+		key, _ := signing.GenerateKey(signing.AlgorithmEd25519)
+		sig, err := cosign.Sign(key, ref, digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Pushing the same signature twice must not duplicate it.
+		for range 2 {
+			if err := cosign.Push(ctx, client, ref, digest, sig); err != nil {
+				t.Fatalf("push failed: %v", err)
+			}
+		}
+		keys = append(keys, key)
+	}
+
+	data, ok := reg.Manifest("skills/my-skill", cosign.SignatureTag(digest))
+	if !ok {
+		t.Fatal("expected a signature manifest under the signature tag")
+	}
+	var m oci.Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Layers) != 2 || m.Layers[0].MediaType != cosign.MediaTypeSimpleSigning {
+		t.Fatalf("unexpected signature manifest: %s", data)
+	}
+
+	sigs, err := cosign.Fetch(ctx, client, ref, digest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sigs) != 2 {
+		t.Fatalf("expected 2 signatures, got %d", len(sigs))
+	}
+	for i, sig := range sigs {
+		if err := sig.Verify(keys[i].Public()); err != nil {
+			t.Errorf("signature %d does not verify: %v", i, err)
+		}
+	}
+}
+
+func TestCosignFetchReferrer(t *testing.T) {
+	reg := registrytest.New()
+	defer reg.Close()
+
+	a, err := oci.Pack(storedSkill(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, _ := oci.ParseReference(reg.Host() + "/skills/my-skill:1.0.0")
+	ctx := context.Background()
+	client := oci.NewClient()
+	if err := client.Push(ctx, ref, a); err != nil {
+		t.Fatal(err)
+	}
+
+	// Newer cosign releases can store signatures as OCI 1.1 referrers.
+	key, _ := signing.GenerateKey(signing.AlgorithmECDSAP256)
+	sig, err := cosign.Sign(key, ref, a.Digest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer := oci.NewDescriptor(cosign.MediaTypeSimpleSigning, sig.Payload)
+	layer.Annotations = map[string]string{cosign.AnnotationSignature: sig.Signature}
+	subject := a.Descriptor()
+	subject.Annotations = nil
+	m := oci.Manifest{
+		SchemaVersion: 2,
+		MediaType:     oci.MediaTypeImageManifest,
+		ArtifactType:  cosign.ArtifactTypeSignature,
+		Config:        oci.NewDescriptor(oci.MediaTypeEmptyJSON, []byte("{}")),
+		Layers:        []oci.Descriptor{layer},
+		Subject:       &subject,
+	}
+	data, _ := json.Marshal(m)
+	referrer := &oci.Artifact{Manifest: m, ManifestBytes: data, Config: []byte("{}"), Layer: sig.Payload}
+	if err := client.PushReferrer(ctx, ref, referrer); err != nil {
+		t.Fatal(err)
+	}
+
+	sigs, err := cosign.Fetch(ctx, client, ref, a.Digest())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sigs) != 1 {
+		t.Fatalf("expected 1 signature, got %d", len(sigs))
+	}
+	if err := sigs[0].Verify(key.Public()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := sigs[0].Check(a.Digest()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package unit
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/diff"
+	"github.com/c8ab/provenskills/internal/skill"
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+func TestUnified(t *testing.T) {
+	a := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\ntwelve\n"
+	b := "one\n2\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\ntwelve\nthirteen"
+	want := `--- a
++++ b
+@@ -1,5 +1,5 @@
+ one
+-two
++2
+ three
+ four
+ five
+@@ -10,3 +10,4 @@
+ ten
+ eleven
+ twelve
++thirteen
+\ No newline at end of file
+`
+	if got := diff.Unified("a", "b", a, b); got != want {
+		t.Errorf("unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := diff.Unified("a", "b", a, a); got != "" {
+		t.Errorf("expected no diff for equal texts, got:\n%s", got)
+	}
+	if got, want := diff.Unified("a", "b", "", "x\n"), "--- a\n+++ b\n@@ -0,0 +1 @@\n+x\n"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestUnifiedRewrite(t *testing.T) {
+	var a, b strings.Builder
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&a, "old %d\n", i)
+		fmt.Fprintf(&b, "new %d\n", i)
+	}
+	got := diff.Unified("a", "b", a.String(), b.String())
+	if !strings.HasPrefix(got, "--- a\n+++ b\n@@ -1,5000 +1,5000 @@\n-old 0\n-old 1\n") || !strings.HasSuffix(got, "+new 4998\n+new 4999\n") {
+		t.Errorf("expected every line to be replaced, deletions first, got %d bytes", len(got))
+	}
+}
+
+func TestCompare(t *testing.T) {
+	from := diff.Side{
+		Label: "my-skill@1.0.0",
+		Manifest: store.Manifest{Name: "my-skill", Version: "1.0.0", Maintainer: "A <a@example.com>", Contents: store.Contents{
+			Scripts: []store.FileEntry{{Path: "scripts/a.sh", Digest: "sha256:aa"}, {Path: "scripts/b.sh", Digest: "sha256:bb"}},
+			Assets:  []store.FileEntry{{Path: "assets/logo.png", Digest: "sha256:cc"}},
+		}},
+		Skill: skill.SkillFrontmatter{Name: "my-skill", AllowedTools: "Read"},
+		Body:  "# Skill\n",
+	}
+	to := diff.Side{
+		Label: "./my-skill",
+		Manifest: store.Manifest{Name: "my-skill", Version: "1.1.0", Contents: store.Contents{
+			Scripts:    []store.FileEntry{{Path: "scripts/a.sh", Digest: "sha256:aa", Executable: true}, {Path: "scripts/b.sh", Digest: "sha256:b2"}},
+			References: []store.FileEntry{{Path: "references/api.md", Digest: "sha256:dd"}},
+		}},
+		Skill:  skill.SkillFrontmatter{Name: "my-skill", AllowedTools: "Read Bash(*)", License: "MIT"},
+		Body:   "# Skill\n",
+		Source: true,
+	}
+
+	r := diff.Compare(from, to)
+	if !reflect.DeepEqual(r.Manifest, []diff.FieldChange{{Field: "version", Old: "1.0.0", New: "1.1.0"}}) {
+		t.Errorf("expected only the version to change, as a source has no maintainer, got %+v", r.Manifest)
+	}
+	if len(r.Frontmatter) != 2 || r.Frontmatter[0].Field != "license" || r.Frontmatter[1].Field != "allowed-tools" {
+		t.Errorf("unexpected frontmatter changes: %+v", r.Frontmatter)
+	}
+	if r.Body != "" {
+		t.Errorf("expected no body diff, got:\n%s", r.Body)
+	}
+
+	var files []string
+	for _, f := range r.Files {
+		files = append(files, f.Status+" "+f.Path)
+	}
+	want := []string{"removed assets/logo.png", "added references/api.md", "modified scripts/a.sh", "modified scripts/b.sh"}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("expected %v, got %v", want, files)
+	}
+	if r.Identical() {
+		t.Error("expected differences")
+	}
+	if !diff.Compare(from, from).Identical() {
+		t.Error("expected a skill to be identical to itself")
+	}
+}
+
+func TestBody(t *testing.T) {
+	if got := skill.Body([]byte("---\nname: x\n---\n\n# Title\n")); got != "\n# Title\n" {
+		t.Errorf("unexpected body %q", got)
+	}
+	if got := skill.Body([]byte("# No frontmatter\n")); got != "# No frontmatter\n" {
+		t.Errorf("unexpected body %q", got)
+	}
+}
+
+func TestRequiredBump(t *testing.T) {
+	base := diff.Side{
+		Label: "my-skill@1.0.0",
+		Manifest: store.Manifest{Name: "my-skill", Version: "1.0.0", Contents: store.Contents{
+			Scripts:    []store.FileEntry{{Path: "scripts/run.sh", Digest: "sha256:aa"}},
+			References: []store.FileEntry{{Path: "references/api.md", Digest: "sha256:bb"}},
+		}},
+		Skill: skill.SkillFrontmatter{Name: "my-skill", Description: "Does things.", AllowedTools: "Read Bash(git status:*)", Compatibility: "claude-code"},
+		Body:  "# Skill\n",
+	}
+
+	tests := []struct {
+		name   string
+		change func(s *diff.Side)
+		want   diff.Bump
+		reason string
+	}{
+		{"nothing", func(s *diff.Side) {}, diff.BumpNone, ""},
+		{"version only", func(s *diff.Side) { s.Manifest.Version = "1.0.1"; s.Skill.Metadata.Version = "1.0.1" }, diff.BumpNone, ""},
+		{"description", func(s *diff.Side) { s.Skill.Description = "Does more things." }, diff.BumpPatch, "description: changed"},
+		{"body", func(s *diff.Side) { s.Body = "# Skill\n\nMore.\n" }, diff.BumpPatch, "SKILL.md: body changed"},
+		{"narrower tools", func(s *diff.Side) { s.Skill.AllowedTools = "Read" }, diff.BumpPatch, "allowed-tools: removes Bash(git status:*)"},
+		{"wider tools", func(s *diff.Side) { s.Skill.AllowedTools = "Read, Bash(git status:*), Write" }, diff.BumpMajor, "allowed-tools: adds Write"},
+		{"compatibility", func(s *diff.Side) { s.Skill.Compatibility = "claude-code>=2" }, diff.BumpMajor, `compatibility: changed from "claude-code" to "claude-code>=2"`},
+		{"added file", func(s *diff.Side) {
+			s.Manifest.Contents.Assets = []store.FileEntry{{Path: "assets/logo.png", Digest: "sha256:cc"}}
+		}, diff.BumpMinor, "assets/logo.png: added"},
+		{"removed reference", func(s *diff.Side) { s.Manifest.Contents.References = nil }, diff.BumpMinor, "references/api.md: removed"},
+		{"removed script", func(s *diff.Side) { s.Manifest.Contents.Scripts = nil }, diff.BumpMajor, "scripts/run.sh: removed"},
+		{"modified script", func(s *diff.Side) {
+			s.Manifest.Contents.Scripts = []store.FileEntry{{Path: "scripts/run.sh", Digest: "sha256:a2"}}
+		}, diff.BumpPatch, "scripts/run.sh: modified"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			to := base
+			to.Manifest.Contents = store.Contents{
+				Scripts:    append([]store.FileEntry(nil), base.Manifest.Contents.Scripts...),
+				References: append([]store.FileEntry(nil), base.Manifest.Contents.References...),
+			}
+			tt.change(&to)
+			got, reasons := diff.RequiredBump(diff.Compare(base, to))
+			if got != tt.want {
+				t.Errorf("expected %s bump, got %s (%v)", tt.want, got, reasons)
+			}
+			if tt.reason == "" {
+				if len(reasons) != 0 {
+					t.Errorf("expected no reasons, got %v", reasons)
+				}
+				return
+			}
+			if len(reasons) == 0 || reasons[0].Change != tt.reason || reasons[0].Bump != tt.want {
+				t.Errorf("expected first reason %q (%s), got %v", tt.reason, tt.want, reasons)
+			}
+		})
+	}
+
+	// The largest bump decides, and its reasons come first
+	to := base
+	to.Body = "# Skill\n\nMore.\n"
+	to.Manifest.Contents = store.Contents{}
+	got, reasons := diff.RequiredBump(diff.Compare(base, to))
+	want := []diff.Reason{
+		{Bump: diff.BumpMajor, Change: "scripts/run.sh: removed"},
+		{Bump: diff.BumpMinor, Change: "references/api.md: removed"},
+		{Bump: diff.BumpPatch, Change: "SKILL.md: body changed"},
+	}
+	if got != diff.BumpMajor || !reflect.DeepEqual(reasons, want) {
+		t.Errorf("expected major bump with %v, got %s with %v", want, got, reasons)
+	}
+}
+
+func TestVersionBump(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     diff.Bump
+	}{
+		{"1.2.3", "1.2.4", diff.BumpPatch},
+		{"1.2.3", "1.3.0", diff.BumpMinor},
+		{"1.2.3", "2.0.0", diff.BumpMajor},
+		{"1.2.3", "1.2.3", diff.BumpNone},
+		{"1.2.3", "1.2.2", diff.BumpNone},
+		{"1.2.3", "1.2.3+build.2", diff.BumpNone},
+		{"0.1.0", "0.1.1", diff.BumpMinor},
+		{"0.1.0", "0.2.0", diff.BumpMajor},
+		{"0.9.0", "1.0.0", diff.BumpMajor},
+		{"1.2.3", "1.2.4-rc.1", diff.BumpPatch},
+		{"2.0.0-rc.1", "2.0.0-rc.2", diff.BumpMajor},
+		{"2.0.0-rc.1", "2.0.0", diff.BumpMajor},
+	}
+	for _, tt := range tests {
+		from, err := skill.ParseVersion(tt.from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		to, err := skill.ParseVersion(tt.to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := diff.VersionBump(from, to); got != tt.want {
+			t.Errorf("VersionBump(%s, %s) = %s, want %s", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
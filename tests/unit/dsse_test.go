@@ -0,0 +1,100 @@
+package unit
+
+import (
+	"crypto"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/dsse"
+	"github.com/c8ab/provenskills/internal/signing"
+)
+
+func TestPAE(t *testing.T) {
+	// Test vector from the DSSE protocol specification.
+	got := string(dsse.PAE("http://example.com/HelloWorld", []byte("hello world")))
+	want := "DSSEv1 29 http://example.com/HelloWorld 11 hello world"
+	if got != want {
+		t.Errorf("PAE = %q, want %q", got, want)
+	}
+}
+
+func TestEnvelopeSignAndVerify(t *testing.T) {
+	for _, algorithm := range []string{signing.AlgorithmECDSAP256, signing.AlgorithmEd25519} {
+		t.Run(algorithm, func(t *testing.T) {
+			key, err := signing.GenerateKey(algorithm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			env, err := dsse.Sign(key, "application/vnd.in-toto+json", []byte(`{"a":1}`))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			data, err := json.Marshal(env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := dsse.Parse(data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := parsed.Verify(key.Public()); err != nil {
+				t.Errorf("expected envelope to verify, got %v", err)
+			}
+			payload, err := parsed.DecodePayload()
+			if err != nil || string(payload) != `{"a":1}` {
+				t.Errorf("unexpected payload %q, %v", payload, err)
+			}
+		})
+	}
+}
+
+func TestEnvelopeVerifyRejects(t *testing.T) {
+	key, err := signing.GenerateKey(signing.AlgorithmEd25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := signing.GenerateKey(signing.AlgorithmEd25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := dsse.Sign(key, "application/vnd.in-toto+json", []byte(`{"a":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := env.Verify(other.Public()); !errors.Is(err, dsse.ErrNoValidSignature) {
+		t.Errorf("expected ErrNoValidSignature for another key, got %v", err)
+	}
+
+	tampered := env
+	tampered.PayloadType = "text/plain"
+	if err := tampered.Verify(key.Public()); err == nil {
+		t.Error("expected error for changed payload type, got nil")
+	}
+
+	if _, err := dsse.Parse([]byte(`{"payloadType":"x","payload":"eA==","signatures":[]}`)); err == nil {
+		t.Error("expected error for unsigned envelope, got nil")
+	}
+}
+
+func TestEnvelopeMultipleSignatures(t *testing.T) {
+	first, _ := signing.GenerateKey(signing.AlgorithmEd25519)
+	second, _ := signing.GenerateKey(signing.AlgorithmECDSAP256)
+	env, err := dsse.Sign(first, "application/vnd.in-toto+json", []byte(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := env.AddSignature(second); err != nil {
+		t.Fatal(err)
+	}
+	if len(env.KeyIDs()) != 2 {
+		t.Fatalf("expected 2 signatures, got %d", len(env.KeyIDs()))
+	}
+	for _, key := range []crypto.Signer{first, second} {
+		if err := env.Verify(key.Public()); err != nil {
+			t.Errorf("expected envelope to verify with each signer, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/fsck"
+	"github.com/c8ab/provenskills/internal/oci"
+	"github.com/c8ab/provenskills/internal/signing"
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+// checkedSkill returns a stored skill with a recorded sourceHash and a
+// signature over its digest.
+func checkedSkill(t *testing.T) store.Entry {
+	t.Helper()
+	dir := storedSkill(t)
+	manifestPath := filepath.Join(dir, "manifest.json")
+	m, err := store.ReadManifest(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.SourceHash, err = store.HashTree(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.WriteManifest(manifestPath, m); err != nil {
+		t.Fatal(err)
+	}
+	artifact, err := oci.Pack(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, sign := newSigner(t)
+	if _, err := signing.Save(dir, sign(artifact.Digest())); err != nil {
+		t.Fatal(err)
+	}
+	return store.Entry{Name: "my-skill", Version: "1.0.0", Path: dir}
+}
+
+// problemChecks returns the check of each problem in r.
+func problemChecks(r fsck.Result) string {
+	var checks []string
+	for _, p := range r.Problems {
+		checks = append(checks, p.Check)
+	}
+	return strings.Join(checks, ",")
+}
+
+func TestFsckHealthyArtifact(t *testing.T) {
+	e := checkedSkill(t)
+	r := fsck.Check(store.New(t.TempDir()), e)
+	if !r.OK() {
+		t.Errorf("expected no problems, got %+v", r.Problems)
+	}
+	if !strings.HasPrefix(r.Digest, "sha256:") {
+		t.Errorf("expected the artifact digest, got %q", r.Digest)
+	}
+}
+
+func TestFsckModifiedFile(t *testing.T) {
+	e := checkedSkill(t)
+	if err := os.WriteFile(filepath.Join(e.Path, "scripts", "run.sh"), []byte("#!/bin/sh\nrm -rf /\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	r := fsck.Check(store.New(t.TempDir()), e)
+	if got := problemChecks(r); got != "sourceHash,signature" {
+		t.Errorf("expected sourceHash and signature problems, got %+v", r.Problems)
+	}
+}
+
+func TestFsckManifestProblems(t *testing.T) {
+	e := checkedSkill(t)
+	e.Version = "2.0.0"
+	r := fsck.Check(store.New(t.TempDir()), e)
+	if got := problemChecks(r); got != "manifest" || !strings.Contains(r.Problems[0].Message, "stored as my-skill@2.0.0") {
+		t.Errorf("expected a manifest mismatch, got %+v", r.Problems)
+	}
+
+	if err := os.Remove(filepath.Join(e.Path, "manifest.json")); err != nil {
+		t.Fatal(err)
+	}
+	if got := problemChecks(fsck.Check(store.New(t.TempDir()), e)); got != "manifest" {
+		t.Errorf("expected only a manifest problem, got %q", got)
+	}
+}
+
+func TestFsckRecordedContents(t *testing.T) {
+	dir, m := scannedSkill(t, map[string]string{"run.py": "print('hi')\n"})
+	if err := store.WriteManifest(filepath.Join(dir, "manifest.json"), m); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "scripts", "run.py")); err != nil {
+		t.Fatal(err)
+	}
+	r := fsck.Check(store.New(t.TempDir()), store.Entry{Name: "my-skill", Version: "1.0.0", Path: dir})
+	if len(r.Problems) != 1 || r.Problems[0].String() != "contents: missing: scripts/run.py" {
+		t.Errorf("expected the missing script, got %+v", r.Problems)
+	}
+}
@@ -0,0 +1,94 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestHashTreeIgnoresManifestAndMetadata(t *testing.T) {
+	a, b := t.TempDir(), t.TempDir()
+	files := map[string]string{
+		"SKILL.md":            "# Skill\n",
+		"references/guide.md": "guide\n",
+	}
+	writeTree(t, a, files)
+	writeTree(t, b, files)
+	writeTree(t, b, map[string]string{"manifest.json": "{}\n"})
+	if err := os.Chmod(filepath.Join(b, "SKILL.md"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	hashA, err := store.HashTree(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashB, err := store.HashTree(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashA != hashB {
+		t.Errorf("expected equal hashes, got %s and %s", hashA, hashB)
+	}
+}
+
+func TestHashTreeDetectsChanges(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"SKILL.md":       "# Skill\n",
+		"scripts/run.sh": "echo hi\n",
+	})
+	base, err := store.HashTree(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Executable bit
+	if err := os.Chmod(filepath.Join(dir, "scripts", "run.sh"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	modeChanged, err := store.HashTree(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modeChanged == base {
+		t.Error("expected hash to change when a file becomes executable")
+	}
+
+	// Content
+	writeTree(t, dir, map[string]string{"SKILL.md": "# Changed\n"})
+	contentChanged, err := store.HashTree(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contentChanged == modeChanged {
+		t.Error("expected hash to change when file content changes")
+	}
+
+	// Rename
+	if err := os.Rename(filepath.Join(dir, "scripts"), filepath.Join(dir, "bin")); err != nil {
+		t.Fatal(err)
+	}
+	renamed, err := store.HashTree(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renamed == contentChanged {
+		t.Error("expected hash to change when a file is renamed")
+	}
+}
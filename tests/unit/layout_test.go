@@ -0,0 +1,266 @@
+package unit
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/oci"
+)
+
+func TestWriteLayout(t *testing.T) {
+	a, err := oci.Pack(storedSkill(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(t.TempDir(), "layout")
+
+	if err := oci.WriteLayout(dir, a, "1.0.0", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "oci-layout"))
+	if err != nil || !strings.Contains(string(data), `"imageLayoutVersion":"1.0.0"`) {
+		t.Errorf("unexpected oci-layout file: %s (%v)", data, err)
+	}
+
+	index := readIndex(t, filepath.Join(dir, "index.json"))
+	if len(index.Manifests) != 1 || index.Manifests[0].Digest != a.Digest() {
+		t.Fatalf("unexpected index manifests: %+v", index.Manifests)
+	}
+	if index.Manifests[0].Annotations[oci.AnnotationRefName] != "1.0.0" {
+		t.Errorf("expected ref name annotation 1.0.0, got %v", index.Manifests[0].Annotations)
+	}
+
+	for _, d := range []string{a.Digest(), a.Manifest.Config.Digest, a.Manifest.Layers[0].Digest} {
+		blob, err := os.ReadFile(filepath.Join(dir, "blobs", "sha256", strings.TrimPrefix(d, "sha256:")))
+		if err != nil {
+			t.Errorf("blob %s missing: %v", d, err)
+			continue
+		}
+		if oci.Digest(blob) != d {
+			t.Errorf("blob %s has wrong content", d)
+		}
+	}
+}
+
+func TestWriteLayoutMergesIndex(t *testing.T) {
+	a, err := oci.Pack(storedSkill(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	if err := oci.WriteLayout(dir, a, "1.0.0", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := oci.WriteLayout(dir, a, "latest", nil); err != nil {
+		t.Fatalf("unexpected error adding to existing layout: %v", err)
+	}
+
+	index := readIndex(t, filepath.Join(dir, "index.json"))
+	if len(index.Manifests) != 1 {
+		t.Fatalf("expected same digest to be replaced, got %d entries", len(index.Manifests))
+	}
+	if index.Manifests[0].Annotations[oci.AnnotationRefName] != "latest" {
+		t.Errorf("expected ref name to be updated, got %v", index.Manifests[0].Annotations)
+	}
+}
+
+func TestWriteLayoutRejectsNonEmptyDir(t *testing.T) {
+	a, err := oci.Pack(storedSkill(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "other.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := oci.WriteLayout(dir, a, "1.0.0", nil); err == nil {
+		t.Fatal("expected error for non-empty directory, got nil")
+	}
+}
+
+func TestWriteLayoutTar(t *testing.T) {
+	a, err := oci.Pack(storedSkill(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := oci.WriteLayoutTar(&buf, a, "1.0.0", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(&buf)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(tr)
+		files[hdr.Name] = data
+	}
+
+	for _, name := range []string{"oci-layout", "index.json", "blobs/sha256/" + strings.TrimPrefix(a.Digest(), "sha256:")} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("tarball missing %s", name)
+		}
+	}
+}
+
+func TestOpenLayoutRejectsOversizedTarEntry(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{Name: "blobs/sha256/huge", Mode: 0o644, Size: oci.MaxFileSize + 1, Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	tw.Flush()
+	path := filepath.Join(t.TempDir(), "layout.tar")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := oci.OpenLayout(path); !errors.Is(err, oci.ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+}
+
+func readIndex(t *testing.T, path string) oci.Index {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read index.json: %v", err)
+	}
+	var index oci.Index
+	if err := json.Unmarshal(data, &index); err != nil {
+		t.Fatalf("index.json is not valid JSON: %v", err)
+	}
+	return index
+}
+
+func TestOpenLayoutRoundTrip(t *testing.T) {
+	a, err := oci.Pack(storedSkill(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := oci.WriteLayout(dir, a, "1.0.0", nil); err != nil {
+		t.Fatal(err)
+	}
+	tarPath := filepath.Join(t.TempDir(), "skill.tar")
+	f, err := os.Create(tarPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := oci.WriteLayoutTar(f, a, "1.0.0", nil); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	for _, path := range []string{dir, tarPath} {
+		layout, err := oci.OpenLayout(path)
+		if err != nil {
+			t.Fatalf("OpenLayout(%s): %v", path, err)
+		}
+		skills, err := layout.Skills()
+		if err != nil {
+			t.Fatalf("Skills(%s): %v", path, err)
+		}
+		if len(skills) != 1 || skills[0].Digest() != a.Digest() {
+			t.Errorf("unexpected skills from %s: %d", path, len(skills))
+		}
+	}
+}
+
+func TestOpenLayoutDetectsTamperedBlob(t *testing.T) {
+	a, err := oci.Pack(storedSkill(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := oci.WriteLayout(dir, a, "1.0.0", nil); err != nil {
+		t.Fatal(err)
+	}
+	layerPath := filepath.Join(dir, "blobs", "sha256", strings.TrimPrefix(a.Manifest.Layers[0].Digest, "sha256:"))
+	data, err := os.ReadFile(layerPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-1] ^= 0xff
+	if err := os.WriteFile(layerPath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	layout, err := oci.OpenLayout(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := layout.Skills(); err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Fatalf("expected digest mismatch error, got %v", err)
+	}
+}
+
+func TestLayoutReferrers(t *testing.T) {
+	a, err := oci.Pack(storedSkill(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := oci.NewReferrer(a.Descriptor(), oci.ArtifactTypeSignature, oci.MediaTypeSignature, []byte(`{"n":1}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	att, err := oci.NewReferrer(a.Descriptor(), oci.ArtifactTypeAttestation, oci.MediaTypeDSSEEnvelope, []byte(`{"n":2}`),
+		map[string]string{oci.AnnotationPredicateType: "https://slsa.dev/provenance/v1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	referrers := []*oci.Artifact{sig, att}
+
+	dir := t.TempDir()
+	tarPath := filepath.Join(t.TempDir(), "skill.tar")
+	if err := oci.WriteLayout(dir, a, "1.0.0", referrers); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(tarPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := oci.WriteLayoutTar(f, a, "1.0.0", referrers); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	for _, path := range []string{dir, tarPath} {
+		layout, err := oci.OpenLayout(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		skills, err := layout.Skills()
+		if err != nil || len(skills) != 1 {
+			t.Fatalf("expected referrers not to be listed as skills, got %d, %v", len(skills), err)
+		}
+		got, err := layout.Referrers(a.Digest())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("expected 2 referrers in %s, got %d", path, len(got))
+		}
+		if string(got[0].Layer) != `{"n":1}` || got[1].Manifest.Annotations[oci.AnnotationPredicateType] == "" {
+			t.Errorf("unexpected referrers from %s", path)
+		}
+		if none, _ := layout.Referrers(oci.Digest([]byte("other"))); len(none) != 0 {
+			t.Errorf("expected no referrers for another subject, got %d", len(none))
+		}
+	}
+}
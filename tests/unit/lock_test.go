@@ -0,0 +1,82 @@
+package unit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+func TestStoreLockTimeout(t *testing.T) {
+	t.Setenv("PSK_LOCK_TIMEOUT", "100ms")
+	root := t.TempDir()
+	unlock, err := store.New(root).Lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A second handle stands in for another process: flock locks of
+	// separate opens of a file conflict even within one process.
+	other := store.New(root)
+	_, err = other.AddFunc("my-skill", "1.0.0", store.Manifest{Name: "my-skill", Version: "1.0.0"}, false, func(dir string) error {
+		t.Error("populate ran while the store was locked")
+		return nil
+	})
+	var lerr *store.LockedError
+	if !errors.As(err, &lerr) {
+		t.Fatalf("expected *LockedError, got %v", err)
+	}
+	if lerr.PID != os.Getpid() || lerr.Skill != "my-skill@1.0.0" {
+		t.Errorf("unexpected error: %+v", lerr)
+	}
+	want := fmt.Sprintf("store is locked by pid %d (writing my-skill@1.0.0)", os.Getpid())
+	if err.Error() != want {
+		t.Errorf("expected %q, got %q", want, err.Error())
+	}
+	if _, err := other.Lock(); !errors.As(err, &lerr) || lerr.Skill != "" {
+		t.Errorf("expected the store lock to time out, got %v", err)
+	}
+
+	unlock()
+	addSkill(t, other, "my-skill", "1.0.0")
+}
+
+func TestStoreConcurrentForceAdd(t *testing.T) {
+	root := t.TempDir()
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s := store.New(root)
+			m := store.Manifest{Name: "my-skill", Version: "1.0.0"}
+			_, err := s.AddFunc("my-skill", "1.0.0", m, true, func(dir string) error {
+				return os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(fmt.Sprintf("---\nname: my-skill\n---\n%d\n", i)), 0o644)
+			})
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(root, "my-skill"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "1.0.0" {
+		t.Errorf("expected only 1.0.0 to remain, got %v", entries)
+	}
+	if _, err := store.ReadManifest(filepath.Join(root, "my-skill", "1.0.0", "manifest.json")); err != nil {
+		t.Errorf("expected a complete artifact, got %v", err)
+	}
+}
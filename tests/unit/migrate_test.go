@@ -0,0 +1,141 @@
+package unit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+// version1Store writes a version 1 store holding my-skill@1.0.0 and
+// returns its root.
+func version1Store(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	dir := filepath.Join(root, "my-skill", "1.0.0")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("---\nname: my-skill\n---\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.WriteManifest(filepath.Join(dir, "manifest.json"), store.Manifest{Name: "my-skill", Version: "1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".store-version"), []byte("1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestStoreOpenMigratesWithBackup(t *testing.T) {
+	root := version1Store(t)
+	s := store.New(root)
+
+	pending, version, err := s.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 1 || len(pending) != 1 || pending[0].From != 1 {
+		t.Fatalf("unexpected pending migrations from version %d: %+v", version, pending)
+	}
+
+	backup, err := s.Open()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if backup != s.BackupPath(1) {
+		t.Errorf("expected backup in %s, got %q", s.BackupPath(1), backup)
+	}
+	if v, _ := s.ReadVersion(); v != store.Version {
+		t.Errorf("expected version %d, got %d", store.Version, v)
+	}
+	if _, err := os.Stat(filepath.Join(backup, "my-skill", "1.0.0", "SKILL.md")); err != nil {
+		t.Errorf("expected the backup to hold the artifact, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(backup, "my-skill", "1.0.0", store.IndexFile)); !os.IsNotExist(err) {
+		t.Errorf("expected the backup to predate the migration, got %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(backup, ".store-version"))
+	if string(data) != "1\n" {
+		t.Errorf("expected the backup to record version 1, got %q", data)
+	}
+
+	// Rewriting metadata in the store leaves the backup alone
+	manifest := filepath.Join(root, "my-skill", "1.0.0", "manifest.json")
+	if err := store.WriteManifest(manifest, store.Manifest{Name: "my-skill", Version: "1.0.0", Description: "changed"}); err != nil {
+		t.Fatal(err)
+	}
+	if m, _ := store.ReadManifest(filepath.Join(backup, "my-skill", "1.0.0", "manifest.json")); m.Description != "" {
+		t.Error("expected the backup manifest to be unchanged")
+	}
+
+	if manifests, _ := s.List(); len(manifests) != 1 {
+		t.Errorf("expected the backup not to be listed, got %+v", manifests)
+	}
+	if backup, err := s.Open(); err != nil || backup != "" {
+		t.Errorf("expected a migrated store to open as is, got %q, %v", backup, err)
+	}
+}
+
+func TestStoreOpenResumesMigration(t *testing.T) {
+	root := version1Store(t)
+	s := store.New(root)
+	if _, err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crash after the backup but before the version was recorded
+	if err := os.Remove(filepath.Join(root, "my-skill", "1.0.0", store.IndexFile)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".store-version"), []byte("1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Open(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := store.ReadIndex(filepath.Join(root, "my-skill", "1.0.0")); err != nil {
+		t.Errorf("expected the resumed migration to write the index, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(s.BackupPath(1), "my-skill", "1.0.0", store.IndexFile)); !os.IsNotExist(err) {
+		t.Errorf("expected the original backup to be kept, got %v", err)
+	}
+}
+
+func TestStoreRefusesNewerVersion(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ".store-version"), []byte("99\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := store.New(root)
+	if _, err := s.Open(); !errors.Is(err, store.ErrNewerVersion) {
+		t.Errorf("expected ErrNewerVersion, got %v", err)
+	}
+	if err := s.Init(); !errors.Is(err, store.ErrNewerVersion) {
+		t.Errorf("expected Init to refuse a newer store, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, ".store-version"), []byte("two\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ReadVersion(); err == nil {
+		t.Error("expected error for an invalid .store-version, got nil")
+	}
+}
+
+func TestStoreInitWritesCurrentVersion(t *testing.T) {
+	s := store.New(filepath.Join(t.TempDir(), "store"))
+	if v, err := s.ReadVersion(); err != nil || v != 0 {
+		t.Errorf("expected version 0 for a missing store, got %d, %v", v, err)
+	}
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := s.ReadVersion(); v != store.Version {
+		t.Errorf("expected version %d, got %d", store.Version, v)
+	}
+}
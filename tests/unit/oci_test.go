@@ -0,0 +1,249 @@
+package unit
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/oci"
+	"github.com/c8ab/provenskills/internal/oci/registrytest"
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+func TestParseReferenceWithTag(t *testing.T) {
+	ref, err := oci.ParseReference("ghcr.io/acme/skills/code-review:1.0.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ref.Registry != "ghcr.io" || ref.Repository != "acme/skills/code-review" || ref.Tag != "1.0.0" {
+		t.Errorf("unexpected reference: %+v", ref)
+	}
+}
+
+func TestParseReferenceWithPortAndDigest(t *testing.T) {
+	digest := oci.Digest([]byte("x"))
+	ref, err := oci.ParseReference("localhost:5000/skills@" + digest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ref.Registry != "localhost:5000" || ref.Repository != "skills" || ref.Tag != "" || ref.Digest != digest {
+		t.Errorf("unexpected reference: %+v", ref)
+	}
+}
+
+func TestParseReferenceInvalid(t *testing.T) {
+	for _, s := range []string{"skills", "ghcr.io/", "ghcr.io/Upper/case:1", "ghcr.io/acme@sha256:abc"} {
+		if _, err := oci.ParseReference(s); err == nil {
+			t.Errorf("expected error for %q, got nil", s)
+		}
+	}
+}
+
+func TestPackArtifact(t *testing.T) {
+	dir := storedSkill(t)
+
+	a, err := oci.Pack(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a.Manifest.ArtifactType != oci.ArtifactTypeSkill {
+		t.Errorf("expected artifactType %q, got %q", oci.ArtifactTypeSkill, a.Manifest.ArtifactType)
+	}
+	if a.Manifest.Config.MediaType != oci.MediaTypeSkillConfig {
+		t.Errorf("expected config media type %q, got %q", oci.MediaTypeSkillConfig, a.Manifest.Config.MediaType)
+	}
+	if len(a.Manifest.Layers) != 1 || a.Manifest.Layers[0].Digest != oci.Digest(a.Layer) {
+		t.Errorf("layer descriptor does not match layer blob: %+v", a.Manifest.Layers)
+	}
+
+	var m store.Manifest
+	if err := json.Unmarshal(a.Config, &m); err != nil {
+		t.Fatalf("config is not a store manifest: %v", err)
+	}
+	if m.Name != "my-skill" || m.Version != "1.0.0" {
+		t.Errorf("unexpected config: %+v", m)
+	}
+}
+
+func TestClientPush(t *testing.T) {
+	reg := registrytest.New()
+	defer reg.Close()
+
+	a, err := oci.Pack(storedSkill(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := oci.ParseReference(reg.Host() + "/skills/my-skill:1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := oci.NewClient().Push(context.Background(), ref, a); err != nil {
+		t.Fatalf("push failed: %v", err)
+	}
+
+	got, ok := reg.Manifest("skills/my-skill", "1.0.0")
+	if !ok {
+		t.Fatal("manifest not found in registry")
+	}
+	if oci.Digest(got) != a.Digest() {
+		t.Errorf("expected manifest digest %s, got %s", a.Digest(), oci.Digest(got))
+	}
+	for _, d := range []string{a.Manifest.Config.Digest, a.Manifest.Layers[0].Digest} {
+		if _, ok := reg.Blob(d); !ok {
+			t.Errorf("blob %s not found in registry", d)
+		}
+	}
+}
+
+func TestClientPullRoundTrip(t *testing.T) {
+	reg := registrytest.New()
+	defer reg.Close()
+
+	a, err := oci.Pack(storedSkill(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := oci.ParseReference(reg.Host() + "/skills/my-skill:1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := oci.NewClient()
+	if err := client.Push(context.Background(), ref, a); err != nil {
+		t.Fatalf("push failed: %v", err)
+	}
+
+	pulled, err := client.Pull(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("pull failed: %v", err)
+	}
+	if pulled.Digest() != a.Digest() {
+		t.Errorf("expected digest %s, got %s", a.Digest(), pulled.Digest())
+	}
+
+	dst := t.TempDir()
+	if err := oci.UnpackLayer(pulled.Layer, dst); err != nil {
+		t.Fatalf("unpack failed: %v", err)
+	}
+	info, err := os.Stat(filepath.Join(dst, "scripts", "run.sh"))
+	if err != nil {
+		t.Fatalf("scripts/run.sh not unpacked: %v", err)
+	}
+	if info.Mode().Perm()&0o100 == 0 {
+		t.Errorf("expected scripts/run.sh to be executable, got mode %v", info.Mode())
+	}
+	if _, err := os.Stat(filepath.Join(dst, "manifest.json")); !os.IsNotExist(err) {
+		t.Errorf("expected manifest.json to be excluded from the layer")
+	}
+}
+
+func TestClientPullNotFound(t *testing.T) {
+	reg := registrytest.New()
+	defer reg.Close()
+
+	ref, err := oci.ParseReference(reg.Host() + "/skills/missing:1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = oci.NewClient().Pull(context.Background(), ref)
+	if !errors.Is(err, oci.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestClientGetBlobRejectsDeclaredSize(t *testing.T) {
+	reg := registrytest.New()
+	defer reg.Close()
+
+	ref, err := oci.ParseReference(reg.Host() + "/skills/my-skill:1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc := oci.NewDescriptor(oci.MediaTypeSkillLayer, []byte("layer"))
+	desc.Size = oci.MaxArchiveSize + 1
+	if _, err := oci.NewClient().GetBlob(context.Background(), ref, desc); !errors.Is(err, oci.ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+	desc.Size = -1
+	if _, err := oci.NewClient().GetBlob(context.Background(), ref, desc); err == nil || errors.Is(err, oci.ErrNotFound) {
+		t.Errorf("expected an invalid size error, got %v", err)
+	}
+}
+
+func TestUnpackLayerRejectsTraversal(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	content := []byte("pwned")
+	if err := tw.WriteHeader(&tar.Header{Name: "../evil.txt", Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	tw.Write(content)
+	tw.Close()
+	gz.Close()
+
+	dst := filepath.Join(t.TempDir(), "out")
+	if err := oci.UnpackLayer(buf.Bytes(), dst); err == nil {
+		t.Fatal("expected error for path traversal entry, got nil")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dst), "evil.txt")); !os.IsNotExist(err) {
+		t.Error("traversal entry was written outside the destination")
+	}
+}
+
+func TestUnpackLayerRejectsOversizedFile(t *testing.T) {
+	// Only the header is written: the size alone must be refused
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: "assets/huge.bin", Mode: 0o644, Size: oci.MaxFileSize + 1, Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	tw.Flush()
+	gz.Close()
+
+	dst := t.TempDir()
+	err := oci.UnpackLayer(buf.Bytes(), dst)
+	if !errors.Is(err, oci.ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "assets", "huge.bin")); !os.IsNotExist(err) {
+		t.Error("oversized entry was written")
+	}
+}
+
+// storedSkill creates a directory laid out like a store artifact.
+func storedSkill(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	skillMD := "---\nname: my-skill\ndescription: A test skill.\nmetadata:\n  version: \"1.0.0\"\n  author: \"test-author\"\n---\n\n# My Skill\n"
+	if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(skillMD), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "scripts"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "scripts", "run.sh"), []byte("#!/bin/sh\necho hi\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	m := store.Manifest{
+		ManifestVersion: 1,
+		Name:            "my-skill",
+		Version:         "1.0.0",
+		Description:     "A test skill.",
+		Author:          "test-author",
+		Maintainer:      "Test <test@example.com>",
+		BuildTimestamp:  "2026-01-01T00:00:00Z",
+		Contents:        store.Contents{SkillFile: "SKILL.md"},
+	}
+	if err := store.WriteManifest(filepath.Join(dir, "manifest.json"), m); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
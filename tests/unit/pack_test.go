@@ -0,0 +1,93 @@
+package unit
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/c8ab/provenskills/internal/oci"
+)
+
+func TestPackLayerDeterministic(t *testing.T) {
+	a := storedSkill(t)
+	b := storedSkill(t)
+
+	// Differing timestamps and permissions must not affect the layer
+	old := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(b, "SKILL.md"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(b, "SKILL.md"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(b, "scripts", "run.sh"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	layerA, err := oci.PackLayer(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layerB, err := oci.PackLayer(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(layerA, layerB) {
+		t.Fatalf("expected identical layers, got digests %s and %s", oci.Digest(layerA), oci.Digest(layerB))
+	}
+}
+
+func TestPackLayerNormalizesHeaders(t *testing.T) {
+	layer, err := oci.PackLayer(storedSkill(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(layer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gz.ModTime.IsZero() || gz.Name != "" {
+		t.Errorf("expected empty gzip header, got name %q mtime %v", gz.Name, gz.ModTime)
+	}
+
+	var names []string
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+		if hdr.Uid != 0 || hdr.Gid != 0 || hdr.Uname != "" || hdr.Gname != "" {
+			t.Errorf("%s: expected cleared ownership, got %d:%d %s:%s", hdr.Name, hdr.Uid, hdr.Gid, hdr.Uname, hdr.Gname)
+		}
+		if !hdr.ModTime.Equal(time.Unix(0, 0)) {
+			t.Errorf("%s: expected epoch mtime, got %v", hdr.Name, hdr.ModTime)
+		}
+		if hdr.Name == "scripts/run.sh" && hdr.Mode != 0o755 {
+			t.Errorf("expected scripts/run.sh mode 0755, got %o", hdr.Mode)
+		}
+		if hdr.Name == "SKILL.md" && hdr.Mode != 0o644 {
+			t.Errorf("expected SKILL.md mode 0644, got %o", hdr.Mode)
+		}
+	}
+
+	want := []string{"SKILL.md", "scripts/", "scripts/run.sh"}
+	if len(names) != len(want) {
+		t.Fatalf("expected entries %v, got %v", want, names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("expected entries %v, got %v", want, names)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/policy"
+	"github.com/c8ab/provenskills/internal/skill"
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+func TestPolicyParse(t *testing.T) {
+	p, err := policy.Parse([]byte(`
+signers:
+  require: true
+  identities: ["*@example.com"]
+provenance:
+  builders: ["https://ci.example.com/*"]
+licenses:
+  allow: [MIT, Apache-2.0]
+allowed-tools:
+  deny: ["Bash(*)"]
+maintainers:
+  allow: ["*@example.com"]
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !p.Signers.Require || len(p.Provenance.Builders) != 1 || len(p.Licenses.Allow) != 2 || p.AllowedTools.Deny[0] != "Bash(*)" {
+		t.Errorf("unexpected policy: %+v", p)
+	}
+
+	if _, err := policy.Parse([]byte("licences:\n  allow: [MIT]\n")); err == nil {
+		t.Error("expected error for unknown key, got nil")
+	}
+	if _, err := policy.Parse(nil); err != nil {
+		t.Errorf("expected empty policy to parse, got %v", err)
+	}
+}
+
+func TestPolicyFind(t *testing.T) {
+	t.Setenv("PSK_POLICY", "")
+	t.Setenv("HOME", t.TempDir())
+	project := t.TempDir()
+	sub := filepath.Join(project, "a", "b")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := policy.Find(sub); got != "" {
+		t.Errorf("expected no policy, got %q", got)
+	}
+
+	path := filepath.Join(project, ".psk", "policy.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := policy.Find(sub); got != path {
+		t.Errorf("expected %q, got %q", path, got)
+	}
+
+	t.Setenv("PSK_POLICY", "/etc/psk.yaml")
+	if got := policy.Find(sub); got != "/etc/psk.yaml" {
+		t.Errorf("expected PSK_POLICY to take precedence, got %q", got)
+	}
+}
+
+// policyInput returns an input for a skill with the given license and
+// allowed-tools.
+func policyInput(license, tools string) policy.Input {
+	return policy.Input{
+		Manifest: store.Manifest{Name: "my-skill", Version: "1.0.0", Maintainer: "Alice <alice@example.com>"},
+		Skill:    skill.SkillFrontmatter{Name: "my-skill", License: license, AllowedTools: tools},
+	}
+}
+
+// violatedRules returns the rule of each violation.
+func violatedRules(vs []policy.Violation) []string {
+	rules := []string{}
+	for _, v := range vs {
+		rules = append(rules, v.Rule)
+	}
+	return rules
+}
+
+func TestPolicyEvaluateSigners(t *testing.T) {
+	p := policy.Policy{Signers: policy.SignerRule{Identities: []string{"*@example.com"}, RequireMaintainer: true}}
+
+	in := policyInput("", "")
+	vs := policy.Evaluate(p, in)
+	if len(vs) != 1 || vs[0].Rule != "signers.require" || vs[0].Field != "signatures" {
+		t.Errorf("expected missing signature violation, got %+v", vs)
+	}
+
+	in.Signers = []string{"Bob <bob@other.org>"}
+	if got := violatedRules(policy.Evaluate(p, in)); !reflect.DeepEqual(got, []string{"signers.identities", "signers.require-maintainer"}) {
+		t.Errorf("unexpected violations: %v", got)
+	}
+
+	in.Signers = append(in.Signers, "Alice <ALICE@example.com>")
+	if vs := policy.Evaluate(p, in); len(vs) != 0 {
+		t.Errorf("expected no violations, got %+v", vs)
+	}
+}
+
+func TestPolicyEvaluateProvenance(t *testing.T) {
+	p := policy.Policy{Provenance: policy.ProvenanceRule{Builders: []string{"https://ci.example.com/*"}}}
+
+	in := policyInput("", "")
+	if got := violatedRules(policy.Evaluate(p, in)); !reflect.DeepEqual(got, []string{"provenance.require"}) {
+		t.Errorf("unexpected violations: %v", got)
+	}
+	in.Builders = []string{"https://laptop.local/builder"}
+	vs := policy.Evaluate(p, in)
+	if len(vs) != 1 || vs[0].Field != "provenance.runDetails.builder.id" || vs[0].Value != "https://laptop.local/builder" {
+		t.Errorf("unexpected violations: %+v", vs)
+	}
+	in.Builders = []string{"https://ci.example.com/builder"}
+	if vs := policy.Evaluate(p, in); len(vs) != 0 {
+		t.Errorf("expected no violations, got %+v", vs)
+	}
+}
+
+func TestPolicyEvaluateLicenses(t *testing.T) {
+	p := policy.Policy{Licenses: policy.ListRule{Allow: []string{"MIT", "Apache-2.0"}, Deny: []string{"GPL-*"}}}
+
+	tests := []struct {
+		license string
+		want    []string
+	}{
+		{"MIT", []string{}},
+		{"mit", []string{}},
+		{"GPL-3.0-only OR MIT", []string{}},
+		{"(MIT AND Apache-2.0)", []string{}},
+		{"MIT AND BSD-3-Clause", []string{"licenses.allow"}},
+		{"GPL-3.0-only", []string{"licenses.deny", "licenses.allow"}},
+		{"", []string{"licenses.allow"}},
+	}
+	for _, tt := range tests {
+		vs := policy.Evaluate(p, policyInput(tt.license, ""))
+		if got := violatedRules(vs); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("license %q: expected %v, got %v", tt.license, tt.want, got)
+		}
+		for _, v := range vs {
+			if v.Field != "license" {
+				t.Errorf("license %q: expected field license, got %q", tt.license, v.Field)
+			}
+		}
+	}
+}
+
+func TestPolicyEvaluateAllowedTools(t *testing.T) {
+	p := policy.Policy{AllowedTools: policy.ListRule{Allow: []string{"Read", "Grep", "Bash(git *)"}, Deny: []string{"Bash(git push*)"}}}
+
+	vs := policy.Evaluate(p, policyInput("", "Read Bash(git status:*) Bash(git push:*), WebFetch"))
+	if len(vs) != 2 {
+		t.Fatalf("expected 2 violations, got %+v", vs)
+	}
+	if vs[0].Rule != "allowed-tools.deny" || vs[0].Value != "Bash(git push:*)" {
+		t.Errorf("unexpected first violation: %+v", vs[0])
+	}
+	if vs[1].Rule != "allowed-tools.allow" || vs[1].Field != "allowed-tools" || vs[1].Value != "WebFetch" {
+		t.Errorf("unexpected second violation: %+v", vs[1])
+	}
+}
+
+func TestPolicyEvaluateMaintainers(t *testing.T) {
+	p := policy.Policy{Maintainers: policy.ListRule{Deny: []string{"*@example.com"}}}
+
+	vs := policy.Evaluate(p, policyInput("", ""))
+	if len(vs) != 1 || vs[0].Rule != "maintainers.deny" || vs[0].Field != "maintainer" {
+		t.Errorf("unexpected violations: %+v", vs)
+	}
+	if vs := policy.Evaluate(policy.Policy{}, policyInput("", "")); len(vs) != 0 {
+		t.Errorf("expected empty policy to admit everything, got %+v", vs)
+	}
+}
+
+func TestSplitTools(t *testing.T) {
+	got := policy.SplitTools("Read, Bash(git add:*)  Bash(npm run test)\tGrep")
+	want := []string{"Read", "Bash(git add:*)", "Bash(npm run test)", "Grep"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,91 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/oci"
+	"github.com/c8ab/provenskills/internal/oci/registrytest"
+)
+
+func TestReferrersRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		disableAPI bool
+	}{
+		{"referrers API", false},
+		{"tag schema fallback", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			reg := registrytest.New()
+			defer reg.Close()
+			reg.DisableReferrersAPI = tc.disableAPI
+
+			a, err := oci.Pack(storedSkill(t))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ref, err := oci.ParseReference(reg.Host() + "/skills/my-skill:1.0.0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.Background()
+			client := oci.NewClient()
+			if err := client.Push(ctx, ref, a); err != nil {
+				t.Fatalf("push failed: %v", err)
+			}
+
+			descs, err := client.Referrers(ctx, ref, a.Digest(), oci.ArtifactTypeSignature)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(descs) != 0 {
+				t.Fatalf("expected no referrers, got %d", len(descs))
+			}
+
+			for _, payload := range []string{`{"n":1}`, `{"n":2}`} {
+				r, err := oci.NewReferrer(a.Descriptor(), oci.ArtifactTypeSignature, oci.MediaTypeSignature, []byte(payload), nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := client.PushReferrer(ctx, ref, r); err != nil {
+					t.Fatalf("push referrer failed: %v", err)
+				}
+				// Pushing the same referrer twice must not duplicate it.
+				if err := client.PushReferrer(ctx, ref, r); err != nil {
+					t.Fatalf("push referrer failed: %v", err)
+				}
+			}
+
+			descs, err = client.Referrers(ctx, ref, a.Digest(), oci.ArtifactTypeSignature)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(descs) != 2 {
+				t.Fatalf("expected 2 referrers, got %d", len(descs))
+			}
+			got := map[string]bool{}
+			for _, d := range descs {
+				r, err := client.PullReferrer(ctx, ref, d)
+				if err != nil {
+					t.Fatalf("pull referrer failed: %v", err)
+				}
+				if r.Manifest.Subject == nil || r.Manifest.Subject.Digest != a.Digest() {
+					t.Errorf("referrer does not point at the skill manifest: %+v", r.Manifest.Subject)
+				}
+				got[string(r.Layer)] = true
+			}
+			if !got[`{"n":1}`] || !got[`{"n":2}`] {
+				t.Errorf("unexpected referrer payloads: %v", got)
+			}
+
+			other, err := client.Referrers(ctx, ref, a.Digest(), "application/vnd.example.other")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(other) != 0 {
+				t.Errorf("expected artifactType filter to exclude signatures, got %d", len(other))
+			}
+		})
+	}
+}
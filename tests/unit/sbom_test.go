@@ -0,0 +1,207 @@
+package unit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/oci"
+	"github.com/c8ab/provenskills/internal/sbom"
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+// scannedSkill writes a skill with the given script files and returns its
+// directory and manifest.
+func scannedSkill(t *testing.T, scripts map[string]string) (string, store.Manifest) {
+	t.Helper()
+	dir := t.TempDir()
+	writeFile := func(rel, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("SKILL.md", "---\nname: my-skill\n---\n")
+	for name, content := range scripts {
+		writeFile("scripts/"+name, content)
+	}
+	contents, err := store.ScanContents(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, store.Manifest{
+		Name:           "my-skill",
+		Version:        "1.0.0",
+		Maintainer:     "Test <test@example.com>",
+		BuildTimestamp: "2026-01-01T00:00:00Z",
+		Contents:       contents,
+	}
+}
+
+func TestSBOMScanDependencies(t *testing.T) {
+	dir, m := scannedSkill(t, map[string]string{
+		"requirements.txt": "# comment\nrequests==2.32.3\nPyYAML >= 6.0 ; python_version >= \"3.8\"\n" +
+			"-r other.txt\n--index-url https://pypi.example.com\nurllib3==2.2.1 \\\n    --hash=sha256:abc\n",
+		"js/package.json": `{"dependencies": {"@octokit/rest": "21.0.2", "marked": "^14.1.0"}, "devDependencies": {"eslint": "9.0.0"}}`,
+		"run.py":          "print('hi')\n",
+	})
+
+	inv, err := sbom.Scan(dir, m, oci.Digest([]byte("manifest")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(inv.Files) != 4 {
+		t.Errorf("expected SKILL.md and 3 scripts, got %d files", len(inv.Files))
+	}
+
+	want := []sbom.Package{
+		{Ecosystem: "npm", Name: "@octokit/rest", Version: "21.0.2", Requirement: "21.0.2", DeclaredIn: "scripts/js/package.json", PURL: "pkg:npm/%40octokit/rest@21.0.2"},
+		{Ecosystem: "npm", Name: "marked", Requirement: "^14.1.0", DeclaredIn: "scripts/js/package.json", PURL: "pkg:npm/marked"},
+		{Ecosystem: "pypi", Name: "pyyaml", Requirement: ">= 6.0", DeclaredIn: "scripts/requirements.txt", PURL: "pkg:pypi/pyyaml"},
+		{Ecosystem: "pypi", Name: "requests", Version: "2.32.3", Requirement: "==2.32.3", DeclaredIn: "scripts/requirements.txt", PURL: "pkg:pypi/requests@2.32.3"},
+		{Ecosystem: "pypi", Name: "urllib3", Version: "2.2.1", Requirement: "==2.2.1", DeclaredIn: "scripts/requirements.txt", PURL: "pkg:pypi/urllib3@2.2.1"},
+	}
+	if len(inv.Packages) != len(want) {
+		t.Fatalf("expected %d packages, got %+v", len(want), inv.Packages)
+	}
+	for i, p := range inv.Packages {
+		if p != want[i] {
+			t.Errorf("package %d:\n got %+v\nwant %+v", i, p, want[i])
+		}
+	}
+}
+
+func TestSBOMScanInvalidPackageJSON(t *testing.T) {
+	dir, m := scannedSkill(t, map[string]string{"package.json": "{"})
+	if _, err := sbom.Scan(dir, m, oci.Digest([]byte("manifest"))); err == nil {
+		t.Error("expected error for invalid package.json, got nil")
+	}
+}
+
+func TestSBOMGenerateSPDX(t *testing.T) {
+	dir, m := scannedSkill(t, map[string]string{"requirements.txt": "requests==2.32.3\n"})
+	inv, err := sbom.Scan(dir, m, oci.Digest([]byte("manifest")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := sbom.Generate(inv, sbom.FormatSPDX)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var doc struct {
+		SPDXVersion  string `json:"spdxVersion"`
+		CreationInfo struct {
+			Created string `json:"created"`
+		} `json:"creationInfo"`
+		Packages []struct {
+			Name         string `json:"name"`
+			VersionInfo  string `json:"versionInfo"`
+			Supplier     string `json:"supplier"`
+			ExternalRefs []struct {
+				Locator string `json:"referenceLocator"`
+			} `json:"externalRefs"`
+		} `json:"packages"`
+		Files []struct {
+			FileName  string `json:"fileName"`
+			Checksums []struct {
+				Algorithm string `json:"algorithm"`
+			} `json:"checksums"`
+		} `json:"files"`
+		Relationships []struct {
+			Type string `json:"relationshipType"`
+		} `json:"relationships"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if doc.SPDXVersion != "SPDX-2.3" || doc.CreationInfo.Created != "2026-01-01T00:00:00Z" {
+		t.Errorf("unexpected document header: %+v", doc)
+	}
+	if len(doc.Packages) != 2 || doc.Packages[0].Supplier != "Person: Test (test@example.com)" {
+		t.Fatalf("unexpected packages: %+v", doc.Packages)
+	}
+	if doc.Packages[1].Name != "requests" || doc.Packages[1].ExternalRefs[0].Locator != "pkg:pypi/requests@2.32.3" {
+		t.Errorf("unexpected dependency package: %+v", doc.Packages[1])
+	}
+	if len(doc.Files) != 2 || doc.Files[0].FileName != "./SKILL.md" || doc.Files[0].Checksums[0].Algorithm != "SHA1" {
+		t.Errorf("unexpected files: %+v", doc.Files)
+	}
+	counts := map[string]int{}
+	for _, r := range doc.Relationships {
+		counts[r.Type]++
+	}
+	if counts["DESCRIBES"] != 1 || counts["CONTAINS"] != 2 || counts["DEPENDS_ON"] != 1 {
+		t.Errorf("unexpected relationships: %v", counts)
+	}
+
+	again, _ := sbom.Generate(inv, sbom.FormatSPDX)
+	if string(again) != string(data) {
+		t.Error("expected SPDX generation to be deterministic")
+	}
+}
+
+func TestSBOMGenerateCycloneDX(t *testing.T) {
+	dir, m := scannedSkill(t, map[string]string{"package.json": `{"dependencies": {"marked": "14.1.0"}}`})
+	inv, err := sbom.Scan(dir, m, oci.Digest([]byte("manifest")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := sbom.Generate(inv, sbom.FormatCycloneDX)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var bom struct {
+		BOMFormat    string `json:"bomFormat"`
+		SpecVersion  string `json:"specVersion"`
+		SerialNumber string `json:"serialNumber"`
+		Metadata     struct {
+			Component struct {
+				Name   string `json:"name"`
+				BOMRef string `json:"bom-ref"`
+			} `json:"component"`
+		} `json:"metadata"`
+		Components []struct {
+			Type string `json:"type"`
+			Name string `json:"name"`
+			PURL string `json:"purl"`
+		} `json:"components"`
+		Dependencies []struct {
+			Ref       string   `json:"ref"`
+			DependsOn []string `json:"dependsOn"`
+		} `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &bom); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if bom.BOMFormat != "CycloneDX" || bom.SpecVersion != "1.5" || len(bom.SerialNumber) != len("urn:uuid:")+36 {
+		t.Errorf("unexpected BOM header: %+v", bom)
+	}
+	if bom.Metadata.Component.Name != "my-skill" {
+		t.Errorf("unexpected metadata component: %+v", bom.Metadata.Component)
+	}
+	types := map[string]int{}
+	for _, c := range bom.Components {
+		types[c.Type]++
+		if c.Type == "library" && c.PURL != "pkg:npm/marked@14.1.0" {
+			t.Errorf("unexpected library purl %q", c.PURL)
+		}
+	}
+	if types["file"] != 2 || types["library"] != 1 {
+		t.Errorf("unexpected components: %v", types)
+	}
+	if len(bom.Dependencies) != 1 || bom.Dependencies[0].Ref != bom.Metadata.Component.BOMRef || len(bom.Dependencies[0].DependsOn) != 1 {
+		t.Errorf("unexpected dependencies: %+v", bom.Dependencies)
+	}
+}
+
+func TestSBOMUnknownFormat(t *testing.T) {
+	if _, err := sbom.Generate(sbom.Inventory{}, "swid"); err == nil {
+		t.Error("expected error for unknown format, got nil")
+	}
+}
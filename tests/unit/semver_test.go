@@ -0,0 +1,117 @@
+package unit
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/skill"
+)
+
+func TestParseVersion(t *testing.T) {
+	v, err := skill.ParseVersion("1.2.0-rc.1+build.5")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.Major != 1 || v.Minor != 2 || v.Patch != 0 ||
+		!reflect.DeepEqual(v.Prerelease, []string{"rc", "1"}) || !reflect.DeepEqual(v.Build, []string{"build", "5"}) {
+		t.Errorf("unexpected version: %+v", v)
+	}
+	if v.String() != "1.2.0-rc.1+build.5" {
+		t.Errorf("expected round trip, got %q", v.String())
+	}
+
+	for _, s := range []string{"1.2", "01.2.3", "1.2.3-", "1.2.3-01", "1.2.3+", "v1.2.3", "1.2.3.4", "1.2.3-rc..1"} {
+		if _, err := skill.ParseVersion(s); err == nil {
+			t.Errorf("%q: expected error, got nil", s)
+		}
+	}
+}
+
+func TestSortVersions(t *testing.T) {
+	// Precedence order from the SemVer 2.0 specification
+	want := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+		"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.9.0", "1.10.0", "2.0.0",
+	}
+	got := []string{"2.0.0", "1.10.0", "1.0.0-beta.11", "1.0.0", "1.9.0", "1.0.0-rc.1",
+		"1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-alpha", "1.0.0-beta.2", "1.0.0-alpha.1"}
+	skill.SortVersions(got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if c := skill.CompareVersions("1.0.0+a", "1.0.0+b"); c >= 0 {
+		t.Errorf("expected build metadata to only break ties in string order, got %d", c)
+	}
+	if c := skill.CompareVersions("not-semver", "0.0.1"); c <= 0 {
+		t.Errorf("expected invalid versions to sort last, got %d", c)
+	}
+}
+
+func TestConstraintMatch(t *testing.T) {
+	tests := []struct {
+		constraint string
+		match      []string
+		noMatch    []string
+	}{
+		{"^1.2", []string{"1.2.0", "1.9.3", "1.10.0"}, []string{"1.1.9", "2.0.0", "2.0.0-rc.1", "1.3.0-beta"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0", "0.2.2"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"~1.2.3", []string{"1.2.3", "1.2.10"}, []string{"1.3.0", "1.2.2"}},
+		{"~1", []string{"1.0.0", "1.9.0"}, []string{"2.0.0"}},
+		{">=1.0 <2", []string{"1.0.0", "1.99.0"}, []string{"0.9.0", "2.0.0", "2.0.0-rc.1"}},
+		{">= 1.0, < 2", []string{"1.5.0"}, []string{"2.0.0"}},
+		{">1.2", []string{"1.3.0"}, []string{"1.2.9"}},
+		{"<=1.2", []string{"1.2.9"}, []string{"1.3.0"}},
+		{"1.2.x", []string{"1.2.0", "1.2.7"}, []string{"1.3.0"}},
+		{"1.2.3", []string{"1.2.3", "1.2.3+build"}, []string{"1.2.4"}},
+		{"*", []string{"0.0.1", "3.0.0"}, []string{"3.0.0-rc.1"}},
+		{">=1.2.0-rc.1", []string{"1.2.0-rc.2", "1.2.0", "1.5.0"}, []string{"1.2.0-rc.0", "1.3.0-rc.1"}},
+		{"^1 || ^3", []string{"1.4.0", "3.0.0"}, []string{"2.0.0"}},
+	}
+	for _, tt := range tests {
+		c, err := skill.ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.constraint, err)
+			continue
+		}
+		for _, s := range tt.match {
+			if !c.Match(mustParseVersion(t, s)) {
+				t.Errorf("%q: expected %s to match", tt.constraint, s)
+			}
+		}
+		for _, s := range tt.noMatch {
+			if c.Match(mustParseVersion(t, s)) {
+				t.Errorf("%q: expected %s not to match", tt.constraint, s)
+			}
+		}
+	}
+
+	for _, s := range []string{"^", ">=1.a", "1.x.2", "=>1.0", "~01.2", "", " ", "^1.0 ||", "|| 2.x", "^1 || || ^3"} {
+		if _, err := skill.ParseConstraint(s); err == nil {
+			t.Errorf("%q: expected error, got nil", s)
+		}
+	}
+}
+
+func mustParseVersion(t *testing.T, s string) skill.Version {
+	t.Helper()
+	v, err := skill.ParseVersion(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestValidatePrereleaseVersion(t *testing.T) {
+	for _, version := range []string{"1.2.0-rc.1", "1.2.0+build.5", "1.2.0-rc.1+build.5", "1.2"} {
+		fm := skill.SkillFrontmatter{
+			Name:        "my-skill",
+			Description: "A valid skill.",
+			Metadata:    skill.Metadata{Version: version, Author: "test-author"},
+		}
+		if errs := skill.Validate(fm, "my-skill"); len(errs) != 0 {
+			t.Errorf("%q: expected no errors, got %v", version, errs)
+		}
+	}
+}
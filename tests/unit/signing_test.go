@@ -0,0 +1,193 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/oci"
+	"github.com/c8ab/provenskills/internal/signing"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	digest := oci.Digest([]byte("manifest"))
+	for _, algorithm := range []string{signing.AlgorithmECDSAP256, signing.AlgorithmEd25519} {
+		t.Run(algorithm, func(t *testing.T) {
+			key, err := signing.GenerateKey(algorithm)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			sig, err := signing.Sign(key, digest)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sig.Algorithm != algorithm {
+				t.Errorf("expected algorithm %q, got %q", algorithm, sig.Algorithm)
+			}
+			if !strings.HasPrefix(sig.KeyID, "sha256:") {
+				t.Errorf("expected sha256 key id, got %q", sig.KeyID)
+			}
+			if err := sig.Verify(digest); err != nil {
+				t.Errorf("expected signature to verify, got %v", err)
+			}
+			if err := sig.Verify(oci.Digest([]byte("other"))); err == nil {
+				t.Error("expected error verifying against a different digest, got nil")
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsTamperedSignature(t *testing.T) {
+	key, err := signing.GenerateKey(signing.AlgorithmEd25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := oci.Digest([]byte("manifest"))
+	sig, err := signing.Sign(key, digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Re-pointing the signature at another digest must not verify.
+	forged := sig
+	forged.Digest = oci.Digest([]byte("other"))
+	if err := forged.Verify(forged.Digest); err == nil {
+		t.Error("expected error for forged digest, got nil")
+	}
+
+	// Swapping in another public key must not verify either.
+	other, err := signing.GenerateKey(signing.AlgorithmEd25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSig, err := signing.Sign(other, digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged = sig
+	forged.PublicKey, forged.KeyID = otherSig.PublicKey, otherSig.KeyID
+	if err := forged.Verify(digest); err == nil {
+		t.Error("expected error for swapped public key, got nil")
+	}
+}
+
+func TestKeyRoundTrip(t *testing.T) {
+	key, err := signing.GenerateKey(signing.AlgorithmECDSAP256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privPEM, err := signing.MarshalPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubPEM, err := signing.MarshalPublicKey(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := signing.ParsePrivateKey(privPEM)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pub, err := signing.ParsePublicKey(pubPEM)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantID, _ := signing.KeyID(key.Public())
+	gotPriv, _ := signing.KeyID(parsed.Public())
+	gotPub, _ := signing.KeyID(pub)
+	if gotPriv != wantID || gotPub != wantID {
+		t.Errorf("key ids differ after round trip: %s, %s, %s", wantID, gotPriv, gotPub)
+	}
+
+	if _, err := signing.ParsePrivateKey(pubPEM); err == nil {
+		t.Error("expected error parsing a public key as private, got nil")
+	}
+}
+
+func TestSaveAndLoadSignatures(t *testing.T) {
+	dir := t.TempDir()
+	digest := oci.Digest([]byte("manifest"))
+
+	sigs, err := signing.Load(dir)
+	if err != nil || len(sigs) != 0 {
+		t.Fatalf("expected no signatures, got %v, %v", sigs, err)
+	}
+
+	for _, algorithm := range []string{signing.AlgorithmECDSAP256, signing.AlgorithmEd25519} {
+		key, err := signing.GenerateKey(algorithm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := signing.Sign(key, digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path, err := signing.Save(dir, sig)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if filepath.Dir(path) != filepath.Join(dir, signing.SignaturesDir) {
+			t.Errorf("unexpected signature path %s", path)
+		}
+		// Re-signing with the same key replaces the earlier signature.
+		if _, err := signing.Save(dir, sig); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sigs, err = signing.Load(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sigs) != 2 {
+		t.Fatalf("expected 2 signatures, got %d", len(sigs))
+	}
+	for _, sig := range sigs {
+		if err := sig.Verify(digest); err != nil {
+			t.Errorf("loaded signature does not verify: %v", err)
+		}
+	}
+}
+
+func TestLoadRejectsMalformedSignature(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, signing.SignaturesDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, signing.SignaturesDir, "bad.json"), []byte(`{"digest":"x"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signing.Load(dir); err == nil {
+		t.Error("expected error for malformed signature, got nil")
+	}
+}
+
+func TestPackIgnoresSignatures(t *testing.T) {
+	dir := storedSkill(t)
+	before, err := oci.Pack(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := signing.GenerateKey(signing.AlgorithmEd25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := signing.Sign(key, before.Digest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signing.Save(dir, sig); err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := oci.Pack(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Digest() != before.Digest() {
+		t.Errorf("signing changed the artifact digest: %s -> %s", before.Digest(), after.Digest())
+	}
+}
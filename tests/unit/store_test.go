@@ -0,0 +1,237 @@
+package unit
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/skill"
+	"github.com/c8ab/provenskills/internal/store"
+)
+
+// addSkill stores an artifact with only a SKILL.md at name@version.
+func addSkill(t *testing.T, s *store.Store, name, version string) {
+	t.Helper()
+	m := store.Manifest{Name: name, Version: version}
+	_, err := s.AddFunc(name, version, m, false, func(dir string) error {
+		return os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("---\nname: "+name+"\n---\n"), 0o644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStoreRemove(t *testing.T) {
+	root := t.TempDir()
+	s := store.New(root)
+	addSkill(t, s, "my-skill", "1.0.0")
+	addSkill(t, s, "my-skill", "1.1.0")
+
+	if err := s.Remove("my-skill", "1.0.0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Exists("my-skill", "1.0.0") {
+		t.Error("expected 1.0.0 to be removed")
+	}
+	versions, err := s.Versions("my-skill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(versions, []string{"1.1.0"}) {
+		t.Errorf("expected only 1.1.0 to remain, got %v", versions)
+	}
+
+	if err := s.Remove("my-skill", "1.0.0"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected not-exist error for a removed version, got %v", err)
+	}
+
+	if err := s.Remove("my-skill", "1.1.0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "my-skill")); !os.IsNotExist(err) {
+		t.Errorf("expected empty name directory to be removed, got %v", err)
+	}
+}
+
+func TestStoreRemoveKeepsConcurrentWrite(t *testing.T) {
+	root := t.TempDir()
+	s := store.New(root)
+	addSkill(t, s, "my-skill", "1.0.0")
+
+	// A build of another version that has not been moved into place yet
+	pending := filepath.Join(root, "my-skill", "2.0.0.tmp.1")
+	if err := os.MkdirAll(pending, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Remove("my-skill", "1.0.0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(pending); err != nil {
+		t.Errorf("expected in-progress write to survive, got %v", err)
+	}
+}
+
+func TestStoreVersionsSkipsTempDirs(t *testing.T) {
+	root := t.TempDir()
+	s := store.New(root)
+	addSkill(t, s, "my-skill", "1.0.0")
+
+	tmp := filepath.Join(root, "my-skill", "1.1.0.tmp.1")
+	if err := os.MkdirAll(tmp, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.WriteManifest(filepath.Join(tmp, "manifest.json"), store.Manifest{Name: "my-skill", Version: "1.1.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	versions, err := s.Versions("my-skill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(versions, []string{"1.0.0"}) {
+		t.Errorf("expected [1.0.0], got %v", versions)
+	}
+	if versions, _ := s.Versions("missing"); len(versions) != 0 {
+		t.Errorf("expected no versions for a missing skill, got %v", versions)
+	}
+}
+
+func TestStorePrereleaseNamedLikeTempDir(t *testing.T) {
+	root := t.TempDir()
+	s := store.New(root)
+	version := "1.0.0-rc.tmp.999999999"
+
+	var tmpDir string
+	_, err := s.AddFunc("my-skill", version, store.Manifest{Name: "my-skill", Version: version}, false, func(dir string) error {
+		tmpDir = dir
+		return os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("---\nname: my-skill\n---\n"), 0o644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(filepath.Base(tmpDir), ".") {
+		t.Errorf("expected a dot-prefixed temp directory, got %s", tmpDir)
+	}
+
+	versions, err := s.Versions("my-skill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(versions, []string{version}) {
+		t.Errorf("expected [%s], got %v", version, versions)
+	}
+	entries, err := s.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Version != version {
+		t.Errorf("expected fsck to see %s, got %+v", version, entries)
+	}
+	garbage, err := s.FindGarbage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(garbage) != 0 {
+		t.Errorf("expected gc to keep %s, got %+v", version, garbage)
+	}
+}
+
+func TestStoreFindGarbage(t *testing.T) {
+	root := t.TempDir()
+	s := store.New(root)
+	addSkill(t, s, "my-skill", "1.0.0")
+
+	mkdir := func(rel string) string {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(path, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	orphan := mkdir("my-skill/1.1.0.tmp.999999999")
+	if err := os.WriteFile(filepath.Join(orphan, "SKILL.md"), []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dotted := mkdir("my-skill/.1.3.0.tmp.999999999")
+	removal := mkdir("my-skill/.0.9.0.rm.999999999")
+	mkdir(fmt.Sprintf("my-skill/1.2.0.tmp.%d", os.Getpid()))
+	partial := mkdir("other-skill/2.0.0")
+
+	garbage, err := s.FindGarbage()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := map[string]store.Garbage{}
+	for _, g := range garbage {
+		got[g.Path] = g
+	}
+	if len(got) != 5 {
+		t.Fatalf("expected 5 garbage entries, got %+v", garbage)
+	}
+	if g := got[orphan]; !strings.Contains(g.Reason, "orphaned temp directory of my-skill@1.1.0") || g.Size != 10 {
+		t.Errorf("unexpected orphan entry: %+v", g)
+	}
+	if g := got[dotted]; !strings.Contains(g.Reason, "orphaned temp directory of my-skill@1.3.0") {
+		t.Errorf("unexpected temp entry: %+v", g)
+	}
+	if g := got[removal]; !strings.Contains(g.Reason, "interrupted removal of my-skill@0.9.0") {
+		t.Errorf("unexpected removal entry: %+v", g)
+	}
+	if g := got[partial]; !strings.Contains(g.Reason, "partial artifact") {
+		t.Errorf("unexpected partial entry: %+v", g)
+	}
+	if _, ok := got[filepath.Join(root, "other-skill")]; !ok {
+		t.Errorf("expected other-skill directory to be collected, got %+v", garbage)
+	}
+
+	if err := s.GC(garbage); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !s.Exists("my-skill", "1.0.0") || !s.Exists("my-skill", fmt.Sprintf("1.2.0.tmp.%d", os.Getpid())) {
+		t.Error("expected the artifact and the live temp directory to survive")
+	}
+	if _, err := os.Stat(filepath.Join(root, "other-skill")); !os.IsNotExist(err) {
+		t.Errorf("expected other-skill to be removed, got %v", err)
+	}
+	if garbage, _ := s.FindGarbage(); len(garbage) != 0 {
+		t.Errorf("expected no garbage after GC, got %+v", garbage)
+	}
+}
+
+func TestStoreSemverOrder(t *testing.T) {
+	s := store.New(t.TempDir())
+	for _, v := range []string{"1.9.0", "1.10.0", "2.0.0-rc.1", "1.10.0-beta"} {
+		addSkill(t, s, "my-skill", v)
+	}
+
+	manifests, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var listed []string
+	for _, m := range manifests {
+		listed = append(listed, m.Version)
+	}
+	if want := []string{"1.9.0", "1.10.0-beta", "1.10.0", "2.0.0-rc.1"}; !reflect.DeepEqual(listed, want) {
+		t.Errorf("expected %v, got %v", want, listed)
+	}
+
+	for constraint, want := range map[string]string{"*": "1.10.0", "^1.2": "1.10.0", "~1.9": "1.9.0", ">=2.0.0-rc.0": "2.0.0-rc.1", "^3": ""} {
+		c, err := skill.ParseConstraint(constraint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := s.Resolve("my-skill", c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("%q: expected %q, got %q", constraint, want, got)
+		}
+	}
+}
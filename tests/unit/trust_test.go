@@ -0,0 +1,133 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/c8ab/provenskills/internal/oci"
+	"github.com/c8ab/provenskills/internal/signing"
+	"github.com/c8ab/provenskills/internal/trust"
+)
+
+// newSigner generates an Ed25519 key and returns its PEM public key and a
+// function signing digests with it.
+func newSigner(t *testing.T) (pub []byte, sign func(digest string) signing.Signature) {
+	t.Helper()
+	k, err := signing.GenerateKey(signing.AlgorithmEd25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err = signing.MarshalPublicKey(k.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pub, func(digest string) signing.Signature {
+		sig, err := signing.Sign(k, digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+}
+
+func TestTrustStoreAddListRemove(t *testing.T) {
+	ts := trust.New(t.TempDir())
+	pub, _ := newSigner(t)
+
+	k, err := ts.Add(pub, "Test <test@example.com>", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if k.Algorithm != signing.AlgorithmEd25519 {
+		t.Errorf("expected algorithm %q, got %q", signing.AlgorithmEd25519, k.Algorithm)
+	}
+
+	if _, err := ts.Add(pub, "Other <other@example.com>", false); !errors.Is(err, trust.ErrKeyExists) {
+		t.Errorf("expected ErrKeyExists, got %v", err)
+	}
+	if _, err := ts.Add(pub, "Other <other@example.com>", true); err != nil {
+		t.Errorf("expected --force to rebind the identity, got %v", err)
+	}
+
+	keys, err := ts.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 1 || keys[0].Identity != "Other <other@example.com>" {
+		t.Fatalf("unexpected keys: %+v", keys)
+	}
+
+	got, ok, err := ts.Lookup(k.KeyID)
+	if err != nil || !ok || got.KeyID != k.KeyID {
+		t.Errorf("expected lookup to find %s, got %+v, %v, %v", k.KeyID, got, ok, err)
+	}
+
+	// Remove by a short key id prefix
+	if _, err := ts.Remove(k.KeyID[len("sha256:") : len("sha256:")+12]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok, _ := ts.Lookup(k.KeyID); ok {
+		t.Error("expected key to be removed")
+	}
+	if _, err := ts.Remove(k.KeyID); !errors.Is(err, trust.ErrKeyNotFound) {
+		t.Errorf("expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func TestTrustStoreRejectsInvalidKey(t *testing.T) {
+	ts := trust.New(t.TempDir())
+	if _, err := ts.Add([]byte("not a key"), "Test <test@example.com>", false); err == nil {
+		t.Error("expected error for invalid key, got nil")
+	}
+	pub, _ := newSigner(t)
+	if _, err := ts.Add(pub, "  ", false); err == nil {
+		t.Error("expected error for empty identity, got nil")
+	}
+}
+
+func TestTrustStoreVerify(t *testing.T) {
+	ts := trust.New(t.TempDir())
+	digest := oci.Digest([]byte("manifest"))
+
+	trustedPub, signTrusted := newSigner(t)
+	_, signUnknown := newSigner(t)
+	if _, err := ts.Add(trustedPub, "Test <test@example.com>", false); err != nil {
+		t.Fatal(err)
+	}
+
+	forged := signUnknown(oci.Digest([]byte("other")))
+	forged.Digest = digest
+
+	results, err := ts.Verify(digest, []signing.Signature{signTrusted(digest), signUnknown(digest), forged})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{trust.StatusTrusted, trust.StatusUntrusted, trust.StatusInvalid}
+	for i, r := range results {
+		if r.Status != want[i] {
+			t.Errorf("signature %d: expected status %q, got %q (%v)", i, want[i], r.Status, r.Err)
+		}
+	}
+	if results[0].Key == nil || results[0].Key.Identity != "Test <test@example.com>" {
+		t.Errorf("expected trusted result to carry its key, got %+v", results[0].Key)
+	}
+}
+
+func TestMatchesMaintainer(t *testing.T) {
+	cases := []struct {
+		identity, maintainer string
+		want                 bool
+	}{
+		{"Test <test@example.com>", "Test <test@example.com>", true},
+		{"T. Ester <TEST@example.com>", "Test <test@example.com>", true},
+		{"test@example.com", "Test <test@example.com>", true},
+		{"Test <test@example.com>", "Other <other@example.com>", false},
+		{"Release Bot", "Release Bot", true},
+		{"Release Bot", "Test <test@example.com>", false},
+	}
+	for _, c := range cases {
+		if got := trust.MatchesMaintainer(c.identity, c.maintainer); got != c.want {
+			t.Errorf("MatchesMaintainer(%q, %q) = %v, want %v", c.identity, c.maintainer, got, c.want)
+		}
+	}
+}